@@ -32,13 +32,65 @@ var (
 		Name:  "height",
 		Usage: "block height",
 	}
+	FromHeightFlag = &cli.Int64Flag{
+		Name:  "from",
+		Usage: "the first block height",
+	}
+	ToHeightFlag = &cli.Int64Flag{
+		Name:  "to",
+		Usage: "the last block height",
+	}
 	ServiceNameFlag = &cli.StringFlag{
 		Name:  "service",
 		Usage: "service name(committer, witness)",
 	}
+	AccountNameFlag = &cli.StringFlag{
+		Name:  "account",
+		Usage: "account name",
+	}
+	AssetIdFlag = &cli.Int64Flag{
+		Name:  "asset",
+		Usage: "asset id",
+	}
+	NftIndexFlag = &cli.Int64Flag{
+		Name:  "nft",
+		Usage: "nft index",
+	}
+	OutputFlag = &cli.StringFlag{
+		Name:    "output",
+		Aliases: []string{"o"},
+		Usage:   "the output file, stdout by default",
+	}
+	InputFlag = &cli.StringFlag{
+		Name:    "input",
+		Aliases: []string{"i"},
+		Usage:   "the input file, stdin by default",
+	}
 	BatchSizeFlag = &cli.IntFlag{
 		Name:  "batch",
 		Value: 1000,
 		Usage: "batch size for reading history record from the database",
 	}
+	SamplesFlag = &cli.IntFlag{
+		Name:  "samples",
+		Value: 1000,
+		Usage: "the number of the accounts, liquidity and nfts sampled",
+	}
+	BlockSizeFlag = &cli.IntSliceFlag{
+		Name:  "block-size",
+		Usage: "the block sizes, e.g. --block-size 1 --block-size 10",
+	}
+	KeyDirFlag = &cli.StringFlag{
+		Name:  "dir",
+		Value: ".",
+		Usage: "the directory of the proving and verifying keys",
+	}
+	R1csCacheDirFlag = &cli.StringFlag{
+		Name:  "r1cs-cache",
+		Usage: "the directory caching the compiled circuits, nothing is cached by default",
+	}
+	WorkersFlag = &cli.IntFlag{
+		Name:  "workers",
+		Usage: "the number of workers reloading the asset trees in parallel, the number of cpus by default",
+	}
 )
@@ -10,12 +10,22 @@ import (
 	"github.com/bnb-chain/zkbas/cmd/flags"
 	"github.com/bnb-chain/zkbas/service/apiserver"
 	"github.com/bnb-chain/zkbas/service/committer"
+	"github.com/bnb-chain/zkbas/service/coordinator"
 	"github.com/bnb-chain/zkbas/service/monitor"
 	"github.com/bnb-chain/zkbas/service/prover"
 	"github.com/bnb-chain/zkbas/service/sender"
 	"github.com/bnb-chain/zkbas/service/witness"
+	"github.com/bnb-chain/zkbas/tools/cache"
 	"github.com/bnb-chain/zkbas/tools/dbinitializer"
+	"github.com/bnb-chain/zkbas/tools/exit"
+	"github.com/bnb-chain/zkbas/tools/keys"
+	"github.com/bnb-chain/zkbas/tools/migrate"
+	"github.com/bnb-chain/zkbas/tools/proof"
+	"github.com/bnb-chain/zkbas/tools/prune"
 	"github.com/bnb-chain/zkbas/tools/recovery"
+	"github.com/bnb-chain/zkbas/tools/replay"
+	"github.com/bnb-chain/zkbas/tools/snapshot"
+	"github.com/bnb-chain/zkbas/tools/treeverify"
 )
 
 // Build Info (set via linker flags)
@@ -56,6 +66,20 @@ func main() {
 					return prover.Run(cCtx.String(flags.ConfigFlag.Name))
 				},
 			},
+			{
+				Name:  "coordinator",
+				Usage: "Run coordinator service for the remote provers",
+				Flags: []cli.Flag{
+					flags.ConfigFlag,
+				},
+				Action: func(cCtx *cli.Context) error {
+					if !cCtx.IsSet(flags.ConfigFlag.Name) {
+						return cli.ShowSubcommandHelp(cCtx)
+					}
+
+					return coordinator.Run(cCtx.String(flags.ConfigFlag.Name))
+				},
+			},
 			{
 				Name:  "witness",
 				Usage: "Run witness service",
@@ -156,6 +180,78 @@ func main() {
 					},
 				},
 			},
+			{
+				Name:  "chain",
+				Usage: "Chain tools",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "replay",
+						Usage: "Replay blocks and verify the state roots",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.FromHeightFlag,
+							flags.ToHeightFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ConfigFlag.Name) ||
+								!cCtx.IsSet(flags.FromHeightFlag.Name) ||
+								!cCtx.IsSet(flags.ToHeightFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							return replay.Replay(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.Int64(flags.FromHeightFlag.Name),
+								cCtx.Int64(flags.ToHeightFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+							)
+						},
+					},
+				},
+			},
+			{
+				Name:  "exit",
+				Usage: "Desert mode tools",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "prove",
+						Usage: "Generate the exit proof of an asset or a nft at the last verified block",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.AccountNameFlag,
+							flags.AssetIdFlag,
+							flags.NftIndexFlag,
+							flags.OutputFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ConfigFlag.Name) ||
+								!cCtx.IsSet(flags.AccountNameFlag.Name) ||
+								cCtx.IsSet(flags.AssetIdFlag.Name) == cCtx.IsSet(flags.NftIndexFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+
+							if cCtx.IsSet(flags.NftIndexFlag.Name) {
+								return exit.ProveNft(
+									cCtx.String(flags.ConfigFlag.Name),
+									cCtx.String(flags.AccountNameFlag.Name),
+									cCtx.Int64(flags.NftIndexFlag.Name),
+									cCtx.Int(flags.BatchSizeFlag.Name),
+									cCtx.String(flags.OutputFlag.Name),
+								)
+							}
+							return exit.ProveAsset(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.String(flags.AccountNameFlag.Name),
+								cCtx.Int64(flags.AssetIdFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+								cCtx.String(flags.OutputFlag.Name),
+							)
+						},
+					},
+				},
+			},
 			{
 				Name:  "tree",
 				Usage: "TreeDB tools",
@@ -168,6 +264,7 @@ func main() {
 							flags.BlockHeightFlag,
 							flags.ServiceNameFlag,
 							flags.BatchSizeFlag,
+							flags.WorkersFlag,
 						},
 						Action: func(cCtx *cli.Context) error {
 							if !cCtx.IsSet(flags.ServiceNameFlag.Name) ||
@@ -180,10 +277,241 @@ func main() {
 								cCtx.Int64(flags.BlockHeightFlag.Name),
 								cCtx.String(flags.ServiceNameFlag.Name),
 								cCtx.Int(flags.BatchSizeFlag.Name),
+								cCtx.Int(flags.WorkersFlag.Name),
 							)
 							return nil
 						},
 					},
+					{
+						Name:  "prune",
+						Usage: "Prune the versions of the trees in treedb by the pruning policy",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.ServiceNameFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ServiceNameFlag.Name) ||
+								!cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return prune.PruneTreeDB(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.String(flags.ServiceNameFlag.Name),
+							)
+						},
+					},
+					{
+						Name:  "migrate",
+						Usage: "Migrate the leveldb treedb to pebble",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return migrate.MigrateTreeDB(
+								cCtx.String(flags.ConfigFlag.Name),
+							)
+						},
+					},
+					{
+						Name:  "verify",
+						Usage: "Verify the trees in treedb against the accounts, liquidity and nfts in the database",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.ServiceNameFlag,
+							flags.OutputFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ServiceNameFlag.Name) ||
+								!cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return treeverify.VerifyTreeDB(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.String(flags.ServiceNameFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+								cCtx.String(flags.OutputFlag.Name),
+							)
+						},
+					},
+				},
+			},
+			{
+				Name:  "state",
+				Usage: "State snapshot tools",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "export",
+						Usage: "Export the snapshot of the state at the block height, the latest verified block by default",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.BlockHeightFlag,
+							flags.OutputFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return snapshot.Export(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.Int64(flags.BlockHeightFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+								cCtx.String(flags.OutputFlag.Name),
+							)
+						},
+					},
+					{
+						Name:  "import",
+						Usage: "Import the snapshot of the state into the treedb of the service",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.ServiceNameFlag,
+							flags.InputFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ConfigFlag.Name) ||
+								!cCtx.IsSet(flags.ServiceNameFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return snapshot.Import(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.String(flags.ServiceNameFlag.Name),
+								cCtx.String(flags.InputFlag.Name),
+							)
+						},
+					},
+				},
+			},
+			{
+				Name:  "cache",
+				Usage: "Redis cache tools",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "check",
+						Usage: "Check the states cached in redis against the database by sampling",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.SamplesFlag,
+							flags.OutputFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return cache.Check(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.Int(flags.SamplesFlag.Name),
+								cCtx.String(flags.OutputFlag.Name),
+							)
+						},
+					},
+					{
+						Name:  "rebuild",
+						Usage: "Rebuild the states cached in redis from the database, the committer should be stopped",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.BatchSizeFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ConfigFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return cache.Rebuild(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.Int(flags.BatchSizeFlag.Name),
+							)
+						},
+					},
+				},
+			},
+			{
+				Name:  "proof",
+				Usage: "Proof tools",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "verify",
+						Usage: "Verify the proof of a block stored in the database",
+						Flags: []cli.Flag{
+							flags.ConfigFlag,
+							flags.BlockHeightFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.ConfigFlag.Name) || !cCtx.IsSet(flags.BlockHeightFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return proof.Verify(
+								cCtx.String(flags.ConfigFlag.Name),
+								cCtx.Int64(flags.BlockHeightFlag.Name),
+							)
+						},
+					},
+				},
+			},
+			{
+				Name:  "keys",
+				Usage: "Proving and verifying key tools",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "setup",
+						Usage: "Generate the keys of the block sizes, it is unsafe and only for development",
+						Flags: []cli.Flag{
+							flags.BlockSizeFlag,
+							flags.KeyDirFlag,
+							flags.R1csCacheDirFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.BlockSizeFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return keys.Setup(
+								cCtx.IntSlice(flags.BlockSizeFlag.Name),
+								cCtx.String(flags.KeyDirFlag.Name),
+								cCtx.String(flags.R1csCacheDirFlag.Name),
+							)
+						},
+					},
+					{
+						Name:  "check",
+						Usage: "Check the keys of the block sizes against the block circuits",
+						Flags: []cli.Flag{
+							flags.BlockSizeFlag,
+							flags.KeyDirFlag,
+							flags.R1csCacheDirFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.BlockSizeFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return keys.Check(
+								cCtx.IntSlice(flags.BlockSizeFlag.Name),
+								cCtx.String(flags.KeyDirFlag.Name),
+								cCtx.String(flags.R1csCacheDirFlag.Name),
+							)
+						},
+					},
+					{
+						Name:  "export-verifier",
+						Usage: "Export the verifying keys of the block sizes as the constants of the verifier contract",
+						Flags: []cli.Flag{
+							flags.BlockSizeFlag,
+							flags.KeyDirFlag,
+							flags.OutputFlag,
+						},
+						Action: func(cCtx *cli.Context) error {
+							if !cCtx.IsSet(flags.BlockSizeFlag.Name) {
+								return cli.ShowSubcommandHelp(cCtx)
+							}
+							return keys.ExportVerifier(
+								cCtx.IntSlice(flags.BlockSizeFlag.Name),
+								cCtx.String(flags.KeyDirFlag.Name),
+								cCtx.String(flags.OutputFlag.Name),
+							)
+						},
+					},
 				},
 			},
 		},
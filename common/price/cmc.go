@@ -0,0 +1,69 @@
+package price
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// GetCurrencyPrice returns the price in usd of the symbol quoted by coinmarketcap, it is 0 if the symbol is not
+// listed.
+func GetCurrencyPrice(cmcUrl, cmcToken, symbol string) (float64, error) {
+	quoteMap, err := GetLatestQuotes(cmcUrl, cmcToken, symbol)
+	if err != nil {
+		if err == types.CmcNotListedErr {
+			return 0.0, nil
+		}
+		return 0.0, err
+	}
+	q, ok := quoteMap[symbol]
+	if !ok {
+		return 0.0, nil
+	}
+	return q.Quote["USD"].Price, nil
+}
+
+func GetLatestQuotes(cmcUrl, cmcToken, symbol string) (map[string]QuoteLatest, error) {
+	client := &http.Client{}
+	url := fmt.Sprintf("%s%s", cmcUrl, symbol)
+	reqest, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, types.HttpErrFailToRequest
+	}
+	reqest.Header.Add("X-CMC_PRO_API_KEY", cmcToken)
+	reqest.Header.Add("Accept", "application/json")
+	resp, err := client.Do(reqest)
+	if err != nil {
+		return nil, types.HttpErrClientDo
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, types.IoErrFailToRead
+	}
+	currencyPrice := &currencyPrice{}
+	if err = json.Unmarshal(body, &currencyPrice); err != nil {
+		return nil, types.JsonErrUnmarshal
+	}
+	dataMap, ok := currencyPrice.Data.(map[string]interface{})
+	if !ok { //the currency not listed on cmc
+		return nil, types.CmcNotListedErr
+	}
+	quotesLatest := make(map[string]QuoteLatest, 0)
+	for _, coinObj := range dataMap {
+		b, err := json.Marshal(coinObj)
+		if err != nil {
+			return nil, types.JsonErrMarshal
+		}
+		quoteLatest := &QuoteLatest{}
+		err = json.Unmarshal(b, quoteLatest)
+		if err != nil {
+			return nil, types.JsonErrUnmarshal
+		}
+		quotesLatest[quoteLatest.Symbol] = *quoteLatest
+	}
+	return quotesLatest, nil
+}
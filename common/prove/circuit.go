@@ -0,0 +1,137 @@
+package prove
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	cryptoBlock "github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+)
+
+// the modules defining the block circuit
+var circuitModules = []string{"github.com/bnb-chain/zkbas-crypto", "github.com/consensys/gnark"}
+
+// CircuitHash identifies the block circuit of the block size by the versions and the checksums of the modules
+// defining it. The circuit can't be identified, and false is returned, if there is no build info or a module has no
+// checksum, e.g. it is replaced by a local directory or it is the main module built from the source, "(devel)".
+func CircuitHash(blockSize int) (string, bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", false
+	}
+	return circuitHash(blockSize, info.Deps)
+}
+
+func circuitHash(blockSize int, deps []*debug.Module) (string, bool) {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "block size %d\n", blockSize)
+	for _, path := range circuitModules {
+		found := false
+		for _, dep := range deps {
+			if dep.Path != path {
+				continue
+			}
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			if dep.Sum == "" || dep.Version == "" || dep.Version == "(devel)" {
+				return "", false
+			}
+			_, _ = fmt.Fprintf(h, "%s %s %s\n", dep.Path, dep.Version, dep.Sum)
+			found = true
+		}
+		if !found {
+			return "", false
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// CompileBlockCircuit compiles the block circuit of the block size. If the cache dir is not empty, the compiled
+// constraint system is cached in it by the hash of the circuit, and read from it next time. The cache is skipped if
+// the circuit can't be identified, see CircuitHash.
+func CompileBlockCircuit(blockSize int, cacheDir string) (frontend.CompiledConstraintSystem, error) {
+	var cacheFile string
+	hash, ok := CircuitHash(blockSize)
+	if cacheDir != "" && !ok {
+		logx.Errorf("block size %d circuit can't be identified by the build info, it is not cached", blockSize)
+	}
+	if cacheDir != "" && ok {
+		cacheFile = filepath.Join(cacheDir, fmt.Sprintf("block%d-%s.r1cs", blockSize, hash[:16]))
+		ccs, err := readCircuit(cacheFile)
+		if err == nil {
+			logx.Infof("block size %d circuit is read from %s", blockSize, cacheFile)
+			return ccs, nil
+		}
+		if !os.IsNotExist(err) {
+			logx.Errorf("read block size %d circuit from %s failed, compile it again: %v", blockSize, cacheFile, err)
+		}
+	}
+
+	var circuit cryptoBlock.BlockConstraints
+	circuit.TxsCount = blockSize
+	circuit.Txs = make([]cryptoBlock.TxConstraints, blockSize)
+	for i := 0; i < blockSize; i++ {
+		circuit.Txs[i] = cryptoBlock.GetZeroTxConstraint()
+	}
+	logx.Infof("start compile block size %d circuit", blockSize)
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
+	if err != nil {
+		return nil, err
+	}
+	logx.Infof("circuit constraints: %d", ccs.GetNbConstraints())
+
+	if cacheFile != "" {
+		err = writeCircuit(cacheFile, ccs)
+		if err != nil {
+			logx.Errorf("cache block size %d circuit to %s failed: %v", blockSize, cacheFile, err)
+		}
+	}
+	return ccs, nil
+}
+
+func readCircuit(file string) (frontend.CompiledConstraintSystem, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ccs := groth16.NewCS(ecc.BN254)
+	_, err = ccs.ReadFrom(f)
+	if err != nil {
+		return nil, err
+	}
+	return ccs, nil
+}
+
+// writeCircuit writes the circuit to a temporary file first, so a partial file is never read.
+func writeCircuit(file string, ccs frontend.CompiledConstraintSystem) error {
+	err := os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = ccs.WriteTo(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
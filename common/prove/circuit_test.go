@@ -0,0 +1,71 @@
+package prove
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitHash(t *testing.T) {
+	deps := func() []*debug.Module {
+		return []*debug.Module{
+			{Path: "github.com/bnb-chain/zkbas-crypto", Version: "v0.0.1", Sum: "h1:crypto"},
+			{Path: "github.com/consensys/gnark", Version: "v0.7.0", Sum: "h1:gnark"},
+		}
+	}
+	hash1, ok := circuitHash(1, deps())
+	assert.True(t, ok)
+	hash, ok := circuitHash(1, deps())
+	assert.True(t, ok)
+	assert.Equal(t, hash1, hash)
+	hash, ok = circuitHash(10, deps())
+	assert.True(t, ok)
+	assert.NotEqual(t, hash1, hash)
+
+	// the replaced module is identified by the replacement.
+	replaced := deps()
+	replaced[0].Replace = &debug.Module{Path: "github.com/fork/zkbas-crypto", Version: "v0.0.2", Sum: "h1:fork"}
+	hash, ok = circuitHash(1, replaced)
+	assert.True(t, ok)
+	assert.NotEqual(t, hash1, hash)
+
+	// the circuit can't be identified by the module replaced by a local directory, without a version or missing.
+	replaced[0].Replace = &debug.Module{Path: "../zkbas-crypto"}
+	_, ok = circuitHash(1, replaced)
+	assert.False(t, ok)
+	devel := deps()
+	devel[1].Version, devel[1].Sum = "(devel)", ""
+	_, ok = circuitHash(1, devel)
+	assert.False(t, ok)
+	_, ok = circuitHash(1, deps()[:1])
+	assert.False(t, ok)
+}
+
+func TestCircuitCache(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "circuits", "square.r1cs")
+	_, err = readCircuit(file)
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, writeCircuit(file, ccs))
+	cached, err := readCircuit(file)
+	assert.NoError(t, err)
+	assert.Equal(t, ccs.GetNbConstraints(), cached.GetNbConstraints())
+	assert.Equal(t, ccs.GetSchema(), cached.GetSchema())
+
+	// the temporary files are removed.
+	files, err := os.ReadDir(filepath.Dir(file))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	assert.NoError(t, os.WriteFile(file, []byte("corrupted"), 0600))
+	_, err = readCircuit(file)
+	assert.Error(t, err)
+}
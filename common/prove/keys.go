@@ -0,0 +1,211 @@
+package prove
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	curve "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/fft"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+)
+
+// provingKey is the groth16 proving key of bn254 as it is encoded by gnark, the elements not checked are dropped.
+type provingKey struct {
+	cardinality uint64
+	g1          struct {
+		alpha, beta, delta curve.G1Affine
+		a, b, z, k         int
+	}
+	g2 struct {
+		beta, delta curve.G2Affine
+		b           int
+	}
+	nbWires, nbInfinityA, nbInfinityB uint64
+}
+
+// verifyingKey is the groth16 verifying key of bn254 as it is encoded by gnark.
+type verifyingKey struct {
+	g1 struct {
+		alpha, beta, delta curve.G1Affine
+		k                  []curve.G1Affine
+	}
+	g2 struct {
+		beta, gamma, delta curve.G2Affine
+	}
+}
+
+// SetupKeys runs the groth16 setup of the circuit and writes the keys to the files as LoadProvingKey and
+// LoadVerifyingKey read them. The toxic waste of the setup is known to nobody but it is not a ceremony, so the keys are
+// only for development. The existing files are not overwritten.
+func SetupKeys(ccs frontend.CompiledConstraintSystem, provingKeyFile, verifyingKeyFile string) error {
+	for _, file := range []string{provingKeyFile, verifyingKeyFile} {
+		if _, err := os.Stat(file); err == nil {
+			return fmt.Errorf("%s exists already", file)
+		}
+	}
+	pk, vk, err := groth16.Setup(ccs)
+	if err != nil {
+		return err
+	}
+	err = writeKey(provingKeyFile, pk)
+	if err != nil {
+		return err
+	}
+	return writeKey(verifyingKeyFile, vk)
+}
+
+func writeKey(file string, key interface {
+	WriteRawTo(w io.Writer) (int64, error)
+}) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	_, err = key.WriteRawTo(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(file)
+		return err
+	}
+	return f.Close()
+}
+
+// CheckKeys checks the proving and verifying keys are a pair generated for the circuit. The sizes of the keys must
+// agree with the constraints and the wires of the circuit, and the elements shared by the keys must be the same. The
+// keys of a circuit of the same shape can't be told apart, only a proof can tell if they are for the circuit.
+func CheckKeys(ccs frontend.CompiledConstraintSystem, provingKeyFile, verifyingKeyFile string) error {
+	pk, err := readProvingKey(provingKeyFile)
+	if err != nil {
+		return fmt.Errorf("read proving key %s failed: %v", provingKeyFile, err)
+	}
+	vk, err := readVerifyingKey(verifyingKeyFile)
+	if err != nil {
+		return fmt.Errorf("read verifying key %s failed: %v", verifyingKeyFile, err)
+	}
+
+	internal, secret, public := ccs.GetNbVariables()
+	nbWires := uint64(internal + secret + public)
+	if cardinality := ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints())); pk.cardinality != cardinality {
+		return fmt.Errorf("the proving key is for a domain of %d, but the circuit needs %d", pk.cardinality,
+			cardinality)
+	}
+	if pk.nbWires != nbWires {
+		return fmt.Errorf("the proving key is for %d wires, but the circuit has %d", pk.nbWires, nbWires)
+	}
+	if pk.g1.k != internal+secret {
+		return fmt.Errorf("the proving key is for %d private wires, but the circuit has %d", pk.g1.k,
+			internal+secret)
+	}
+	if pk.g1.z != int(pk.cardinality) ||
+		uint64(pk.g1.a)+pk.nbInfinityA != nbWires ||
+		uint64(pk.g1.b)+pk.nbInfinityB != nbWires ||
+		uint64(pk.g2.b)+pk.nbInfinityB != nbWires {
+		return fmt.Errorf("the proving key is corrupted")
+	}
+	if len(vk.g1.k) != public {
+		return fmt.Errorf("the verifying key is for %d public wires, but the circuit has %d", len(vk.g1.k), public)
+	}
+
+	if !pk.g1.alpha.Equal(&vk.g1.alpha) || !pk.g1.beta.Equal(&vk.g1.beta) || !pk.g1.delta.Equal(&vk.g1.delta) ||
+		!pk.g2.beta.Equal(&vk.g2.beta) || !pk.g2.delta.Equal(&vk.g2.delta) {
+		return fmt.Errorf("the proving key and the verifying key are not a pair")
+	}
+	// [β]1 and [β]2, [δ]1 and [δ]2 must be of the same β and δ.
+	_, _, g1, g2 := curve.Generators()
+	var g1Neg curve.G1Affine
+	g1Neg.Neg(&g1)
+	for _, pair := range []struct {
+		p1 curve.G1Affine
+		p2 curve.G2Affine
+	}{{vk.g1.beta, vk.g2.beta}, {vk.g1.delta, vk.g2.delta}} {
+		ok, err := curve.PairingCheck([]curve.G1Affine{pair.p1, g1Neg}, []curve.G2Affine{g2, pair.p2})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("the verifying key is corrupted")
+		}
+	}
+	return nil
+}
+
+// VerifierConstants returns the constants of the verifying key in the verifier contract. The vk are [α]1, [β]2, [γ]2
+// and [δ]2, and the ic are the points of the public inputs, in the order of the solidity verifier exported by gnark.
+func VerifierConstants(verifyingKeyFile string) (vkConstants []*big.Int, icConstants []*big.Int, err error) {
+	vk, err := readVerifyingKey(verifyingKeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	vkConstants = append(vkConstants, vk.g1.alpha.X.ToBigIntRegular(new(big.Int)),
+		vk.g1.alpha.Y.ToBigIntRegular(new(big.Int)))
+	for _, p := range []*curve.G2Affine{&vk.g2.beta, &vk.g2.gamma, &vk.g2.delta} {
+		vkConstants = append(vkConstants, p.X.A1.ToBigIntRegular(new(big.Int)), p.X.A0.ToBigIntRegular(new(big.Int)),
+			p.Y.A1.ToBigIntRegular(new(big.Int)), p.Y.A0.ToBigIntRegular(new(big.Int)))
+	}
+	for _, p := range vk.g1.k {
+		icConstants = append(icConstants, p.X.ToBigIntRegular(new(big.Int)), p.Y.ToBigIntRegular(new(big.Int)))
+	}
+	return vkConstants, icConstants, nil
+}
+
+func readProvingKey(file string) (*provingKey, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+
+	pk := &provingKey{}
+	var domain fft.Domain
+	_, err = domain.ReadFrom(r)
+	if err != nil {
+		return nil, err
+	}
+	pk.cardinality = domain.Cardinality
+
+	dec := curve.NewDecoder(r)
+	var a, b, z, k []curve.G1Affine
+	var g2b []curve.G2Affine
+	for _, v := range []interface{}{
+		&pk.g1.alpha, &pk.g1.beta, &pk.g1.delta, &a, &b, &z, &k,
+		&pk.g2.beta, &pk.g2.delta, &g2b,
+		&pk.nbWires, &pk.nbInfinityA, &pk.nbInfinityB,
+	} {
+		err = dec.Decode(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pk.g1.a, pk.g1.b, pk.g1.z, pk.g1.k, pk.g2.b = len(a), len(b), len(z), len(k), len(g2b)
+	return pk, nil
+}
+
+func readVerifyingKey(file string) (*verifyingKey, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	vk := &verifyingKey{}
+	dec := curve.NewDecoder(bufio.NewReader(f))
+	for _, v := range []interface{}{
+		&vk.g1.alpha, &vk.g1.beta, &vk.g2.beta, &vk.g2.gamma, &vk.g1.delta, &vk.g2.delta, &vk.g1.k,
+	} {
+		err = dec.Decode(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return vk, nil
+}
@@ -0,0 +1,69 @@
+package prove
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+)
+
+type cubeCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *cubeCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X, c.X), c.Y)
+	return nil
+}
+
+func TestCheckKeys(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	pk, vk := filepath.Join(dir, "square.pk"), filepath.Join(dir, "square.vk")
+	assert.NoError(t, SetupKeys(ccs, pk, vk))
+	assert.NoError(t, CheckKeys(ccs, pk, vk))
+
+	// the keys are not overwritten.
+	assert.Error(t, SetupKeys(ccs, pk, vk))
+
+	// the keys of another setup are not a pair.
+	otherPk, otherVk := filepath.Join(dir, "other.pk"), filepath.Join(dir, "other.vk")
+	assert.NoError(t, SetupKeys(ccs, otherPk, otherVk))
+	assert.EqualError(t, CheckKeys(ccs, pk, otherVk), "the proving key and the verifying key are not a pair")
+
+	// the keys are not for another circuit.
+	cube, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &cubeCircuit{})
+	assert.NoError(t, err)
+	assert.Error(t, CheckKeys(cube, pk, vk))
+
+	assert.Error(t, CheckKeys(ccs, filepath.Join(dir, "missing.pk"), vk))
+}
+
+func TestVerifierConstants(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	pk, vk := filepath.Join(dir, "square.pk"), filepath.Join(dir, "square.vk")
+	assert.NoError(t, SetupKeys(ccs, pk, vk))
+
+	vkConstants, icConstants, err := VerifierConstants(vk)
+	assert.NoError(t, err)
+	assert.Len(t, vkConstants, 14)
+	// the constant wire and the public input.
+	assert.Len(t, icConstants, 4)
+
+	// the constants are the ones in the verifier exported by gnark.
+	verifyingKey, err := LoadVerifyingKey(vk)
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, verifyingKey.ExportSolidity(&buf))
+	for _, c := range append(vkConstants, icConstants...) {
+		assert.Contains(t, buf.String(), "uint256("+c.String()+")")
+	}
+}
@@ -2,6 +2,8 @@ package prove
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
@@ -10,9 +12,18 @@ import (
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
+	"github.com/ethereum/go-ethereum/common"
 
 	cryptoBlock "github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
 	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+	"github.com/bnb-chain/zkbas/dao/block"
+)
+
+var (
+	// ErrProofNotVerified is returned by GenerateProof if the proof generated fails to be verified.
+	ErrProofNotVerified = errors.New("proof is not verified")
+	// ErrWitnessMismatch is returned by VerifyWitnessBlock if the witness differs from the committed blocks.
+	ErrWitnessMismatch = errors.New("witness differs from the block")
 )
 
 func LoadProvingKey(filepath string) (pk groth16.ProvingKey, err error) {
@@ -69,7 +80,7 @@ func GenerateProof(
 	}
 	err = groth16.Verify(proof, verifyingKey, vWitness)
 	if err != nil {
-		return proof, err
+		return proof, fmt.Errorf("%w: %v", ErrProofNotVerified, err)
 	}
 
 	return proof, nil
@@ -107,3 +118,85 @@ func FormatProof(oProof groth16.Proof, oldRoot, newRoot, commitment []byte) (pro
 	proof.Inputs[2] = new(big.Int).SetBytes(commitment)
 	return proof, nil
 }
+
+// ParseProof converts the formatted proof back to the groth16 proof, it is the reverse of FormatProof.
+func ParseProof(proof *FormattedProof) (oProof groth16.Proof, err error) {
+	const fpSize = 4 * 8
+	var buf bytes.Buffer
+	for _, v := range []*big.Int{proof.A[0], proof.A[1], proof.B[0][0], proof.B[0][1], proof.B[1][0], proof.B[1][1],
+		proof.C[0], proof.C[1]} {
+		if v == nil || v.BitLen() > fpSize*8 {
+			return nil, fmt.Errorf("invalid proof element")
+		}
+		buf.Write(v.FillBytes(make([]byte, fpSize)))
+	}
+	oProof = groth16.NewProof(ecc.BN254)
+	_, err = oProof.ReadFrom(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return oProof, nil
+}
+
+// VerifyProof verifies the formatted proof of the block, the public inputs of the proof must be the old and new state
+// roots and the commitment of the block.
+func VerifyProof(proof *FormattedProof, verifyingKey groth16.VerifyingKey, cBlock *cryptoBlock.Block) error {
+	for i, input := range [][]byte{cBlock.OldStateRoot, cBlock.NewStateRoot, cBlock.BlockCommitment} {
+		if proof.Inputs[i] == nil || proof.Inputs[i].Cmp(new(big.Int).SetBytes(input)) != 0 {
+			return fmt.Errorf("public input %d mismatch", i)
+		}
+	}
+	oProof, err := ParseProof(proof)
+	if err != nil {
+		return err
+	}
+	var verifyWitness cryptoBlock.BlockConstraints
+	verifyWitness.OldStateRoot = cBlock.OldStateRoot
+	verifyWitness.NewStateRoot = cBlock.NewStateRoot
+	verifyWitness.BlockCommitment = cBlock.BlockCommitment
+	vWitness, err := frontend.NewWitness(&verifyWitness, ecc.BN254, frontend.PublicOnly())
+	if err != nil {
+		return err
+	}
+	return groth16.Verify(oProof, verifyingKey, vWitness)
+}
+
+// VerifyProofInfo verifies the json of the formatted proof stored in the proof table, see VerifyProof.
+func VerifyProofInfo(proofInfo string, verifyingKey groth16.VerifyingKey, cBlock *cryptoBlock.Block) error {
+	var proof *FormattedProof
+	err := json.Unmarshal([]byte(proofInfo), &proof)
+	if err != nil {
+		return err
+	}
+	if proof == nil {
+		return fmt.Errorf("empty proof")
+	}
+	return VerifyProof(proof, verifyingKey, cBlock)
+}
+
+// VerifyWitnessBlock checks the state roots and the commitment of the witness, i.e. the public inputs of its proof,
+// against the block and its parent in the block table. The proof of a witness differing from the committed blocks is
+// reverted by the rollup contract even if it is verified.
+func VerifyWitnessBlock(blockModel block.BlockModel, height int64, cBlock *cryptoBlock.Block) error {
+	b, err := blockModel.GetBlockByHeightWithoutTx(height)
+	if err != nil {
+		return fmt.Errorf("get block %d failed: %v", height, err)
+	}
+	parent, err := blockModel.GetBlockByHeightWithoutTx(height - 1)
+	if err != nil {
+		return fmt.Errorf("get block %d failed: %v", height-1, err)
+	}
+	if !bytes.Equal(cBlock.OldStateRoot, common.FromHex(parent.StateRoot)) {
+		return fmt.Errorf("%w: old state root of the witness %x differs from the block %d %s", ErrWitnessMismatch,
+			cBlock.OldStateRoot, height-1, parent.StateRoot)
+	}
+	if !bytes.Equal(cBlock.NewStateRoot, common.FromHex(b.StateRoot)) {
+		return fmt.Errorf("%w: new state root of the witness %x differs from the block %s", ErrWitnessMismatch,
+			cBlock.NewStateRoot, b.StateRoot)
+	}
+	if !bytes.Equal(cBlock.BlockCommitment, common.FromHex(b.BlockCommitment)) {
+		return fmt.Errorf("%w: commitment of the witness %x differs from the block %s", ErrWitnessMismatch,
+			cBlock.BlockCommitment, b.BlockCommitment)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package prove
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+)
+
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+func TestParseProof(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(t, err)
+	pk, vk, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+	witness, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, ecc.BN254)
+	assert.NoError(t, err)
+	publicWitness, err := witness.Public()
+	assert.NoError(t, err)
+	proof, err := groth16.Prove(ccs, pk, witness)
+	assert.NoError(t, err)
+
+	formatted, err := FormatProof(proof, nil, nil, nil)
+	assert.NoError(t, err)
+	parsed, err := ParseProof(formatted)
+	assert.NoError(t, err)
+	assert.NoError(t, groth16.Verify(parsed, vk, publicWitness))
+
+	var expected, actual bytes.Buffer
+	_, err = proof.WriteRawTo(&expected)
+	assert.NoError(t, err)
+	_, err = parsed.WriteRawTo(&actual)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Bytes(), actual.Bytes())
+
+	// the tampered proof is not verified.
+	formatted.C[0], formatted.C[1] = formatted.A[0], formatted.A[1]
+	parsed, err = ParseProof(formatted)
+	assert.NoError(t, err)
+	assert.Error(t, groth16.Verify(parsed, vk, publicWitness))
+}
@@ -38,13 +38,13 @@ type WitnessHelper struct {
 
 	// Trees
 	accountTree   bsmt.SparseMerkleTree
-	assetTrees    *[]bsmt.SparseMerkleTree
+	assetTrees    *tree.AssetTreeCache
 	liquidityTree bsmt.SparseMerkleTree
 	nftTree       bsmt.SparseMerkleTree
 }
 
 func NewWitnessHelper(treeCtx *tree.Context, accountTree, liquidityTree, nftTree bsmt.SparseMerkleTree,
-	assetTrees *[]bsmt.SparseMerkleTree, accountModel AccountModel) *WitnessHelper {
+	assetTrees *tree.AssetTreeCache, accountModel AccountModel) *WitnessHelper {
 	return &WitnessHelper{
 		treeCtx:       treeCtx,
 		accountModel:  accountModel,
@@ -191,6 +191,7 @@ func (w *WitnessHelper) constructAccountWitness(
 	for _, accountKey := range accountKeys {
 		var (
 			cryptoAccount = new(CryptoAccount)
+			assetTree     bsmt.SparseMerkleTree
 			// get account asset before
 			assetCount = 0
 		)
@@ -201,15 +202,14 @@ func (w *WitnessHelper) constructAccountWitness(
 		}
 		// it means this is a registerZNS tx
 		if proverAccounts == nil {
-			if accountKey != int64(len(*w.assetTrees)) {
+			if accountKey != w.assetTrees.GetNextAccountIndex() {
 				return accountRootBefore, accountsInfoBefore, merkleProofsAccountAssetsBefore, merkleProofsAccountBefore,
 					fmt.Errorf("invalid key")
 			}
-			emptyAccountAssetTree, err := tree.NewEmptyAccountAssetTree(w.treeCtx, accountKey, finalityBlockNr)
+			assetTree, err = w.assetTrees.AddAccount(accountKey)
 			if err != nil {
 				return accountRootBefore, accountsInfoBefore, merkleProofsAccountAssetsBefore, merkleProofsAccountBefore, err
 			}
-			*w.assetTrees = append(*w.assetTrees, emptyAccountAssetTree)
 			cryptoAccount = std.EmptyAccount(accountKey, tree.NilAccountAssetRoot)
 			// update account info
 			accountInfo, err := w.accountModel.GetConfirmedAccountByIndex(accountKey)
@@ -231,6 +231,10 @@ func (w *WitnessHelper) constructAccountWitness(
 				},
 			})
 		} else {
+			assetTree, err = w.assetTrees.GetForUpdate(accountKey)
+			if err != nil {
+				return accountRootBefore, accountsInfoBefore, merkleProofsAccountAssetsBefore, merkleProofsAccountBefore, err
+			}
 			proverAccountInfo := proverAccounts[accountCount]
 			pk, err := common2.ParsePubKey(proverAccountInfo.AccountInfo.PublicKey)
 			if err != nil {
@@ -242,10 +246,10 @@ func (w *WitnessHelper) constructAccountWitness(
 				AccountPk:       pk,
 				Nonce:           proverAccountInfo.AccountInfo.Nonce,
 				CollectionNonce: proverAccountInfo.AccountInfo.CollectionNonce,
-				AssetRoot:       (*w.assetTrees)[accountKey].Root(),
+				AssetRoot:       assetTree.Root(),
 			}
 			for i, accountAsset := range proverAccountInfo.AccountAssets {
-				assetMerkleProof, err := (*w.assetTrees)[accountKey].GetProof(uint64(accountAsset.AssetId))
+				assetMerkleProof, err := assetTree.GetProof(uint64(accountAsset.AssetId))
 				if err != nil {
 					return accountRootBefore, accountsInfoBefore, merkleProofsAccountAssetsBefore, merkleProofsAccountBefore, err
 				}
@@ -279,7 +283,7 @@ func (w *WitnessHelper) constructAccountWitness(
 				if err != nil {
 					return accountRootBefore, accountsInfoBefore, merkleProofsAccountAssetsBefore, merkleProofsAccountBefore, err
 				}
-				err = (*w.assetTrees)[accountKey].Set(uint64(accountAsset.AssetId), nAssetHash)
+				err = assetTree.Set(uint64(accountAsset.AssetId), nAssetHash)
 				if err != nil {
 					return accountRootBefore, accountsInfoBefore, merkleProofsAccountAssetsBefore, merkleProofsAccountBefore, err
 				}
@@ -293,7 +297,7 @@ func (w *WitnessHelper) constructAccountWitness(
 		// padding empty account asset
 		for assetCount < NbAccountAssetsPerAccount {
 			cryptoAccount.AssetsInfo[assetCount] = std.EmptyAccountAsset(LastAccountAssetId)
-			assetMerkleProof, err := (*w.assetTrees)[accountKey].GetProof(LastAccountAssetId)
+			assetMerkleProof, err := assetTree.GetProof(LastAccountAssetId)
 			if err != nil {
 				return accountRootBefore, accountsInfoBefore, merkleProofsAccountAssetsBefore, merkleProofsAccountBefore, err
 			}
@@ -322,7 +326,7 @@ func (w *WitnessHelper) constructAccountWitness(
 			proverAccounts[accountCount].AccountInfo.PublicKey,
 			nonce,
 			collectionNonce,
-			(*w.assetTrees)[accountKey].Root(),
+			assetTree.Root(),
 		)
 		if err != nil {
 			return accountRootBefore, accountsInfoBefore, merkleProofsAccountAssetsBefore, merkleProofsAccountBefore, err
@@ -94,7 +94,7 @@ func getWitnessHelper(blockHeight int64) (*WitnessHelper, error) {
 		accountTree,
 		liquidityTree,
 		nftTree,
-		&accountAssetTrees,
+		accountAssetTrees,
 		accountModel), nil
 }
 
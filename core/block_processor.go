@@ -2,13 +2,17 @@ package core
 
 import (
 	"fmt"
+	"runtime"
+	"sync"
 
 	"github.com/bnb-chain/zkbas/core/executor"
+	"github.com/bnb-chain/zkbas/core/hook"
 	"github.com/bnb-chain/zkbas/dao/tx"
 )
 
 type Processor interface {
 	Process(tx *tx.Tx) error
+	ProcessTxs(txs []*tx.Tx) []error
 }
 
 type CommitProcessor struct {
@@ -25,6 +29,38 @@ func (p *CommitProcessor) Process(tx *tx.Tx) error {
 	p.bc.setCurrentBlockTimeStamp()
 	defer p.bc.resetCurrentBlockTimeStamp()
 
+	return p.process(tx)
+}
+
+// ProcessTxs processes the txs in order and returns the error of each tx, the result is identical to processing
+// them one by one. The consecutive L2 txs are prepared and verified concurrently against isolated views of the
+// state, then applied in order. A tx which has read the states written by a former tx of the same run is
+// prepared and verified again on the latest states before it is applied. The txs are applied in order since every
+// tx records the state root after it, the asset trees of the accounts of a tx are updated concurrently though, see
+// StateDB.UpdateAccountTree.
+func (p *CommitProcessor) ProcessTxs(txs []*tx.Tx) []error {
+	p.bc.setCurrentBlockTimeStamp()
+	defer p.bc.resetCurrentBlockTimeStamp()
+
+	errs := make([]error, len(txs))
+	for start := 0; start < len(txs); {
+		if !isSpeculative(txs[start].TxType) {
+			errs[start] = p.process(txs[start])
+			start++
+			continue
+		}
+
+		end := start + 1
+		for end < len(txs) && isSpeculative(txs[end].TxType) {
+			end++
+		}
+		p.processSpeculatively(txs[start:end], errs[start:end])
+		start = end
+	}
+	return errs
+}
+
+func (p *CommitProcessor) process(tx *tx.Tx) error {
 	executor, err := executor.NewTxExecutor(p.bc, tx)
 	if err != nil {
 		return fmt.Errorf("new tx executor failed")
@@ -34,10 +70,56 @@ func (p *CommitProcessor) Process(tx *tx.Tx) error {
 	if err != nil {
 		return err
 	}
+	err = hook.PreExecute(p.bc.hook, p.bc.Statedb, tx)
+	if err != nil {
+		return err
+	}
 	err = executor.VerifyInputs()
 	if err != nil {
 		return err
 	}
+	return p.apply(executor, tx)
+}
+
+func (p *CommitProcessor) processSpeculatively(txs []*tx.Tx, errs []error) {
+	speculations := make([]*speculation, len(txs))
+	var wg sync.WaitGroup
+	jobs := make(chan int)
+	for w := 0; w < runtime.NumCPU() && w < len(txs); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				speculations[i] = speculate(p.bc, txs[i])
+			}
+		}()
+	}
+	for i := range txs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	written := newStateKeys()
+	for i, tx := range txs {
+		s := speculations[i]
+		if written.conflicts(s.view()) {
+			s = speculate(p.bc, tx)
+		}
+		if s.err != nil {
+			errs[i] = s.err
+			continue
+		}
+
+		written.add(s.view(), creditOnlyAccount(tx))
+		p.bc.Statedb.Merge(s.view())
+		s.chain.statedb = p.bc.Statedb
+		errs[i] = p.apply(s.executor, tx)
+	}
+}
+
+// apply applies the tx verified by the executor to the state.
+func (p *CommitProcessor) apply(executor executor.TxExecutor, tx *tx.Tx) error {
 	txDetails, err := executor.GenerateTxDetails()
 	if err != nil {
 		return err
@@ -0,0 +1,202 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
+	"github.com/bnb-chain/zkbas/common/chain"
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/account"
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/liquidity"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/dao/tx"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+const testAccountNums = 5
+
+type testAccountHistoryModel struct {
+	account.AccountHistoryModel
+	accounts map[int64]*account.Account
+}
+
+func (m *testAccountHistoryModel) GetValidAccountCount(_ int64) (int64, error) {
+	return int64(len(m.accounts)), nil
+}
+
+func (m *testAccountHistoryModel) GetValidAccounts(_ int64, limit int, offset int) (int64, []*account.AccountHistory, error) {
+	var histories []*account.AccountHistory
+	for i := int64(offset); i < int64(offset+limit) && i < int64(len(m.accounts)); i++ {
+		histories = append(histories, &account.AccountHistory{
+			AccountIndex:    i,
+			Nonce:           m.accounts[i].Nonce,
+			CollectionNonce: m.accounts[i].CollectionNonce,
+			AssetInfo:       m.accounts[i].AssetInfo,
+		})
+	}
+	return int64(len(histories)), histories, nil
+}
+
+type emptyLiquidityHistoryModel struct {
+	liquidity.LiquidityHistoryModel
+}
+
+func (m *emptyLiquidityHistoryModel) GetLatestLiquidityCountByBlockHeight(_ int64) (int64, error) {
+	return 0, nil
+}
+
+type emptyNftHistoryModel struct {
+	nft.L2NftHistoryModel
+}
+
+func (m *emptyNftHistoryModel) GetLatestNftAssetCountByBlockHeight(_ int64) (int64, error) {
+	return 0, nil
+}
+
+// testAccounts returns the accounts with 100 of asset 0, the account 0 collects the gas.
+func testAccounts(t *testing.T) (map[int64]*account.Account, map[int64]*eddsa.PrivateKey) {
+	accounts := make(map[int64]*account.Account)
+	keys := make(map[int64]*eddsa.PrivateKey)
+	for i := int64(0); i < testAccountNums; i++ {
+		key, err := eddsa.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		keys[i] = key
+		accounts[i], err = chain.FromFormatAccountInfo(&types.AccountInfo{
+			AccountIndex:    i,
+			AccountName:     fmt.Sprintf("account%d.legend", i),
+			AccountNameHash: fmt.Sprintf("0x%064x", i+1),
+			PublicKey:       hex.EncodeToString(key.PublicKey.Bytes()),
+			Nonce:           0,
+			CollectionNonce: 0,
+			AssetInfo: map[int64]*types.AccountAsset{
+				0: {AssetId: 0, Balance: big.NewInt(100), LpAmount: big.NewInt(0), OfferCanceledOrFinalized: big.NewInt(0)},
+			},
+		})
+		assert.NoError(t, err)
+	}
+	return accounts, keys
+}
+
+func newTestBlockChain(t *testing.T, accounts map[int64]*account.Account) *BlockChain {
+	chainDb := &sdb.ChainDB{
+		AccountModel:          &testAccountModel{accounts: accounts},
+		AccountHistoryModel:   &testAccountHistoryModel{accounts: accounts},
+		LiquidityHistoryModel: &emptyLiquidityHistoryModel{},
+		L2NftHistoryModel:     &emptyNftHistoryModel{},
+	}
+	statedb, err := sdb.NewStateDB(&tree.Context{Driver: tree.MemoryDB}, chainDb, &emptyCache{}, "", 0)
+	assert.NoError(t, err)
+	bc := &BlockChain{
+		ChainDB:      chainDb,
+		Statedb:      statedb,
+		currentBlock: &block.Block{BlockHeight: 1},
+	}
+	bc.processor = NewCommitProcessor(bc)
+	return bc
+}
+
+func signedWithdrawTx(t *testing.T, key *eddsa.PrivateKey, from int64, amount int64, nonce int64) *tx.Tx {
+	txInfo := &legendTxTypes.WithdrawTxInfo{
+		FromAccountIndex:  from,
+		AssetId:           0,
+		AssetAmount:       big.NewInt(amount),
+		GasAccountIndex:   0,
+		GasFeeAssetId:     0,
+		GasFeeAssetAmount: big.NewInt(1),
+		ToAddress:         "0x0000000000000000000000000000000000000001",
+		ExpiredAt:         time.Now().Add(time.Hour).UnixMilli(),
+		Nonce:             nonce,
+	}
+	msgHash, err := legendTxTypes.ComputeWithdrawMsgHash(txInfo, mimc.NewMiMC())
+	assert.NoError(t, err)
+	txInfo.Sig, err = key.Sign(msgHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	txInfoBytes, err := json.Marshal(txInfo)
+	assert.NoError(t, err)
+	return &tx.Tx{TxType: types.TxTypeWithdraw, TxInfo: string(txInfoBytes)}
+}
+
+func signedTransferTx(t *testing.T, key *eddsa.PrivateKey, from int64, to int64, amount int64, nonce int64) *tx.Tx {
+	callDataHash := make([]byte, 32)
+	callDataHash[31] = 1
+	txInfo := &legendTxTypes.TransferTxInfo{
+		FromAccountIndex:  from,
+		ToAccountIndex:    to,
+		ToAccountNameHash: fmt.Sprintf("0x%064x", to+1),
+		AssetId:           0,
+		AssetAmount:       big.NewInt(amount),
+		GasAccountIndex:   0,
+		GasFeeAssetId:     0,
+		GasFeeAssetAmount: big.NewInt(1),
+		CallDataHash:      callDataHash,
+		ExpiredAt:         time.Now().Add(time.Hour).UnixMilli(),
+		Nonce:             nonce,
+	}
+	msgHash, err := legendTxTypes.ComputeTransferMsgHash(txInfo, mimc.NewMiMC())
+	assert.NoError(t, err)
+	txInfo.Sig, err = key.Sign(msgHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	txInfoBytes, err := json.Marshal(txInfo)
+	assert.NoError(t, err)
+	return &tx.Tx{TxType: types.TxTypeTransfer, TxInfo: string(txInfoBytes)}
+}
+
+func TestProcessTxsInParallel(t *testing.T) {
+	accounts, keys := testAccounts(t)
+	newTxs := func() []*tx.Tx {
+		return []*tx.Tx{
+			signedWithdrawTx(t, keys[1], 1, 10, 0),
+			signedTransferTx(t, keys[2], 2, 3, 50, 0),
+			// spends the amount received from the former tx.
+			signedWithdrawTx(t, keys[3], 3, 120, 0),
+			// the second tx of the account.
+			signedWithdrawTx(t, keys[1], 1, 10, 1),
+			signedWithdrawTx(t, keys[4], 4, 10, 0),
+			// the balance is spent by the former transfer.
+			signedWithdrawTx(t, keys[2], 2, 60, 1),
+			signedWithdrawTx(t, keys[4], 4, 10, 5),
+			signedTransferTx(t, keys[4], 4, 1, 20, 1),
+			signedWithdrawTx(t, keys[1], 1, 95, 2),
+		}
+	}
+
+	sequential := newTestBlockChain(t, accounts)
+	sequentialTxs := newTxs()
+	sequentialErrs := make([]error, len(sequentialTxs))
+	for i, tx := range sequentialTxs {
+		sequentialErrs[i] = sequential.ApplyTransaction(tx)
+	}
+
+	parallel := newTestBlockChain(t, accounts)
+	parallelTxs := newTxs()
+	parallelErrs := parallel.ApplyTransactions(parallelTxs)
+
+	assert.Len(t, parallelErrs, len(sequentialErrs))
+	for i, err := range parallelErrs {
+		if i == 5 || i == 6 {
+			assert.EqualError(t, err, sequentialErrs[i].Error())
+		} else {
+			assert.NoError(t, err)
+			assert.NoError(t, sequentialErrs[i])
+		}
+	}
+	assert.Equal(t, len(sequential.Statedb.Txs), len(parallel.Statedb.Txs))
+	for i := range sequential.Statedb.Txs {
+		assert.Equal(t, sequential.Statedb.Txs[i].StateRoot, parallel.Statedb.Txs[i].StateRoot)
+		assert.Equal(t, sequential.Statedb.Txs[i].TxDetails, parallel.Statedb.Txs[i].TxDetails)
+	}
+	assert.Equal(t, sequential.Statedb.GetStateRoot(), parallel.Statedb.GetStateRoot())
+	assert.Equal(t, sequential.Statedb.PubData, parallel.Statedb.PubData)
+}
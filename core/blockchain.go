@@ -12,7 +12,10 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
 	"github.com/bnb-chain/zkbas/common/chain"
+	"github.com/bnb-chain/zkbas/core/executor"
+	"github.com/bnb-chain/zkbas/core/hook"
 	sdb "github.com/bnb-chain/zkbas/core/statedb"
 	"github.com/bnb-chain/zkbas/dao/account"
 	"github.com/bnb-chain/zkbas/dao/block"
@@ -23,6 +26,7 @@ import (
 	"github.com/bnb-chain/zkbas/dao/nft"
 	"github.com/bnb-chain/zkbas/dao/tx"
 	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
 )
 
 type ChainConfig struct {
@@ -35,19 +39,41 @@ type ChainConfig struct {
 		//nolint:staticcheck
 		LevelDBOption tree.LevelDBOption `json:",optional"`
 		//nolint:staticcheck
+		PebbleDBOption tree.PebbleDBOption `json:",optional"`
+		//nolint:staticcheck
 		RedisDBOption tree.RedisDBOption `json:",optional"`
+		//nolint:staticcheck
+		Prune tree.PruneConfig `json:",optional"`
+		// the number of the asset trees kept in memory after they are committed
+		//nolint:staticcheck
+		AssetTreeCacheSize int `json:",optional"`
 	}
+	TxHooks hook.Config
+}
+
+// SimulateOption relaxes the verification of the txs in dry run mode, so that a tx can be simulated before it is
+// signed or before the former txs of the account are sent.
+type SimulateOption struct {
+	SkipSignature bool // the signature of the tx is not verified, the ones of the offers in it still are
+	SkipNonce     bool // the nonce of the tx is not verified
 }
 
 type BlockChain struct {
 	*sdb.ChainDB
 	Statedb *sdb.StateDB // Cache for current block changes.
+	Pruner  *tree.Pruner // Prunes the versions of the trees, nil in dry run mode.
 
 	chainConfig *ChainConfig
 	dryRun      bool //dryRun mode is used for verifying user inputs, is not for execution
 
 	currentBlock *block.Block
 	processor    Processor
+	hook         hook.Hook // screens the L2 txs before their inputs are verified, nil means no screening
+
+	// the next nonces of the accounts which have sent txs in dry run mode
+	dryRunNonces map[int64]int64
+	// the verifications skipped in dry run mode
+	simulateOption SimulateOption
 }
 
 func NewBlockChain(config *ChainConfig, moduleName string) (*BlockChain, error) {
@@ -60,6 +86,10 @@ func NewBlockChain(config *ChainConfig, moduleName string) (*BlockChain, error)
 		ChainDB:     sdb.NewChainDB(db),
 		chainConfig: config,
 	}
+	bc.hook, err = hook.NewHook(&config.TxHooks)
+	if err != nil {
+		return nil, fmt.Errorf("new tx hooks failed: %v", err)
+	}
 
 	curHeight, err := bc.BlockModel.GetCurrentHeight()
 	if err != nil {
@@ -76,15 +106,18 @@ func NewBlockChain(config *ChainConfig, moduleName string) (*BlockChain, error)
 	}
 	redisCache := dbcache.NewRedisCache(config.CacheRedis[0].Host, config.CacheRedis[0].Pass, 15*time.Minute)
 	treeCtx := &tree.Context{
-		Name:          moduleName,
-		Driver:        config.TreeDB.Driver,
-		LevelDBOption: &config.TreeDB.LevelDBOption,
-		RedisDBOption: &config.TreeDB.RedisDBOption,
+		Name:           moduleName,
+		Driver:         config.TreeDB.Driver,
+		LevelDBOption:  &config.TreeDB.LevelDBOption,
+		PebbleDBOption: &config.TreeDB.PebbleDBOption,
+		RedisDBOption:  &config.TreeDB.RedisDBOption,
 	}
+	treeCtx.SetAssetTreeCacheSize(config.TreeDB.AssetTreeCacheSize)
 	bc.Statedb, err = sdb.NewStateDB(treeCtx, bc.ChainDB, redisCache, bc.currentBlock.StateRoot, curHeight)
 	if err != nil {
 		return nil, err
 	}
+	bc.Pruner = tree.NewPruner(treeCtx, config.TreeDB.Prune, bc.BlockModel, nil)
 	bc.processor = NewCommitProcessor(bc)
 	return bc, nil
 }
@@ -92,25 +125,89 @@ func NewBlockChain(config *ChainConfig, moduleName string) (*BlockChain, error)
 // NewBlockChainForDryRun - for dry run mode, we can reuse existing models for quick creation
 // , e.g., for sending tx, we can create blockchain for each request quickly
 func NewBlockChainForDryRun(accountModel account.AccountModel, liquidityModel liquidity.LiquidityModel,
-	nftModel nft.L2NftModel, mempoolModel mempool.MempoolModel, redisCache dbcache.Cache) *BlockChain {
+	nftModel nft.L2NftModel, mempoolModel mempool.MempoolModel, blockModel block.BlockModel, redisCache dbcache.Cache,
+	txHook hook.Hook) *BlockChain {
 	chainDb := &sdb.ChainDB{
 		AccountModel:   accountModel,
 		LiquidityModel: liquidityModel,
 		L2NftModel:     nftModel,
 		MempoolModel:   mempoolModel,
+		BlockModel:     blockModel,
 	}
 	bc := &BlockChain{
-		ChainDB: chainDb,
-		dryRun:  true,
-		Statedb: sdb.NewStateDBForDryRun(redisCache, chainDb),
+		ChainDB:      chainDb,
+		dryRun:       true,
+		Statedb:      sdb.NewStateDBForDryRun(redisCache, chainDb),
+		hook:         txHook,
+		dryRunNonces: make(map[int64]int64),
 	}
 	return bc
 }
 
+// NewBlockChainForSimulation creates a blockchain in dry run mode which skips the verifications of the option.
+func NewBlockChainForSimulation(accountModel account.AccountModel, liquidityModel liquidity.LiquidityModel,
+	nftModel nft.L2NftModel, mempoolModel mempool.MempoolModel, blockModel block.BlockModel, redisCache dbcache.Cache,
+	txHook hook.Hook, option SimulateOption) *BlockChain {
+	bc := NewBlockChainForDryRun(accountModel, liquidityModel, nftModel, mempoolModel, blockModel, redisCache, txHook)
+	bc.simulateOption = option
+	return bc
+}
+
 func (bc *BlockChain) ApplyTransaction(tx *tx.Tx) error {
 	return bc.processor.Process(tx)
 }
 
+// ApplyTransactions applies the txs in order and returns the error of each tx, the independent txs are
+// verified in parallel.
+func (bc *BlockChain) ApplyTransactions(txs []*tx.Tx) []error {
+	return bc.processor.ProcessTxs(txs)
+}
+
+// DryRunTransaction verifies the tx and applies it to the dry run state, so that the following txs are
+// verified against the state accumulated by the former ones. The trees are not updated in dry run mode.
+func (bc *BlockChain) DryRunTransaction(tx *tx.Tx) (*mempool.MempoolTx, error) {
+	if !bc.dryRun {
+		return nil, errors.New("not in dry run mode")
+	}
+	if !types.IsL2Tx(tx.TxType) {
+		return nil, errors.New("invalid tx type")
+	}
+
+	executor, err := executor.NewTxExecutor(bc, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = executor.Prepare()
+	if err != nil {
+		return nil, err
+	}
+	err = hook.PreExecute(bc.hook, bc.Statedb, tx)
+	if err != nil {
+		return nil, err
+	}
+	err = executor.VerifyInputs()
+	if err != nil {
+		return nil, err
+	}
+	mempoolTx, err := executor.GenerateMempoolTx()
+	if err != nil {
+		return nil, err
+	}
+	txDetails, err := executor.GenerateTxDetails()
+	if err != nil {
+		return nil, err
+	}
+	tx.TxDetails = txDetails
+	err = executor.ApplyTransaction()
+	if err != nil {
+		return nil, err
+	}
+
+	bc.dryRunNonces[mempoolTx.AccountIndex] = mempoolTx.Nonce + 1
+	bc.Statedb.Txs = append(bc.Statedb.Txs, tx)
+	return mempoolTx, nil
+}
+
 func (bc *BlockChain) ProposeNewBlock() (*block.Block, error) {
 	newBlock := &block.Block{
 		Model: gorm.Model{
@@ -138,7 +235,7 @@ func (bc *BlockChain) CommitNewBlock(blockSize int, createdAt int64) (*block.Blo
 	}
 
 	currentHeight := bc.currentBlock.BlockHeight
-	err = tree.CommitTrees(uint64(currentHeight), bc.Statedb.AccountTree, &bc.Statedb.AccountAssetTrees, bc.Statedb.LiquidityTree, bc.Statedb.NftTree)
+	err = bc.Pruner.CommitTrees(bc.Statedb.AccountTree, bc.Statedb.AccountAssetTrees, bc.Statedb.LiquidityTree, bc.Statedb.NftTree)
 	if err != nil {
 		return nil, err
 	}
@@ -253,17 +350,45 @@ func (bc *BlockChain) VerifyNonce(accountIndex int64, nonce int64) error {
 			return errors.New("invalid Nonce")
 		}
 	} else {
+		if bc.simulateOption.SkipNonce {
+			return nil
+		}
+		if expectNonce, ok := bc.dryRunNonces[accountIndex]; ok {
+			if nonce != expectNonce {
+				return errors.New("invalid Nonce")
+			}
+			return nil
+		}
 		pendingNonce, err := bc.Statedb.GetPendingNonce(accountIndex)
 		if err != nil {
 			return err
 		}
-		if pendingNonce != nonce {
+		if nonce == pendingNonce {
+			return nil
+		}
+		// a pending tx with the same nonce can be replaced, the replacement fee is checked by the caller
+		_, err = bc.MempoolModel.GetPendingMempoolTxByAccountNonce(accountIndex, nonce)
+		if err == nil {
+			return nil
+		}
+		if err != types.DbErrNotFound {
+			return err
+		}
+		// a tx with a higher nonce is queued until the gap is filled, the distance is limited by the caller
+		if nonce < pendingNonce {
 			return errors.New("invalid Nonce")
 		}
 	}
 	return nil
 }
 
+func (bc *BlockChain) VerifySignature(txInfo legendTxTypes.TxInfo) error {
+	if bc.dryRun && bc.simulateOption.SkipSignature {
+		return nil
+	}
+	return txInfo.VerifySignature(bc.Statedb.AccountMap[txInfo.GetFromAccountIndex()].PublicKey)
+}
+
 func (bc *BlockChain) StateDB() *sdb.StateDB {
 	return bc.Statedb
 }
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/consensys/gnark-crypto/ecc/bn254/twistededwards/eddsa"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
+	"github.com/bnb-chain/zkbas/common/chain"
+	"github.com/bnb-chain/zkbas/dao/account"
+	"github.com/bnb-chain/zkbas/dao/dbcache"
+	"github.com/bnb-chain/zkbas/dao/mempool"
+	"github.com/bnb-chain/zkbas/dao/tx"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+type emptyCache struct {
+	dbcache.Cache
+}
+
+func (c *emptyCache) Get(_ context.Context, _ string, _ interface{}) (interface{}, error) {
+	return nil, errors.New("redis: nil")
+}
+
+type testAccountModel struct {
+	account.AccountModel
+	accounts map[int64]*account.Account
+}
+
+func (m *testAccountModel) GetAccountByIndex(accountIndex int64) (*account.Account, error) {
+	accountInfo, ok := m.accounts[accountIndex]
+	if !ok {
+		return nil, types.DbErrNotFound
+	}
+	return accountInfo, nil
+}
+
+type emptyMempoolModel struct {
+	mempool.MempoolModel
+}
+
+func (m *emptyMempoolModel) GetNoncesByAccountIndex(_ int64, _ int64) ([]int64, error) {
+	return nil, nil
+}
+
+func (m *emptyMempoolModel) GetPendingMempoolTxByAccountNonce(_ int64, _ int64) (*mempool.MempoolTx, error) {
+	return nil, types.DbErrNotFound
+}
+
+func TestSimulateUnsignedTx(t *testing.T) {
+	key, err := eddsa.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	accountModel := &testAccountModel{accounts: make(map[int64]*account.Account)}
+	for _, accountIndex := range []int64{1, 2} {
+		accountInfo, err := chain.FromFormatAccountInfo(&types.AccountInfo{
+			AccountIndex: accountIndex,
+			PublicKey:    hex.EncodeToString(key.PublicKey.Bytes()),
+			Nonce:        3,
+			AssetInfo: map[int64]*types.AccountAsset{
+				0: {AssetId: 0, Balance: big.NewInt(100), LpAmount: big.NewInt(0), OfferCanceledOrFinalized: big.NewInt(0)},
+			},
+		})
+		assert.NoError(t, err)
+		accountModel.accounts[accountIndex] = accountInfo
+	}
+	newTx := func(nonce int64) *tx.Tx {
+		return &tx.Tx{TxType: types.TxTypeWithdraw, TxInfo: fmt.Sprintf(`{"FromAccountIndex":1,"AssetId":0,`+
+			`"AssetAmount":10,"GasAccountIndex":2,"GasFeeAssetId":0,"GasFeeAssetAmount":1,`+
+			`"ToAddress":"0x0000000000000000000000000000000000000001","ExpiredAt":%d,"Nonce":%d}`,
+			time.Now().Add(time.Hour).UnixMilli(), nonce)}
+	}
+	newChain := func(option SimulateOption) *BlockChain {
+		return NewBlockChainForSimulation(accountModel, nil, nil, &emptyMempoolModel{}, nil, &emptyCache{}, nil, option)
+	}
+
+	// the unsigned tx is rejected by the dry run of sendTx, the signed one is accepted.
+	_, err = NewBlockChainForDryRun(accountModel, nil, nil, &emptyMempoolModel{}, nil, &emptyCache{}, nil).
+		DryRunTransaction(newTx(3))
+	assert.Error(t, err)
+	signedTx := newTx(3)
+	txInfo, err := types.ParseWithdrawTxInfo(signedTx.TxInfo)
+	assert.NoError(t, err)
+	msgHash, err := legendTxTypes.ComputeWithdrawMsgHash(txInfo, mimc.NewMiMC())
+	assert.NoError(t, err)
+	txInfo.Sig, err = key.Sign(msgHash, mimc.NewMiMC())
+	assert.NoError(t, err)
+	txInfoBytes, err := json.Marshal(txInfo)
+	assert.NoError(t, err)
+	signedTx.TxInfo = string(txInfoBytes)
+	_, err = NewBlockChainForDryRun(accountModel, nil, nil, &emptyMempoolModel{}, nil, &emptyCache{}, nil).
+		DryRunTransaction(signedTx)
+	assert.NoError(t, err)
+
+	unsignedTx := newTx(3)
+	mempoolTx, err := newChain(SimulateOption{SkipSignature: true}).DryRunTransaction(unsignedTx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), mempoolTx.Nonce)
+	assert.NotEmpty(t, mempoolTx.TxHash)
+	assert.Len(t, unsignedTx.TxDetails, 3)
+
+	// the nonce is verified unless it is skipped.
+	_, err = newChain(SimulateOption{SkipSignature: true}).DryRunTransaction(newTx(5))
+	assert.NoError(t, err)
+	_, err = newChain(SimulateOption{SkipSignature: true}).DryRunTransaction(newTx(2))
+	assert.EqualError(t, err, "invalid Nonce")
+	_, err = newChain(SimulateOption{SkipSignature: true, SkipNonce: true}).DryRunTransaction(newTx(2))
+	assert.NoError(t, err)
+}
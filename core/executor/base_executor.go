@@ -40,7 +40,7 @@ func (e *BaseExecutor) VerifyInputs() error {
 			return err
 		}
 
-		err = txInfo.VerifySignature(e.bc.StateDB().AccountMap[from].PublicKey)
+		err = e.bc.VerifySignature(txInfo)
 		if err != nil {
 			return err
 		}
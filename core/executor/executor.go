@@ -3,6 +3,7 @@ package executor
 import (
 	"errors"
 
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
 	sdb "github.com/bnb-chain/zkbas/core/statedb"
 	"github.com/bnb-chain/zkbas/dao/block"
 	"github.com/bnb-chain/zkbas/dao/mempool"
@@ -13,6 +14,7 @@ import (
 type IBlockchain interface {
 	VerifyExpiredAt(expiredAt int64) error
 	VerifyNonce(accountIndex int64, nonce int64) error
+	VerifySignature(txInfo legendTxTypes.TxInfo) error
 	StateDB() *sdb.StateDB
 	DB() *sdb.ChainDB
 	CurrentBlock() *block.Block
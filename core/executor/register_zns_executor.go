@@ -130,12 +130,11 @@ func (e *RegisterZnsExecutor) UpdateTrees() error {
 	txInfo := e.txInfo
 	accounts := []int64{txInfo.AccountIndex}
 
-	emptyAssetTree, err := tree.NewEmptyAccountAssetTree(bc.StateDB().TreeCtx, txInfo.AccountIndex, uint64(bc.CurrentBlock().BlockHeight))
+	_, err := bc.StateDB().AccountAssetTrees.AddAccount(txInfo.AccountIndex)
 	if err != nil {
 		logx.Errorf("new empty account asset tree failed: %s", err.Error())
 		return err
 	}
-	bc.StateDB().AccountAssetTrees = append(bc.StateDB().AccountAssetTrees, emptyAssetTree)
 
 	return bc.StateDB().UpdateAccountTree(accounts, nil)
 }
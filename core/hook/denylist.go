@@ -0,0 +1,66 @@
+package hook
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Denylist rejects the txs involving the denied account indexes or L1 addresses.
+type Denylist struct {
+	accounts  map[int64]bool
+	addresses map[string]bool
+}
+
+// LoadDenylist reads the denylist file, which has an account index or an L1 address on each line.
+// Empty lines and lines starting with # are skipped.
+func LoadDenylist(file string) (*Denylist, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("open denylist failed: %v", err)
+	}
+	defer f.Close()
+
+	denylist := &Denylist{
+		accounts:  make(map[int64]bool),
+		addresses: make(map[string]bool),
+	}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if common.IsHexAddress(entry) {
+			denylist.addresses[strings.ToLower(entry)] = true
+			continue
+		}
+		accountIndex, err := strconv.ParseInt(entry, 10, 64)
+		if err != nil || accountIndex < 0 {
+			return nil, fmt.Errorf("invalid denylist entry at line %d: %s", line, entry)
+		}
+		denylist.accounts[accountIndex] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read denylist failed: %v", err)
+	}
+	return denylist, nil
+}
+
+func (d *Denylist) PreExecute(tx *Tx) error {
+	for _, accountIndex := range tx.AccountIndexes {
+		if d.accounts[accountIndex] {
+			return fmt.Errorf("account %d is denied", accountIndex)
+		}
+	}
+	for _, address := range tx.L1Addresses {
+		if d.addresses[strings.ToLower(address)] {
+			return fmt.Errorf("address %s is denied", address)
+		}
+	}
+	return nil
+}
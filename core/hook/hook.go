@@ -0,0 +1,184 @@
+package hook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/tx"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+type Config struct {
+	// the file of the denied account indexes and L1 addresses, one per line
+	DenylistFile string `json:",optional"`
+	Webhook      struct {
+		Url     string        `json:",optional"`
+		Timeout time.Duration `json:",default=1s"`
+		// accept the txs if the webhook is unavailable, otherwise they are kept pending until it is available
+		FailOpen bool `json:",optional"`
+	}
+}
+
+// Tx is the tx screened by the hooks, together with the accounts and L1 addresses involved.
+type Tx struct {
+	TxType         int64
+	TxInfo         string
+	AccountIndexes []int64
+	L1Addresses    []string
+}
+
+// Hook screens the L2 txs before their inputs are verified, a tx is rejected if an error is returned,
+// the error is the reason of the rejection, unless it is an UnavailableError. Hooks are called concurrently.
+type Hook interface {
+	PreExecute(tx *Tx) error
+}
+
+// RejectedError is returned for a tx rejected by a hook.
+type RejectedError struct {
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return "tx rejected: " + e.Reason
+}
+
+// UnavailableError is returned if a hook can't tell whether the tx is allowed, e.g. the webhook is down. The tx is
+// neither allowed nor rejected, it should be screened again later.
+type UnavailableError struct {
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	return "tx hook unavailable: " + e.Err.Error()
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// IsUnavailable reports whether the tx is not screened as a hook is unavailable.
+func IsUnavailable(err error) bool {
+	var unavailableErr *UnavailableError
+	return errors.As(err, &unavailableErr)
+}
+
+type hooks []Hook
+
+func (hs hooks) PreExecute(tx *Tx) error {
+	for _, h := range hs {
+		if err := h.PreExecute(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewHook returns the hooks configured, or nil if there is none.
+func NewHook(config *Config) (Hook, error) {
+	hs := make(hooks, 0)
+	if config.DenylistFile != "" {
+		denylist, err := LoadDenylist(config.DenylistFile)
+		if err != nil {
+			return nil, err
+		}
+		hs = append(hs, denylist)
+	}
+	if config.Webhook.Url != "" {
+		hs = append(hs, NewWebhook(config.Webhook.Url, config.Webhook.Timeout, config.Webhook.FailOpen))
+	}
+	if len(hs) == 0 {
+		return nil, nil
+	}
+	return hs, nil
+}
+
+// PreExecute screens the tx prepared in the state db with the hook. The priority operations from L1 are never
+// screened, they must be included to keep the exits censorship-resistant.
+func PreExecute(h Hook, statedb *sdb.StateDB, t *tx.Tx) error {
+	if h == nil || !types.IsL2Tx(t.TxType) {
+		return nil
+	}
+
+	hookTx, err := NewTx(statedb, t)
+	if err != nil {
+		return err
+	}
+	err = h.PreExecute(hookTx)
+	if err != nil {
+		if IsUnavailable(err) {
+			return err
+		}
+		return &RejectedError{Reason: err.Error()}
+	}
+	return nil
+}
+
+// NewTx collects the accounts involved in the tx from its tx info, which are the accounts of the fields named
+// *AccountIndex except the gas account, and the L1 addresses of these accounts together with the addresses
+// withdrawn to. The accounts should have been prepared in the state db.
+func NewTx(statedb *sdb.StateDB, t *tx.Tx) (*Tx, error) {
+	decoder := json.NewDecoder(bytes.NewBufferString(t.TxInfo))
+	decoder.UseNumber()
+	var txInfo map[string]interface{}
+	if err := decoder.Decode(&txInfo); err != nil {
+		return nil, fmt.Errorf("parse tx info failed: %v", err)
+	}
+
+	hookTx := &Tx{
+		TxType: t.TxType,
+		TxInfo: t.TxInfo,
+	}
+	accounts := make(map[int64]bool)
+	addresses := make(map[string]bool)
+	var collect func(fields map[string]interface{}) error
+	collect = func(fields map[string]interface{}) error {
+		for key, value := range fields {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				if err := collect(v); err != nil {
+					return err
+				}
+			case json.Number:
+				if !strings.HasSuffix(key, "AccountIndex") || key == "GasAccountIndex" {
+					continue
+				}
+				accountIndex, err := v.Int64()
+				if err != nil {
+					return fmt.Errorf("invalid %s: %v", key, err)
+				}
+				if !accounts[accountIndex] {
+					accounts[accountIndex] = true
+					hookTx.AccountIndexes = append(hookTx.AccountIndexes, accountIndex)
+				}
+			case string:
+				if key == "ToAddress" && !addresses[strings.ToLower(v)] {
+					addresses[strings.ToLower(v)] = true
+					hookTx.L1Addresses = append(hookTx.L1Addresses, v)
+				}
+			}
+		}
+		return nil
+	}
+	if err := collect(txInfo); err != nil {
+		return nil, err
+	}
+	sort.Slice(hookTx.AccountIndexes, func(i, j int) bool {
+		return hookTx.AccountIndexes[i] < hookTx.AccountIndexes[j]
+	})
+
+	for _, accountIndex := range hookTx.AccountIndexes {
+		account := statedb.AccountMap[accountIndex]
+		if account == nil || account.L1Address == "" || addresses[strings.ToLower(account.L1Address)] {
+			continue
+		}
+		addresses[strings.ToLower(account.L1Address)] = true
+		hookTx.L1Addresses = append(hookTx.L1Addresses, account.L1Address)
+	}
+	return hookTx, nil
+}
@@ -0,0 +1,124 @@
+package hook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/tx"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+const (
+	senderAddress   = "0x5C7a1C2e6E9c1a1dB9a2fB6bC04F62a4e7D5a1A1"
+	receiverAddress = "0x8b1b2f3D6e2A0c9C6d5e4f3a2b1c0d9e8f7a6b5C"
+)
+
+func newTestStateDB() *sdb.StateDB {
+	statedb := sdb.NewStateDBForDryRun(nil, nil)
+	statedb.AccountMap[1] = &types.AccountInfo{AccountIndex: 1, L1Address: senderAddress}
+	statedb.AccountMap[2] = &types.AccountInfo{AccountIndex: 2}
+	return statedb
+}
+
+func TestNewTx(t *testing.T) {
+	hookTx, err := NewTx(newTestStateDB(), &tx.Tx{
+		TxType: types.TxTypeWithdraw,
+		TxInfo: `{"FromAccountIndex":1,"GasAccountIndex":0,"ToAddress":"` + receiverAddress + `"}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, hookTx.AccountIndexes)
+	assert.Equal(t, []string{receiverAddress, senderAddress}, hookTx.L1Addresses)
+
+	hookTx, err = NewTx(newTestStateDB(), &tx.Tx{
+		TxType: types.TxTypeAtomicMatch,
+		TxInfo: `{"AccountIndex":3,"BuyOffer":{"AccountIndex":2},"SellOffer":{"AccountIndex":1},"GasAccountIndex":0}`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, hookTx.AccountIndexes)
+	assert.Equal(t, []string{senderAddress}, hookTx.L1Addresses)
+}
+
+func TestDenylist(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "denylist")
+	assert.NoError(t, os.WriteFile(file, []byte("# sanctioned\n2\n\n"+receiverAddress+"\n"), 0600))
+	denylist, err := LoadDenylist(file)
+	assert.NoError(t, err)
+
+	assert.NoError(t, denylist.PreExecute(&Tx{AccountIndexes: []int64{1}, L1Addresses: []string{senderAddress}}))
+	assert.EqualError(t, denylist.PreExecute(&Tx{AccountIndexes: []int64{1, 2}}), "account 2 is denied")
+	assert.Error(t, denylist.PreExecute(&Tx{AccountIndexes: []int64{1}, L1Addresses: []string{
+		"0x8B1B2F3D6E2A0C9C6D5E4F3A2B1C0D9E8F7A6B5C",
+	}}))
+
+	assert.NoError(t, os.WriteFile(file, []byte("alice\n"), 0600))
+	_, err = LoadDenylist(file)
+	assert.Error(t, err)
+}
+
+func TestWebhook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hookTx := &Tx{}
+		_ = json.NewDecoder(r.Body).Decode(hookTx)
+		resp := &WebhookResponse{Allowed: true}
+		for _, accountIndex := range hookTx.AccountIndexes {
+			if accountIndex == 2 {
+				resp = &WebhookResponse{Allowed: false, Reason: "account under review"}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, time.Second, false)
+	assert.NoError(t, webhook.PreExecute(&Tx{AccountIndexes: []int64{1}}))
+	assert.EqualError(t, webhook.PreExecute(&Tx{AccountIndexes: []int64{2}}), "account under review")
+
+	// The txs are not screened if the webhook is unavailable, unless it fails open.
+	err := NewWebhook("http://127.0.0.1:0", time.Second, false).PreExecute(&Tx{})
+	assert.True(t, IsUnavailable(err))
+	assert.NoError(t, NewWebhook("http://127.0.0.1:0", time.Second, true).PreExecute(&Tx{}))
+
+	config := &Config{}
+	config.Webhook.Url = "http://127.0.0.1:0"
+	config.Webhook.Timeout = time.Second
+	h, err := NewHook(config)
+	assert.NoError(t, err)
+	err = PreExecute(h, newTestStateDB(), &tx.Tx{
+		TxType: types.TxTypeWithdraw,
+		TxInfo: `{"FromAccountIndex":1,"GasAccountIndex":0,"ToAddress":"` + receiverAddress + `"}`,
+	})
+	assert.True(t, IsUnavailable(err))
+	_, rejected := err.(*RejectedError)
+	assert.False(t, rejected)
+}
+
+func TestPreExecute(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "denylist")
+	assert.NoError(t, os.WriteFile(file, []byte("1\n"), 0600))
+	h, err := NewHook(&Config{DenylistFile: file})
+	assert.NoError(t, err)
+
+	err = PreExecute(h, newTestStateDB(), &tx.Tx{
+		TxType: types.TxTypeTransfer,
+		TxInfo: `{"FromAccountIndex":1,"ToAccountIndex":2,"GasAccountIndex":0}`,
+	})
+	assert.Equal(t, &RejectedError{Reason: "account 1 is denied"}, err)
+
+	// Priority operations are always included.
+	assert.NoError(t, PreExecute(h, newTestStateDB(), &tx.Tx{
+		TxType: types.TxTypeFullExit,
+		TxInfo: `{"AccountIndex":1}`,
+	}))
+
+	h, err = NewHook(&Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, h)
+}
@@ -0,0 +1,72 @@
+package hook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// WebhookResponse is the response of the webhook to a tx posted to it in json.
+type WebhookResponse struct {
+	Allowed bool
+	Reason  string
+}
+
+// Webhook asks a local http service whether a tx is allowed.
+type Webhook struct {
+	url      string
+	client   *http.Client
+	failOpen bool
+}
+
+func NewWebhook(url string, timeout time.Duration, failOpen bool) *Webhook {
+	return &Webhook{
+		url:      url,
+		client:   &http.Client{Timeout: timeout},
+		failOpen: failOpen,
+	}
+}
+
+func (w *Webhook) PreExecute(tx *Tx) error {
+	resp, err := w.call(tx)
+	if err != nil {
+		logx.Errorf("call tx webhook failed: %v", err)
+		if w.failOpen {
+			return nil
+		}
+		return &UnavailableError{Err: fmt.Errorf("call webhook failed: %v", err)}
+	}
+	if !resp.Allowed {
+		if resp.Reason == "" {
+			return errors.New("denied by webhook")
+		}
+		return errors.New(resp.Reason)
+	}
+	return nil
+}
+
+func (w *Webhook) call(tx *Tx) (*WebhookResponse, error) {
+	body, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+	httpResp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", httpResp.Status)
+	}
+
+	resp := &WebhookResponse{}
+	if err := json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return nil, fmt.Errorf("decode response failed: %v", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
+	"github.com/bnb-chain/zkbas/core/executor"
+	"github.com/bnb-chain/zkbas/core/hook"
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/tx"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// speculativeChain is the blockchain seen by a tx executed speculatively, the states are read from a view forked
+// from the state db of the chain. Once the view is merged, the statedb is switched to the state db of the chain
+// so that the executor applies the tx to it.
+type speculativeChain struct {
+	bc      *BlockChain
+	statedb *sdb.StateDB
+}
+
+func (c *speculativeChain) VerifyExpiredAt(expiredAt int64) error {
+	return c.bc.VerifyExpiredAt(expiredAt)
+}
+
+func (c *speculativeChain) VerifyNonce(accountIndex int64, nonce int64) error {
+	expectNonce, err := c.statedb.GetCommittedNonce(accountIndex)
+	if err != nil {
+		return err
+	}
+	if nonce != expectNonce {
+		return errors.New("invalid Nonce")
+	}
+	return nil
+}
+
+func (c *speculativeChain) VerifySignature(txInfo legendTxTypes.TxInfo) error {
+	return txInfo.VerifySignature(c.statedb.AccountMap[txInfo.GetFromAccountIndex()].PublicKey)
+}
+
+func (c *speculativeChain) StateDB() *sdb.StateDB {
+	return c.statedb
+}
+
+func (c *speculativeChain) DB() *sdb.ChainDB {
+	return c.bc.ChainDB
+}
+
+func (c *speculativeChain) CurrentBlock() *block.Block {
+	return c.bc.currentBlock
+}
+
+// speculation is a tx prepared and verified against a view of the state.
+type speculation struct {
+	chain    *speculativeChain
+	executor executor.TxExecutor
+	err      error
+}
+
+func speculate(bc *BlockChain, tx *tx.Tx) *speculation {
+	s := &speculation{
+		chain: &speculativeChain{bc: bc, statedb: bc.Statedb.Fork()},
+	}
+
+	s.executor, s.err = executor.NewTxExecutor(s.chain, tx)
+	if s.err != nil {
+		s.err = fmt.Errorf("new tx executor failed")
+		return s
+	}
+	s.err = s.executor.Prepare()
+	if s.err != nil {
+		return s
+	}
+	s.err = hook.PreExecute(bc.hook, s.view(), tx)
+	if s.err != nil {
+		return s
+	}
+	s.err = s.executor.VerifyInputs()
+	return s
+}
+
+func (s *speculation) view() *sdb.StateDB {
+	return s.chain.statedb
+}
+
+// isSpeculative returns whether the tx can be executed speculatively. The priority operations and the txs
+// allocating new states like MintNft depend on the global state and are always executed in order.
+func isSpeculative(txType int64) bool {
+	return types.IsL2Tx(txType) && txType != types.TxTypeMintNft
+}
+
+// creditOnlyAccount returns the gas account of the tx if the account only receives the gas fee. Crediting an
+// account never changes the result of the verification of another tx, and credits of the same asset commute,
+// so the txs paying gas to the same account don't conflict with each other. types.NilTxAccountIndex is returned
+// if there is no such account.
+func creditOnlyAccount(tx *tx.Tx) int64 {
+	var txInfo struct {
+		GasAccountIndex int64
+	}
+	if err := json.Unmarshal([]byte(tx.TxInfo), &txInfo); err != nil {
+		return types.NilTxAccountIndex
+	}
+	if txInfo.GasAccountIndex == tx.AccountIndex {
+		return types.NilTxAccountIndex
+	}
+	if tx.TxType == types.TxTypeAtomicMatch {
+		matchInfo, err := types.ParseAtomicMatchTxInfo(tx.TxInfo)
+		if err != nil || txInfo.GasAccountIndex == matchInfo.BuyOffer.AccountIndex ||
+			txInfo.GasAccountIndex == matchInfo.SellOffer.AccountIndex {
+			return types.NilTxAccountIndex
+		}
+	}
+	return txInfo.GasAccountIndex
+}
+
+// stateKeys is the set of states written by the txs applied in a speculative run.
+type stateKeys struct {
+	accounts map[int64]bool
+	pairs    map[int64]bool
+	nfts     map[int64]bool
+}
+
+func newStateKeys() *stateKeys {
+	return &stateKeys{
+		accounts: make(map[int64]bool),
+		pairs:    make(map[int64]bool),
+		nfts:     make(map[int64]bool),
+	}
+}
+
+// conflicts returns whether any state loaded by the view is written.
+func (k *stateKeys) conflicts(view *sdb.StateDB) bool {
+	for accountIndex := range view.AccountMap {
+		if k.accounts[accountIndex] {
+			return true
+		}
+	}
+	for pairIndex := range view.LiquidityMap {
+		if k.pairs[pairIndex] {
+			return true
+		}
+	}
+	for nftIndex := range view.NftMap {
+		if k.nfts[nftIndex] {
+			return true
+		}
+	}
+	return false
+}
+
+// add adds the states loaded by the view of an applied tx, except the account which is only credited.
+func (k *stateKeys) add(view *sdb.StateDB, creditOnlyAccount int64) {
+	for accountIndex := range view.AccountMap {
+		if accountIndex != creditOnlyAccount {
+			k.accounts[accountIndex] = true
+		}
+	}
+	for pairIndex := range view.LiquidityMap {
+		k.pairs[pairIndex] = true
+	}
+	for nftIndex := range view.NftMap {
+		k.nfts[nftIndex] = true
+	}
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/liquidity"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/dao/tx"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+func newTestStateDB() *sdb.StateDB {
+	statedb := sdb.NewStateDBForDryRun(nil, nil)
+	for _, accountIndex := range []int64{1, 2, 3} {
+		statedb.AccountMap[accountIndex] = &types.AccountInfo{
+			AccountIndex: accountIndex,
+			AssetInfo: map[int64]*types.AccountAsset{
+				0: {AssetId: 0, Balance: big.NewInt(100), LpAmount: big.NewInt(0), OfferCanceledOrFinalized: big.NewInt(0)},
+			},
+		}
+	}
+	statedb.LiquidityMap[0] = &liquidity.Liquidity{PairIndex: 0, AssetA: "100"}
+	statedb.NftMap[0] = &nft.L2Nft{NftIndex: 0, OwnerAccountIndex: 1}
+	return statedb
+}
+
+func TestForkAndMerge(t *testing.T) {
+	statedb := newTestStateDB()
+	view := statedb.Fork()
+
+	// The states are copied from the parent, the changes are invisible to it.
+	assert.NoError(t, view.PrepareAccountsAndAssets([]int64{1}, []int64{0, 1}))
+	assert.NoError(t, view.PrepareLiquidity(0))
+	assert.NoError(t, view.PrepareNft(0))
+	view.AccountMap[1].AssetInfo[0].Balance = big.NewInt(50)
+	view.LiquidityMap[0].AssetA = "50"
+	view.NftMap[0].OwnerAccountIndex = 2
+	assert.Equal(t, int64(100), statedb.AccountMap[1].AssetInfo[0].Balance.Int64())
+	assert.Equal(t, "100", statedb.LiquidityMap[0].AssetA)
+	assert.Equal(t, int64(1), statedb.NftMap[0].OwnerAccountIndex)
+	assert.Len(t, view.AccountMap, 1)
+
+	// The loaded states are kept, the missing assets are added.
+	statedb.Merge(view)
+	assert.Equal(t, int64(100), statedb.AccountMap[1].AssetInfo[0].Balance.Int64())
+	assert.Equal(t, int64(0), statedb.AccountMap[1].AssetInfo[1].Balance.Int64())
+	assert.Equal(t, "100", statedb.LiquidityMap[0].AssetA)
+	assert.Equal(t, int64(1), statedb.NftMap[0].OwnerAccountIndex)
+}
+
+func TestStateKeysConflicts(t *testing.T) {
+	statedb := newTestStateDB()
+
+	first := statedb.Fork()
+	assert.NoError(t, first.PrepareAccountsAndAssets([]int64{1, 3}, []int64{0}))
+	second := statedb.Fork()
+	assert.NoError(t, second.PrepareAccountsAndAssets([]int64{2, 3}, []int64{0}))
+	third := statedb.Fork()
+	assert.NoError(t, third.PrepareAccountsAndAssets([]int64{1}, []int64{0}))
+
+	// Account 3 only receives the gas fee of the first tx.
+	written := newStateKeys()
+	written.add(first, 3)
+	assert.False(t, written.conflicts(second))
+	assert.True(t, written.conflicts(third))
+
+	written.add(second, types.NilTxAccountIndex)
+	view := statedb.Fork()
+	assert.NoError(t, view.PrepareAccountsAndAssets([]int64{3}, []int64{0}))
+	assert.True(t, written.conflicts(view))
+}
+
+func TestCreditOnlyAccount(t *testing.T) {
+	assert.Equal(t, int64(3), creditOnlyAccount(&tx.Tx{
+		TxType:       types.TxTypeTransfer,
+		AccountIndex: 1,
+		TxInfo:       `{"FromAccountIndex":1,"ToAccountIndex":2,"GasAccountIndex":3}`,
+	}))
+	assert.Equal(t, types.NilTxAccountIndex, creditOnlyAccount(&tx.Tx{
+		TxType:       types.TxTypeWithdraw,
+		AccountIndex: 3,
+		TxInfo:       `{"FromAccountIndex":3,"GasAccountIndex":3}`,
+	}))
+	assert.Equal(t, types.NilTxAccountIndex, creditOnlyAccount(&tx.Tx{
+		TxType:       types.TxTypeAtomicMatch,
+		AccountIndex: 1,
+		TxInfo:       `{"AccountIndex":1,"BuyOffer":{"AccountIndex":3},"SellOffer":{"AccountIndex":2},"GasAccountIndex":3}`,
+	}))
+}
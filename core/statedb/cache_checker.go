@@ -0,0 +1,196 @@
+package statedb
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/bnb-chain/zkbas/dao/account"
+	"github.com/bnb-chain/zkbas/dao/dbcache"
+	"github.com/bnb-chain/zkbas/dao/liquidity"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+const (
+	CacheReasonVersion    = "unsupported entry version"
+	CacheReasonNotCreated = "block is not created"
+	CacheReasonDiverged   = "state differs from the database"
+)
+
+// CacheMismatch is a state cached in redis which is inconsistent with the committer's view.
+type CacheMismatch struct {
+	Key         string `json:"key"`
+	BlockHeight int64  `json:"block_height"`
+	TxIndex     int64  `json:"tx_index"`
+	Reason      string `json:"reason"`
+}
+
+// CacheChecker compares the states cached in redis with the committer's view of them. The states of the committed
+// blocks are the ones in the database, so an entry written in a committed block must hold the same state as the
+// database, and an entry written in a block not created is left by a rolled back block. The entries written in the
+// proposing block can't be checked, as its states are only known by the committer.
+type CacheChecker struct {
+	chainDb    *ChainDB
+	redisCache dbcache.Cache
+}
+
+func NewCacheChecker(chainDb *ChainDB, redisCache dbcache.Cache) *CacheChecker {
+	return &CacheChecker{
+		chainDb:    chainDb,
+		redisCache: redisCache,
+	}
+}
+
+// Sample checks the entries of the accounts, liquidity and nfts at random indexes, at most samples of each. The
+// mismatches are passed to fn, the number of the entries checked is returned.
+func (c *CacheChecker) Sample(samples int, fn func(*CacheMismatch) error) (int, error) {
+	accounts, err := c.chainDb.AccountModel.GetAccountsTotalCount()
+	if err != nil && err != types.DbErrNotFound {
+		return 0, err
+	}
+	liquidityList, err := c.chainDb.LiquidityModel.GetAllLiquidityAssets()
+	if err != nil && err != types.DbErrNotFound {
+		return 0, err
+	}
+	nfts := int64(0)
+	latestNftIndex, err := c.chainDb.L2NftModel.GetLatestNftIndex()
+	if err == nil {
+		nfts = latestNftIndex + 1
+	} else if err != types.DbErrNotFound {
+		return 0, err
+	}
+
+	checked := 0
+	for _, s := range []struct {
+		count int64
+		check func(int64) (*CacheMismatch, error)
+	}{
+		{accounts, c.CheckAccount},
+		{int64(len(liquidityList)), c.CheckLiquidity},
+		{nfts, c.CheckNft},
+	} {
+		for i := 0; i < samples && s.count > 0; i++ {
+			mismatch, err := s.check(rand.Int63n(s.count))
+			if err != nil {
+				return checked, err
+			}
+			checked++
+			if mismatch == nil {
+				continue
+			}
+			err = fn(mismatch)
+			if err != nil {
+				return checked, err
+			}
+		}
+	}
+	return checked, nil
+}
+
+func (c *CacheChecker) CheckAccount(accountIndex int64) (*CacheMismatch, error) {
+	cached := &account.Account{}
+	return c.check(dbcache.AccountKeyByIndex(accountIndex), cached, func() (bool, error) {
+		stored, err := c.chainDb.AccountModel.GetAccountByIndex(accountIndex)
+		if err == types.DbErrNotFound {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return cached.AccountIndex == stored.AccountIndex &&
+			cached.AccountName == stored.AccountName &&
+			cached.PublicKey == stored.PublicKey &&
+			cached.Nonce == stored.Nonce &&
+			cached.CollectionNonce == stored.CollectionNonce &&
+			cached.AssetInfo == stored.AssetInfo &&
+			cached.AssetRoot == stored.AssetRoot, nil
+	})
+}
+
+func (c *CacheChecker) CheckLiquidity(pairIndex int64) (*CacheMismatch, error) {
+	cached := &liquidity.Liquidity{}
+	return c.check(dbcache.LiquidityKeyByIndex(pairIndex), cached, func() (bool, error) {
+		stored, err := c.chainDb.LiquidityModel.GetLiquidityByPairIndex(pairIndex)
+		if err == types.DbErrNotFound {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return cached.PairIndex == stored.PairIndex &&
+			cached.AssetAId == stored.AssetAId &&
+			cached.AssetA == stored.AssetA &&
+			cached.AssetBId == stored.AssetBId &&
+			cached.AssetB == stored.AssetB &&
+			cached.LpAmount == stored.LpAmount &&
+			cached.KLast == stored.KLast &&
+			cached.FeeRate == stored.FeeRate &&
+			cached.TreasuryAccountIndex == stored.TreasuryAccountIndex &&
+			cached.TreasuryRate == stored.TreasuryRate, nil
+	})
+}
+
+func (c *CacheChecker) CheckNft(nftIndex int64) (*CacheMismatch, error) {
+	cached := &nft.L2Nft{}
+	return c.check(dbcache.NftKeyByIndex(nftIndex), cached, func() (bool, error) {
+		stored, err := c.chainDb.L2NftModel.GetNftAsset(nftIndex)
+		if err == types.DbErrNotFound {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return cached.NftIndex == stored.NftIndex &&
+			cached.CreatorAccountIndex == stored.CreatorAccountIndex &&
+			cached.OwnerAccountIndex == stored.OwnerAccountIndex &&
+			cached.NftContentHash == stored.NftContentHash &&
+			cached.NftL1Address == stored.NftL1Address &&
+			cached.NftL1TokenId == stored.NftL1TokenId &&
+			cached.CreatorTreasuryRate == stored.CreatorTreasuryRate &&
+			cached.CollectionId == stored.CollectionId, nil
+	})
+}
+
+// check checks the entry of the key, the state is decoded into the value and compared with the database by match.
+// The entry is skipped if a block is committed during the check, the database may hold the newer state.
+func (c *CacheChecker) check(key string, value interface{}, match func() (bool, error)) (*CacheMismatch, error) {
+	committedHeight, currentHeight, err := CommittedHeight(c.chainDb)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := dbcache.GetEntry(context.Background(), c.redisCache, key, value)
+	if err == dbcache.ErrEntryVersion {
+		return &CacheMismatch{Key: key, Reason: CacheReasonVersion}, nil
+	}
+	if dbcache.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	mismatch := &CacheMismatch{Key: key, BlockHeight: entry.BlockHeight, TxIndex: entry.TxIndex}
+	if entry.BlockHeight > currentHeight {
+		mismatch.Reason = CacheReasonNotCreated
+		return mismatch, nil
+	}
+	if entry.BlockHeight > committedHeight {
+		return nil, nil
+	}
+
+	matched, err := match()
+	if err != nil {
+		return nil, err
+	}
+	if matched {
+		return nil, nil
+	}
+	latestCommittedHeight, _, err := CommittedHeight(c.chainDb)
+	if err != nil {
+		return nil, err
+	}
+	if latestCommittedHeight != committedHeight {
+		return nil, nil
+	}
+	mismatch.Reason = CacheReasonDiverged
+	return mismatch, nil
+}
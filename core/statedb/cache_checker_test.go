@@ -0,0 +1,170 @@
+package statedb
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/bnb-chain/zkbas/common/chain"
+	"github.com/bnb-chain/zkbas/dao/account"
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/dbcache"
+	"github.com/bnb-chain/zkbas/dao/liquidity"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/dao/tx"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// testCache encodes the values as the redis cache does.
+type testCache struct {
+	dbcache.Cache
+	values map[string][]byte
+}
+
+func (c *testCache) Get(_ context.Context, key string, value interface{}) (interface{}, error) {
+	buf, ok := c.values[key]
+	if !ok {
+		return nil, errors.New("redis: nil")
+	}
+	return value, msgpack.Unmarshal(buf, value)
+}
+
+func (c *testCache) Set(_ context.Context, key string, value interface{}) error {
+	buf, err := msgpack.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.values[key] = buf
+	return nil
+}
+
+type testBlockModel struct {
+	block.BlockModel
+	height int64
+	status int64
+}
+
+func (m *testBlockModel) GetCurrentHeight() (int64, error) {
+	return m.height, nil
+}
+
+func (m *testBlockModel) GetBlockByHeightWithoutTx(height int64) (*block.Block, error) {
+	return &block.Block{BlockHeight: height, BlockStatus: m.status}, nil
+}
+
+type testAccountModel struct {
+	account.AccountModel
+	accounts map[int64]*account.Account
+}
+
+func (m *testAccountModel) GetAccountByIndex(accountIndex int64) (*account.Account, error) {
+	if a, ok := m.accounts[accountIndex]; ok {
+		return a, nil
+	}
+	return nil, types.DbErrNotFound
+}
+
+func (m *testAccountModel) GetAccountsTotalCount() (int64, error) {
+	return int64(len(m.accounts)), nil
+}
+
+type testLiquidityModel struct {
+	liquidity.LiquidityModel
+}
+
+func (m *testLiquidityModel) GetAllLiquidityAssets() ([]*liquidity.Liquidity, error) {
+	return nil, types.DbErrNotFound
+}
+
+type testNftModel struct {
+	nft.L2NftModel
+}
+
+func (m *testNftModel) GetLatestNftIndex() (int64, error) {
+	return -1, types.DbErrNotFound
+}
+
+func TestCacheChecker(t *testing.T) {
+	blockModel := &testBlockModel{height: 5, status: block.StatusProposing}
+	accountModel := &testAccountModel{accounts: make(map[int64]*account.Account)}
+	chainDb := &ChainDB{
+		BlockModel:     blockModel,
+		AccountModel:   accountModel,
+		LiquidityModel: &testLiquidityModel{},
+		L2NftModel:     &testNftModel{},
+	}
+	cache := &testCache{values: make(map[string][]byte)}
+	statedb := NewStateDBForDryRun(cache, chainDb)
+
+	// the accounts 0 and 1 are updated by the second tx of the block 4.
+	for i := int64(0); i < 2; i++ {
+		statedb.AccountMap[i] = &types.AccountInfo{
+			AccountIndex: i,
+			AccountName:  "account",
+			Nonce:        1,
+			AssetInfo: map[int64]*types.AccountAsset{
+				0: {AssetId: 0, Balance: big.NewInt(100), LpAmount: big.NewInt(0), OfferCanceledOrFinalized: big.NewInt(0)},
+			},
+		}
+		statedb.PendingUpdateAccountIndexMap[i] = StateCachePending
+		accountInfo, err := chain.FromFormatAccountInfo(statedb.AccountMap[i])
+		assert.NoError(t, err)
+		accountModel.accounts[i] = accountInfo
+	}
+	statedb.Txs = []*tx.Tx{{}, {}}
+	assert.NoError(t, statedb.SyncStateCacheToRedis(4))
+	assert.Equal(t, StateCacheCached, statedb.PendingUpdateAccountIndexMap[0])
+
+	cached := &account.Account{}
+	entry, err := dbcache.GetEntry(context.Background(), cache, dbcache.AccountKeyByIndex(0), cached)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), entry.BlockHeight)
+	assert.Equal(t, int64(1), entry.TxIndex)
+	assert.Equal(t, int64(1), cached.Nonce)
+
+	checker := NewCacheChecker(chainDb, cache)
+	checked, err := checker.Sample(10, func(mismatch *CacheMismatch) error {
+		t.Errorf("unexpected mismatch: %+v", mismatch)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, checked)
+
+	// the account 1 is updated in the committed block without the cache being synced.
+	accountModel.accounts[1].Nonce = 2
+	mismatch, err := checker.CheckAccount(1)
+	assert.NoError(t, err)
+	assert.Equal(t, &CacheMismatch{Key: dbcache.AccountKeyByIndex(1), BlockHeight: 4, TxIndex: 1,
+		Reason: CacheReasonDiverged}, mismatch)
+
+	// the entry written in the proposing block can't be checked.
+	statedb.PendingUpdateAccountIndexMap[1] = StateCachePending
+	statedb.AccountMap[1].Nonce = 3
+	assert.NoError(t, statedb.SyncStateCacheToRedis(5))
+	mismatch, err = checker.CheckAccount(1)
+	assert.NoError(t, err)
+	assert.Nil(t, mismatch)
+
+	// the proposing block is rolled back.
+	blockModel.height, blockModel.status = 4, block.StatusPending
+	mismatch, err = checker.CheckAccount(1)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheReasonNotCreated, mismatch.Reason)
+
+	// the entries of the former format are not trusted.
+	assert.NoError(t, cache.Set(context.Background(), dbcache.AccountKeyByIndex(0), accountModel.accounts[0]))
+	_, err = dbcache.GetEntry(context.Background(), cache, dbcache.AccountKeyByIndex(0), &account.Account{})
+	assert.Equal(t, dbcache.ErrEntryVersion, err)
+	mismatch, err = checker.CheckAccount(0)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheReasonVersion, mismatch.Reason)
+
+	// the missing entries are read from the database.
+	mismatch, err = checker.CheckAccount(2)
+	assert.NoError(t, err)
+	assert.Nil(t, mismatch)
+}
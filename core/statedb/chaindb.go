@@ -17,6 +17,7 @@ type ChainDB struct {
 	BlockModel    block.BlockModel
 	TxModel       tx.TxModel
 	TxDetailModel tx.TxDetailModel
+	FailTxModel   tx.FailTxModel
 
 	// State DB
 	AccountModel          account.AccountModel
@@ -34,6 +35,7 @@ func NewChainDB(db *gorm.DB) *ChainDB {
 		BlockModel:    block.NewBlockModel(db),
 		TxModel:       tx.NewTxModel(db),
 		TxDetailModel: tx.NewTxDetailModel(db),
+		FailTxModel:   tx.NewFailTxModel(db),
 
 		AccountModel:          account.NewAccountModel(db),
 		AccountHistoryModel:   account.NewAccountHistoryModel(db),
@@ -45,3 +47,20 @@ func NewChainDB(db *gorm.DB) *ChainDB {
 		MempoolModel:          mempool.NewMempoolModel(db),
 	}
 }
+
+// CommittedHeight returns the height of the last committed block and the current block, the states of the proposing
+// block are not written into the database yet.
+func CommittedHeight(chainDb *ChainDB) (committedHeight int64, currentHeight int64, err error) {
+	currentHeight, err = chainDb.BlockModel.GetCurrentHeight()
+	if err != nil {
+		return 0, 0, err
+	}
+	b, err := chainDb.BlockModel.GetBlockByHeightWithoutTx(currentHeight)
+	if err != nil {
+		return 0, 0, err
+	}
+	if b.BlockStatus == block.StatusProposing {
+		return currentHeight - 1, currentHeight, nil
+	}
+	return currentHeight, currentHeight, nil
+}
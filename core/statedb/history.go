@@ -0,0 +1,203 @@
+package statedb
+
+import (
+	"fmt"
+
+	"github.com/bnb-chain/zkbas/common/chain"
+	"github.com/bnb-chain/zkbas/dao/account"
+	"github.com/bnb-chain/zkbas/dao/liquidity"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// NewHistoryStateDB builds the state db at the block height in memory, the trees are reloaded from the history
+// tables and checked against the state root of the block, then the states are loaded with LoadHistoryState.
+// The name is the name of the tree context.
+func NewHistoryStateDB(chainDb *ChainDB, name string, height int64, batchSize int) (*StateDB, error) {
+	b, err := chainDb.BlockModel.GetBlockByHeightWithoutTx(height)
+	if err != nil {
+		return nil, fmt.Errorf("get block %d failed: %v", height, err)
+	}
+
+	treeCtx := &tree.Context{
+		Name:   name,
+		Driver: tree.MemoryDB,
+		Reload: true,
+	}
+	treeCtx.SetBatchReloadSize(batchSize)
+	statedb, err := NewStateDB(treeCtx, chainDb, nil, b.StateRoot, height)
+	if err != nil {
+		return nil, err
+	}
+	if stateRoot := statedb.GetStateRoot(); stateRoot != b.StateRoot {
+		return nil, fmt.Errorf("state root of block %d is %s, but %s is loaded from the history", height,
+			b.StateRoot, stateRoot)
+	}
+	err = statedb.LoadHistoryState(height, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("load state at block %d failed: %v", height, err)
+	}
+	return statedb, nil
+}
+
+// LoadHistoryState loads the accounts, liquidity and nfts at the block height from the history tables into the
+// state db, it is used together with the trees reloaded at the same height.
+func (s *StateDB) LoadHistoryState(height int64, batchSize int) error {
+	err := ForEachHistoryAccount(s.chainDb, height, batchSize, func(accountInfo *types.AccountInfo) error {
+		s.AccountMap[accountInfo.AccountIndex] = accountInfo
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = ForEachHistoryLiquidity(s.chainDb, height, batchSize, func(liquidityInfo *liquidity.Liquidity) error {
+		s.LiquidityMap[liquidityInfo.PairIndex] = liquidityInfo
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ForEachHistoryNft(s.chainDb, height, batchSize, func(nftInfo *nft.L2Nft) error {
+		s.NftMap[nftInfo.NftIndex] = nftInfo
+		return nil
+	})
+}
+
+// ForEachHistoryAccount calls fn with the accounts at the block height read from the history tables, in the order
+// of the account index.
+func ForEachHistoryAccount(chainDb *ChainDB, height int64, batchSize int, fn func(*types.AccountInfo) error) error {
+	for offset := 0; ; offset += batchSize {
+		_, accountHistories, err := chainDb.AccountHistoryModel.GetValidAccounts(height, batchSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, accountHistory := range accountHistories {
+			accountInfo, err := historyAccount(chainDb, accountHistory)
+			if err != nil {
+				return err
+			}
+			err = fn(accountInfo)
+			if err != nil {
+				return err
+			}
+		}
+		if len(accountHistories) < batchSize {
+			return nil
+		}
+	}
+}
+
+// ForEachHistoryLiquidity calls fn with the liquidity at the block height read from the history tables, in the
+// order of the pair index.
+func ForEachHistoryLiquidity(chainDb *ChainDB, height int64, batchSize int, fn func(*liquidity.Liquidity) error) error {
+	for offset := 0; ; offset += batchSize {
+		liquidityHistories, err := chainDb.LiquidityHistoryModel.GetLatestLiquidityByBlockHeight(height, batchSize, offset)
+		if err != nil && err != types.DbErrNotFound {
+			return err
+		}
+		for _, liquidityHistory := range liquidityHistories {
+			err = fn(historyLiquidity(liquidityHistory))
+			if err != nil {
+				return err
+			}
+		}
+		if len(liquidityHistories) < batchSize {
+			return nil
+		}
+	}
+}
+
+// ForEachHistoryNft calls fn with the nfts at the block height read from the history tables, in the order of the
+// nft index.
+func ForEachHistoryNft(chainDb *ChainDB, height int64, batchSize int, fn func(*nft.L2Nft) error) error {
+	for offset := 0; ; offset += batchSize {
+		_, nftHistories, err := chainDb.L2NftHistoryModel.GetLatestNftAssetsByBlockHeight(height, batchSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, nftHistory := range nftHistories {
+			err = fn(historyNft(nftHistory))
+			if err != nil {
+				return err
+			}
+		}
+		if len(nftHistories) < batchSize {
+			return nil
+		}
+	}
+}
+
+// GetHistoryAccount returns the account at the block height read from the history tables.
+func GetHistoryAccount(chainDb *ChainDB, accountIndex int64, height int64) (*types.AccountInfo, error) {
+	accountHistory, err := chainDb.AccountHistoryModel.GetLatestAccountHistory(accountIndex, height)
+	if err != nil {
+		return nil, err
+	}
+	return historyAccount(chainDb, accountHistory)
+}
+
+// GetHistoryLiquidity returns the liquidity at the block height read from the history tables.
+func GetHistoryLiquidity(chainDb *ChainDB, pairIndex int64, height int64) (*liquidity.Liquidity, error) {
+	liquidityHistory, err := chainDb.LiquidityHistoryModel.GetLatestLiquidityHistory(pairIndex, height)
+	if err != nil {
+		return nil, err
+	}
+	return historyLiquidity(liquidityHistory), nil
+}
+
+// GetHistoryNft returns the nft at the block height read from the history tables.
+func GetHistoryNft(chainDb *ChainDB, nftIndex int64, height int64) (*nft.L2Nft, error) {
+	nftHistory, err := chainDb.L2NftHistoryModel.GetLatestNftHistory(nftIndex, height)
+	if err != nil {
+		return nil, err
+	}
+	return historyNft(nftHistory), nil
+}
+
+func historyAccount(chainDb *ChainDB, accountHistory *account.AccountHistory) (*types.AccountInfo, error) {
+	accountInfo, err := chainDb.AccountModel.GetAccountByIndex(accountHistory.AccountIndex)
+	if err != nil {
+		return nil, err
+	}
+	accountInfo.Nonce = 0
+	if accountHistory.Nonce != types.NilNonce {
+		accountInfo.Nonce = accountHistory.Nonce
+	}
+	accountInfo.CollectionNonce = 0
+	if accountHistory.CollectionNonce != types.NilNonce {
+		accountInfo.CollectionNonce = accountHistory.CollectionNonce
+	}
+	accountInfo.AssetInfo = accountHistory.AssetInfo
+	accountInfo.AssetRoot = accountHistory.AssetRoot
+	accountInfo.Status = account.AccountStatusConfirmed
+	return chain.ToFormatAccountInfo(accountInfo)
+}
+
+func historyLiquidity(liquidityHistory *liquidity.LiquidityHistory) *liquidity.Liquidity {
+	return &liquidity.Liquidity{
+		PairIndex:            liquidityHistory.PairIndex,
+		AssetAId:             liquidityHistory.AssetAId,
+		AssetA:               liquidityHistory.AssetA,
+		AssetBId:             liquidityHistory.AssetBId,
+		AssetB:               liquidityHistory.AssetB,
+		LpAmount:             liquidityHistory.LpAmount,
+		KLast:                liquidityHistory.KLast,
+		FeeRate:              liquidityHistory.FeeRate,
+		TreasuryAccountIndex: liquidityHistory.TreasuryAccountIndex,
+		TreasuryRate:         liquidityHistory.TreasuryRate,
+	}
+}
+
+func historyNft(nftHistory *nft.L2NftHistory) *nft.L2Nft {
+	return &nft.L2Nft{
+		NftIndex:            nftHistory.NftIndex,
+		CreatorAccountIndex: nftHistory.CreatorAccountIndex,
+		OwnerAccountIndex:   nftHistory.OwnerAccountIndex,
+		NftContentHash:      nftHistory.NftContentHash,
+		NftL1Address:        nftHistory.NftL1Address,
+		NftL1TokenId:        nftHistory.NftL1TokenId,
+		CreatorTreasuryRate: nftHistory.CreatorTreasuryRate,
+		CollectionId:        nftHistory.CollectionId,
+	}
+}
@@ -0,0 +1,109 @@
+package statedb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	bsmt "github.com/bnb-chain/zkbas-smt"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// MerkleProof proves a leaf of a tree, the siblings are ordered from the leaf to the root.
+type MerkleProof struct {
+	Leaf     []byte
+	Siblings [][]byte
+}
+
+// GetAccountAssetProof returns the proof of the asset in the asset tree of the account, and the proof of the
+// account in the account tree. The leaves are hashed from the states loaded in the state db, they must be the ones
+// in the trees.
+func (s *StateDB) GetAccountAssetProof(accountIndex, assetId int64) (accountProof, assetProof *MerkleProof, err error) {
+	account, ok := s.AccountMap[accountIndex]
+	if !ok {
+		return nil, nil, types.DbErrNotFound
+	}
+	assetTree, err := s.AccountAssetTrees.Get(accountIndex)
+	if err == tree.ErrAssetTreeNotFound {
+		return nil, nil, types.DbErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	balance, lpAmount, offerCanceledOrFinalized := types.ZeroBigInt, types.ZeroBigInt, types.ZeroBigInt
+	if asset, ok := account.AssetInfo[assetId]; ok {
+		balance, lpAmount, offerCanceledOrFinalized = asset.Balance, asset.LpAmount, asset.OfferCanceledOrFinalized
+	}
+	assetLeaf, err := tree.ComputeAccountAssetLeafHash(balance.String(), lpAmount.String(), offerCanceledOrFinalized.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	assetProof, err = getProof(assetTree, assetId, assetLeaf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("asset %d of account %d: %v", assetId, accountIndex, err)
+	}
+
+	accountLeaf, err := tree.ComputeAccountLeafHash(account.AccountNameHash, account.PublicKey, account.Nonce,
+		account.CollectionNonce, assetTree.Root())
+	if err != nil {
+		return nil, nil, err
+	}
+	accountProof, err = getProof(s.AccountTree, accountIndex, accountLeaf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("account %d: %v", accountIndex, err)
+	}
+	return accountProof, assetProof, nil
+}
+
+// GetLiquidityProof returns the proof of the pair in the liquidity tree.
+func (s *StateDB) GetLiquidityProof(pairIndex int64) (*MerkleProof, error) {
+	liquidity, ok := s.LiquidityMap[pairIndex]
+	if !ok {
+		return nil, types.DbErrNotFound
+	}
+	leaf, err := tree.ComputeLiquidityAssetLeafHash(liquidity.AssetAId, liquidity.AssetA, liquidity.AssetBId,
+		liquidity.AssetB, liquidity.LpAmount, liquidity.KLast, liquidity.FeeRate, liquidity.TreasuryAccountIndex,
+		liquidity.TreasuryRate)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := getProof(s.LiquidityTree, pairIndex, leaf)
+	if err != nil {
+		return nil, fmt.Errorf("pair %d: %v", pairIndex, err)
+	}
+	return proof, nil
+}
+
+// GetNftProof returns the proof of the nft in the nft tree.
+func (s *StateDB) GetNftProof(nftIndex int64) (*MerkleProof, error) {
+	nft, ok := s.NftMap[nftIndex]
+	if !ok {
+		return nil, types.DbErrNotFound
+	}
+	leaf, err := tree.ComputeNftAssetLeafHash(nft.CreatorAccountIndex, nft.OwnerAccountIndex, nft.NftContentHash,
+		nft.NftL1Address, nft.NftL1TokenId, nft.CreatorTreasuryRate, nft.CollectionId)
+	if err != nil {
+		return nil, err
+	}
+	proof, err := getProof(s.NftTree, nftIndex, leaf)
+	if err != nil {
+		return nil, fmt.Errorf("nft %d: %v", nftIndex, err)
+	}
+	return proof, nil
+}
+
+func getProof(smt bsmt.SparseMerkleTree, key int64, leaf []byte) (*MerkleProof, error) {
+	siblings, err := smt.GetProof(uint64(key))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(tree.ComputeMerkleRoot(uint64(key), leaf, siblings), smt.Root()) {
+		return nil, errors.New("leaf mismatches the tree")
+	}
+	return &MerkleProof{
+		Leaf:     leaf,
+		Siblings: siblings,
+	}, nil
+}
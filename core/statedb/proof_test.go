@@ -0,0 +1,115 @@
+package statedb
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas-crypto/hash/bn254/zmimc"
+	bsmt "github.com/bnb-chain/zkbas-smt"
+	"github.com/bnb-chain/zkbas-smt/database/memory"
+	"github.com/bnb-chain/zkbas/dao/liquidity"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+func newMemTree(t *testing.T, depth uint8, nilHash []byte) bsmt.SparseMerkleTree {
+	smt, err := bsmt.NewBASSparseMerkleTree(bsmt.NewHasher(zmimc.Hmimc), memory.NewMemoryDB(), depth, nilHash)
+	assert.NoError(t, err)
+	return smt
+}
+
+func newTestStateDB(t *testing.T) *StateDB {
+	statedb := NewStateDBForDryRun(nil, nil)
+	statedb.AccountTree = newMemTree(t, tree.AccountTreeHeight, tree.NilAccountNodeHash)
+	statedb.LiquidityTree = newMemTree(t, tree.LiquidityTreeHeight, tree.NilLiquidityNodeHash)
+	statedb.NftTree = newMemTree(t, tree.NftTreeHeight, tree.NilNftNodeHash)
+	assetTrees, err := tree.NewAssetTreeCache(&tree.Context{Driver: tree.MemoryDB}, 0, 0)
+	assert.NoError(t, err)
+	for i := int64(0); i < 2; i++ {
+		_, err = assetTrees.AddAccount(i)
+		assert.NoError(t, err)
+	}
+	statedb.AccountAssetTrees = assetTrees
+	assetTree, err := assetTrees.GetForUpdate(1)
+	assert.NoError(t, err)
+
+	statedb.AccountMap[1] = &types.AccountInfo{
+		AccountIndex:    1,
+		AccountNameHash: "0x04b2ea4f6f9a6b6a8b6c7e2e2e24bbf0c7f2e1a1d2c3b4a5968778695a4b3c2d",
+		PublicKey:       "58130e24cd20d9de8a110a20751f0a9b36089400ac0f20ca1993c28ee663318a",
+		Nonce:           3,
+		AssetInfo: map[int64]*types.AccountAsset{
+			2: {AssetId: 2, Balance: big.NewInt(100), LpAmount: big.NewInt(0), OfferCanceledOrFinalized: big.NewInt(0)},
+		},
+	}
+	assetLeaf, err := tree.ComputeAccountAssetLeafHash("100", "0", "0")
+	assert.NoError(t, err)
+	assert.NoError(t, assetTree.Set(2, assetLeaf))
+	account := statedb.AccountMap[1]
+	accountLeaf, err := tree.ComputeAccountLeafHash(account.AccountNameHash, account.PublicKey, account.Nonce,
+		account.CollectionNonce, assetTree.Root())
+	assert.NoError(t, err)
+	assert.NoError(t, statedb.AccountTree.Set(1, accountLeaf))
+
+	statedb.LiquidityMap[0] = &liquidity.Liquidity{
+		PairIndex: 0, AssetAId: 0, AssetA: "1000", AssetBId: 2, AssetB: "2000", LpAmount: "1414", KLast: "2000000",
+		FeeRate: 30, TreasuryAccountIndex: 0, TreasuryRate: 5,
+	}
+	liquidityLeaf, err := tree.ComputeLiquidityAssetLeafHash(0, "1000", 2, "2000", "1414", "2000000", 30, 0, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, statedb.LiquidityTree.Set(0, liquidityLeaf))
+
+	statedb.NftMap[3] = &nft.L2Nft{
+		NftIndex: 3, CreatorAccountIndex: 1, OwnerAccountIndex: 1, NftContentHash: "0x01",
+		NftL1Address: "0", NftL1TokenId: "0", CreatorTreasuryRate: 10,
+	}
+	nftLeaf, err := tree.ComputeNftAssetLeafHash(1, 1, "0x01", "0", "0", 10, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, statedb.NftTree.Set(3, nftLeaf))
+	return statedb
+}
+
+func TestGetAccountAssetProof(t *testing.T) {
+	statedb := newTestStateDB(t)
+	assetTree, err := statedb.AccountAssetTrees.Get(1)
+	assert.NoError(t, err)
+
+	accountProof, assetProof, err := statedb.GetAccountAssetProof(1, 2)
+	assert.NoError(t, err)
+	assert.Len(t, assetProof.Siblings, tree.AssetTreeHeight)
+	assert.Equal(t, assetTree.Root(), tree.ComputeMerkleRoot(2, assetProof.Leaf, assetProof.Siblings))
+	assert.Len(t, accountProof.Siblings, tree.AccountTreeHeight)
+	assert.Equal(t, statedb.AccountTree.Root(), tree.ComputeMerkleRoot(1, accountProof.Leaf, accountProof.Siblings))
+
+	// The asset never held is proved with the leaf of the empty asset.
+	_, assetProof, err = statedb.GetAccountAssetProof(1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, tree.NilAccountAssetNodeHash, assetProof.Leaf)
+	assert.Equal(t, assetTree.Root(), tree.ComputeMerkleRoot(5, assetProof.Leaf, assetProof.Siblings))
+
+	_, _, err = statedb.GetAccountAssetProof(0, 2)
+	assert.Equal(t, types.DbErrNotFound, err)
+
+	// The states loaded must match the trees.
+	statedb.AccountMap[1].Nonce = 4
+	_, _, err = statedb.GetAccountAssetProof(1, 2)
+	assert.Error(t, err)
+}
+
+func TestGetLiquidityAndNftProof(t *testing.T) {
+	statedb := newTestStateDB(t)
+
+	proof, err := statedb.GetLiquidityProof(0)
+	assert.NoError(t, err)
+	assert.Equal(t, statedb.LiquidityTree.Root(), tree.ComputeMerkleRoot(0, proof.Leaf, proof.Siblings))
+
+	proof, err = statedb.GetNftProof(3)
+	assert.NoError(t, err)
+	assert.Equal(t, statedb.NftTree.Root(), tree.ComputeMerkleRoot(3, proof.Leaf, proof.Siblings))
+
+	_, err = statedb.GetNftProof(4)
+	assert.Equal(t, types.DbErrNotFound, err)
+}
@@ -3,6 +3,7 @@ package statedb
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
 	"github.com/ethereum/go-ethereum/common"
@@ -20,10 +21,16 @@ import (
 
 type StateDB struct {
 	dryRun bool
+	// parent is set for a view forked for speculative execution, the states are copied from it on demand
+	parent *StateDB
 	// State cache
 	*StateCache
 	chainDb    *ChainDB
 	redisCache dbcache.Cache
+	// the height of the current block read once by the dry run, the entries cached above it are stale
+	currentHeightOnce sync.Once
+	currentHeight     int64
+	currentHeightErr  error
 
 	// Flat state
 	AccountMap   map[int64]*types.AccountInfo
@@ -34,7 +41,7 @@ type StateDB struct {
 	AccountTree       bsmt.SparseMerkleTree
 	LiquidityTree     bsmt.SparseMerkleTree
 	NftTree           bsmt.SparseMerkleTree
-	AccountAssetTrees []bsmt.SparseMerkleTree
+	AccountAssetTrees *tree.AssetTreeCache
 	TreeCtx           *tree.Context
 }
 
@@ -100,6 +107,58 @@ func NewStateDBForDryRun(redisCache dbcache.Cache, chainDb *ChainDB) *StateDB {
 	}
 }
 
+// Fork returns an isolated view of the state db for executing a tx speculatively. The states loaded by the view
+// are copied from the state db or read from the database, so the changes made to the view are invisible to the
+// state db until they are merged. The trees are shared and must not be updated through the view.
+func (s *StateDB) Fork() *StateDB {
+	return &StateDB{
+		dryRun:       s.dryRun,
+		parent:       s,
+		StateCache:   NewStateCache(s.StateRoot),
+		chainDb:      s.chainDb,
+		redisCache:   s.redisCache,
+		AccountMap:   make(map[int64]*types.AccountInfo),
+		LiquidityMap: make(map[int64]*liquidity.Liquidity),
+		NftMap:       make(map[int64]*nft.L2Nft),
+
+		AccountTree:       s.AccountTree,
+		LiquidityTree:     s.LiquidityTree,
+		NftTree:           s.NftTree,
+		AccountAssetTrees: s.AccountAssetTrees,
+		TreeCtx:           s.TreeCtx,
+	}
+}
+
+// Merge moves the states loaded by a view forked from the state db into it. The states already loaded by the
+// state db are kept, only the assets missing in its accounts are added.
+func (s *StateDB) Merge(view *StateDB) {
+	for accountIndex, accountInfo := range view.AccountMap {
+		current, ok := s.AccountMap[accountIndex]
+		if !ok {
+			s.AccountMap[accountIndex] = accountInfo
+			continue
+		}
+		if current.AssetInfo == nil {
+			current.AssetInfo = make(map[int64]*types.AccountAsset)
+		}
+		for assetId, asset := range accountInfo.AssetInfo {
+			if current.AssetInfo[assetId] == nil {
+				current.AssetInfo[assetId] = asset
+			}
+		}
+	}
+	for pairIndex, liquidityInfo := range view.LiquidityMap {
+		if s.LiquidityMap[pairIndex] == nil {
+			s.LiquidityMap[pairIndex] = liquidityInfo
+		}
+	}
+	for nftIndex, nftInfo := range view.NftMap {
+		if s.NftMap[nftIndex] == nil {
+			s.NftMap[nftIndex] = nftInfo
+		}
+	}
+}
+
 func (s *StateDB) GetAccount(accountIndex int64) interface{} {
 	// to save account to cache, we need to convert it
 	account, err := chain.FromFormatAccountInfo(s.AccountMap[accountIndex])
@@ -117,15 +176,17 @@ func (s *StateDB) GetNft(nftIndex int64) interface{} {
 	return s.NftMap[nftIndex]
 }
 
-func (s *StateDB) syncPendingStateToRedis(pendingMap map[int64]int, getKey func(int64) string, getValue func(int64) interface{}) error {
+func (s *StateDB) syncPendingStateToRedis(blockHeight int64, pendingMap map[int64]int, getKey func(int64) string, getValue func(int64) interface{}) error {
+	// the states are the ones after the txs executed so far in the block.
+	txIndex := int64(len(s.Txs)) - 1
 	for index, status := range pendingMap {
 		if status != StateCachePending {
 			continue
 		}
 
-		err := s.redisCache.Set(context.Background(), getKey(index), getValue(index))
+		err := s.redisCache.Set(context.Background(), getKey(index), dbcache.NewEntry(blockHeight, txIndex, getValue(index)))
 		if err != nil {
-			return fmt.Errorf("cache to redis failed: %v", err)
+			return fmt.Errorf("cache to redis failed: %w", err)
 		}
 		pendingMap[index] = StateCacheCached
 	}
@@ -133,32 +194,34 @@ func (s *StateDB) syncPendingStateToRedis(pendingMap map[int64]int, getKey func(
 	return nil
 }
 
-func (s *StateDB) SyncStateCacheToRedis() error {
+// SyncStateCacheToRedis writes the pending states of the block into redis, the entries carry the position of the
+// states in the block.
+func (s *StateDB) SyncStateCacheToRedis(blockHeight int64) error {
 
 	// Sync new create to cache.
-	err := s.syncPendingStateToRedis(s.PendingNewAccountIndexMap, dbcache.AccountKeyByIndex, s.GetAccount)
+	err := s.syncPendingStateToRedis(blockHeight, s.PendingNewAccountIndexMap, dbcache.AccountKeyByIndex, s.GetAccount)
 	if err != nil {
 		return err
 	}
-	err = s.syncPendingStateToRedis(s.PendingNewLiquidityIndexMap, dbcache.LiquidityKeyByIndex, s.GetLiquidity)
+	err = s.syncPendingStateToRedis(blockHeight, s.PendingNewLiquidityIndexMap, dbcache.LiquidityKeyByIndex, s.GetLiquidity)
 	if err != nil {
 		return err
 	}
-	err = s.syncPendingStateToRedis(s.PendingNewNftIndexMap, dbcache.NftKeyByIndex, s.GetNft)
+	err = s.syncPendingStateToRedis(blockHeight, s.PendingNewNftIndexMap, dbcache.NftKeyByIndex, s.GetNft)
 	if err != nil {
 		return err
 	}
 
 	// Sync pending update to cache.
-	err = s.syncPendingStateToRedis(s.PendingUpdateAccountIndexMap, dbcache.AccountKeyByIndex, s.GetAccount)
+	err = s.syncPendingStateToRedis(blockHeight, s.PendingUpdateAccountIndexMap, dbcache.AccountKeyByIndex, s.GetAccount)
 	if err != nil {
 		return err
 	}
-	err = s.syncPendingStateToRedis(s.PendingUpdateLiquidityIndexMap, dbcache.LiquidityKeyByIndex, s.GetLiquidity)
+	err = s.syncPendingStateToRedis(blockHeight, s.PendingUpdateLiquidityIndexMap, dbcache.LiquidityKeyByIndex, s.GetLiquidity)
 	if err != nil {
 		return err
 	}
-	err = s.syncPendingStateToRedis(s.PendingUpdateNftIndexMap, dbcache.NftKeyByIndex, s.GetNft)
+	err = s.syncPendingStateToRedis(blockHeight, s.PendingUpdateNftIndexMap, dbcache.NftKeyByIndex, s.GetNft)
 	if err != nil {
 		return err
 	}
@@ -360,10 +423,19 @@ func (s *StateDB) DeepCopyAccounts(accountIds []int64) (map[int64]*types.Account
 
 func (s *StateDB) PrepareAccountsAndAssets(accounts []int64, assets []int64) error {
 	for _, accountIndex := range accounts {
-		if s.dryRun {
+		if s.parent != nil && s.AccountMap[accountIndex] == nil && s.parent.AccountMap[accountIndex] != nil {
+			accountCopy, err := s.parent.AccountMap[accountIndex].DeepCopy()
+			if err != nil {
+				return err
+			}
+			s.AccountMap[accountIndex] = accountCopy
+		}
+
+		// in dry run mode, the states changed by former txs are kept
+		if s.dryRun && s.AccountMap[accountIndex] == nil {
 			account := &account.Account{}
-			redisAccount, err := s.redisCache.Get(context.Background(), dbcache.AccountKeyByIndex(accountIndex), account)
-			if err == nil && redisAccount != nil {
+			err := s.getCachedEntry(dbcache.AccountKeyByIndex(accountIndex), account)
+			if err == nil {
 				formatAccount, err := chain.ToFormatAccountInfo(account)
 				if err == nil {
 					s.AccountMap[accountIndex] = formatAccount
@@ -400,10 +472,15 @@ func (s *StateDB) PrepareAccountsAndAssets(accounts []int64, assets []int64) err
 }
 
 func (s *StateDB) PrepareLiquidity(pairIndex int64) error {
-	if s.dryRun {
+	if s.parent != nil && s.LiquidityMap[pairIndex] == nil && s.parent.LiquidityMap[pairIndex] != nil {
+		liquidityCopy := *s.parent.LiquidityMap[pairIndex]
+		s.LiquidityMap[pairIndex] = &liquidityCopy
+	}
+
+	if s.dryRun && s.LiquidityMap[pairIndex] == nil {
 		l := &liquidity.Liquidity{}
-		redisLiquidity, err := s.redisCache.Get(context.Background(), dbcache.LiquidityKeyByIndex(pairIndex), l)
-		if err == nil && redisLiquidity != nil {
+		err := s.getCachedEntry(dbcache.LiquidityKeyByIndex(pairIndex), l)
+		if err == nil {
 			s.LiquidityMap[pairIndex] = l
 		}
 	}
@@ -419,10 +496,15 @@ func (s *StateDB) PrepareLiquidity(pairIndex int64) error {
 }
 
 func (s *StateDB) PrepareNft(nftIndex int64) error {
-	if s.dryRun {
+	if s.parent != nil && s.NftMap[nftIndex] == nil && s.parent.NftMap[nftIndex] != nil {
+		nftCopy := *s.parent.NftMap[nftIndex]
+		s.NftMap[nftIndex] = &nftCopy
+	}
+
+	if s.dryRun && s.NftMap[nftIndex] == nil {
 		n := &nft.L2Nft{}
-		redisNft, err := s.redisCache.Get(context.Background(), dbcache.NftKeyByIndex(nftIndex), n)
-		if err == nil && redisNft != nil {
+		err := s.getCachedEntry(dbcache.NftKeyByIndex(nftIndex), n)
+		if err == nil {
 			s.NftMap[nftIndex] = n
 		}
 	}
@@ -437,41 +519,82 @@ func (s *StateDB) PrepareNft(nftIndex int64) error {
 	return nil
 }
 
+// UpdateAccountTree updates the asset trees of the accounts and their leaves in the account tree. The asset trees are
+// independent of each other and have their own hashers, so they are updated concurrently, then the account leaves
+// are set in order as the account tree is not safe for concurrent use.
 func (s *StateDB) UpdateAccountTree(accounts []int64, assets []int64) error {
-	for _, accountIndex := range accounts {
-		for _, assetId := range assets {
-			assetLeaf, err := tree.ComputeAccountAssetLeafHash(
-				s.AccountMap[accountIndex].AssetInfo[assetId].Balance.String(),
-				s.AccountMap[accountIndex].AssetInfo[assetId].LpAmount.String(),
-				s.AccountMap[accountIndex].AssetInfo[assetId].OfferCanceledOrFinalized.String(),
-			)
-			if err != nil {
-				return fmt.Errorf("compute new account asset leaf failed: %v", err)
-			}
-			err = s.AccountAssetTrees[accountIndex].Set(uint64(assetId), assetLeaf)
-			if err != nil {
-				return fmt.Errorf("update asset tree failed: %v", err)
-			}
+	// the accounts of a tx may be the same one, e.g. the gas account.
+	accounts = distinct(accounts)
+	leaves := make([][]byte, len(accounts))
+	errs := make([]error, len(accounts))
+	var wg sync.WaitGroup
+	for i := range accounts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			leaves[i], errs[i] = s.updateAssetTree(accounts[i], assets)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, accountIndex := range accounts {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		err := s.AccountTree.Set(uint64(accountIndex), leaves[i])
+		if err != nil {
+			return fmt.Errorf("unable to update account tree: %v", err)
 		}
+	}
+	return nil
+}
 
-		s.AccountMap[accountIndex].AssetRoot = common.Bytes2Hex(s.AccountAssetTrees[accountIndex].Root())
-		nAccountLeafHash, err := tree.ComputeAccountLeafHash(
-			s.AccountMap[accountIndex].AccountNameHash,
-			s.AccountMap[accountIndex].PublicKey,
-			s.AccountMap[accountIndex].Nonce,
-			s.AccountMap[accountIndex].CollectionNonce,
-			s.AccountAssetTrees[accountIndex].Root(),
+// updateAssetTree updates the assets in the asset tree of the account and returns the new leaf of the account.
+func (s *StateDB) updateAssetTree(accountIndex int64, assets []int64) ([]byte, error) {
+	assetTree, err := s.AccountAssetTrees.GetForUpdate(accountIndex)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get asset tree of account %d: %v", accountIndex, err)
+	}
+	account := s.AccountMap[accountIndex]
+	for _, assetId := range assets {
+		assetLeaf, err := tree.ComputeAccountAssetLeafHash(
+			account.AssetInfo[assetId].Balance.String(),
+			account.AssetInfo[assetId].LpAmount.String(),
+			account.AssetInfo[assetId].OfferCanceledOrFinalized.String(),
 		)
 		if err != nil {
-			return fmt.Errorf("unable to compute account leaf: %v", err)
+			return nil, fmt.Errorf("compute new account asset leaf failed: %v", err)
 		}
-		err = s.AccountTree.Set(uint64(accountIndex), nAccountLeafHash)
+		err = assetTree.Set(uint64(assetId), assetLeaf)
 		if err != nil {
-			return fmt.Errorf("unable to update account tree: %v", err)
+			return nil, fmt.Errorf("update asset tree failed: %v", err)
 		}
 	}
 
-	return nil
+	account.AssetRoot = common.Bytes2Hex(assetTree.Root())
+	nAccountLeafHash, err := tree.ComputeAccountLeafHash(
+		account.AccountNameHash,
+		account.PublicKey,
+		account.Nonce,
+		account.CollectionNonce,
+		assetTree.Root(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute account leaf: %v", err)
+	}
+	return nAccountLeafHash, nil
+}
+
+func distinct(indexes []int64) []int64 {
+	result := make([]int64, 0, len(indexes))
+	seen := make(map[int64]bool, len(indexes))
+	for _, index := range indexes {
+		if !seen[index] {
+			seen[index] = true
+			result = append(result, index)
+		}
+	}
+	return result
 }
 
 func (s *StateDB) UpdateLiquidityTree(pairIndex int64) error {
@@ -534,14 +657,49 @@ func (s *StateDB) GetCommittedNonce(accountIndex int64) (int64, error) {
 	}
 }
 
+// GetPendingNonce returns the first nonce of the account not taken by the executed txs or the txs in the mempool. The
+// txs with higher nonces may be queued in the mempool already, waiting for the gaps to be filled.
 func (s *StateDB) GetPendingNonce(accountIndex int64) (int64, error) {
-	nonce, err := s.chainDb.MempoolModel.GetMaxNonceByAccountIndex(accountIndex)
-	if err == nil {
-		return nonce + 1, nil
+	nonce, err := s.getExecutedNonce(accountIndex)
+	if err != nil {
+		return 0, err
+	}
+	nonces, err := s.chainDb.MempoolModel.GetNoncesByAccountIndex(accountIndex, nonce)
+	if err != nil {
+		return 0, err
+	}
+	for _, taken := range nonces {
+		if taken != nonce {
+			break
+		}
+		nonce++
+	}
+	return nonce, nil
+}
+
+// getCachedEntry reads the state cached by the committer into the value. The height of the current block is read
+// once for the state db and its views, so a dry run reads it once however many states it reads.
+func (s *StateDB) getCachedEntry(key string, value interface{}) error {
+	_, err := dbcache.GetCurrentEntry(context.Background(), s.redisCache, s.getCurrentHeight, key, value)
+	return err
+}
+
+func (s *StateDB) getCurrentHeight() (int64, error) {
+	root := s
+	for root.parent != nil {
+		root = root.parent
 	}
+	root.currentHeightOnce.Do(func() {
+		root.currentHeight, root.currentHeightErr = root.chainDb.BlockModel.GetCurrentHeight()
+	})
+	return root.currentHeight, root.currentHeightErr
+}
+
+// getExecutedNonce returns the nonce of the account in the state cached by the committer, or in the database.
+func (s *StateDB) getExecutedNonce(accountIndex int64) (int64, error) {
 	account := &account.Account{}
-	redisAccount, err := s.redisCache.Get(context.Background(), dbcache.AccountKeyByIndex(accountIndex), account)
-	if err == nil && redisAccount != nil {
+	err := s.getCachedEntry(dbcache.AccountKeyByIndex(accountIndex), account)
+	if err == nil {
 		return account.Nonce, nil
 	}
 	dbAccount, err := s.chainDb.AccountModel.GetAccountByIndex(accountIndex)
@@ -552,7 +710,11 @@ func (s *StateDB) GetPendingNonce(accountIndex int64) (int64, error) {
 }
 
 func (s *StateDB) GetNextAccountIndex() int64 {
-	return int64(len(s.AccountAssetTrees))
+	// the state db for dry run has no trees.
+	if s.AccountAssetTrees == nil {
+		return 0
+	}
+	return s.AccountAssetTrees.GetNextAccountIndex()
 }
 
 func (s *StateDB) GetNextNftIndex() int64 {
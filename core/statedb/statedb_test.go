@@ -0,0 +1,80 @@
+package statedb
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/dao/account"
+	"github.com/bnb-chain/zkbas/dao/dbcache"
+	"github.com/bnb-chain/zkbas/dao/mempool"
+)
+
+type testMempoolModel struct {
+	mempool.MempoolModel
+	nonces []int64
+}
+
+func (m *testMempoolModel) GetNoncesByAccountIndex(_ int64, fromNonce int64) ([]int64, error) {
+	nonces := make([]int64, 0)
+	for _, nonce := range m.nonces {
+		if nonce >= fromNonce {
+			nonces = append(nonces, nonce)
+		}
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	return nonces, nil
+}
+
+func TestGetPendingNonce(t *testing.T) {
+	accountModel := &testAccountModel{accounts: map[int64]*account.Account{0: {AccountIndex: 0, Nonce: 3}}}
+	mempoolModel := &testMempoolModel{}
+	cache := &testCache{values: make(map[string][]byte)}
+	blockModel := &testBlockModel{height: 1}
+	statedb := NewStateDBForDryRun(cache, &ChainDB{AccountModel: accountModel, MempoolModel: mempoolModel,
+		BlockModel: blockModel})
+
+	nonce, err := statedb.GetPendingNonce(0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), nonce)
+
+	// the nonces taken by the txs in the mempool are skipped, the ones after a gap are queued.
+	mempoolModel.nonces = []int64{1, 3, 4, 6}
+	nonce, err = statedb.GetPendingNonce(0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), nonce)
+
+	// the state cached by the committer is newer than the database.
+	assert.NoError(t, cache.Set(context.Background(), dbcache.AccountKeyByIndex(0),
+		dbcache.NewEntry(1, 0, &account.Account{AccountIndex: 0, Nonce: 5})))
+	nonce, err = statedb.GetPendingNonce(0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), nonce)
+	mempoolModel.nonces = append(mempoolModel.nonces, 5)
+	nonce, err = statedb.GetPendingNonce(0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), nonce)
+
+	// the state cached for a block rolled back is stale.
+	assert.NoError(t, cache.Set(context.Background(), dbcache.AccountKeyByIndex(0),
+		dbcache.NewEntry(2, 0, &account.Account{AccountIndex: 0, Nonce: 8})))
+	nonce, err = statedb.GetPendingNonce(0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), nonce)
+
+	// the height is read once by a dry run, the next one sees the new block.
+	blockModel.height = 2
+	nonce, err = statedb.GetPendingNonce(0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), nonce)
+	statedb = NewStateDBForDryRun(cache, &ChainDB{AccountModel: accountModel, MempoolModel: mempoolModel,
+		BlockModel: blockModel})
+	nonce, err = statedb.GetPendingNonce(0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), nonce)
+
+	_, err = statedb.GetPendingNonce(1)
+	assert.Error(t, err)
+}
@@ -34,6 +34,7 @@ type (
 		DropAccountHistoryTable() error
 		GetValidAccounts(height int64, limit int, offset int) (rowsAffected int64, accounts []*AccountHistory, err error)
 		GetValidAccountCount(height int64) (accounts int64, err error)
+		GetLatestAccountHistory(accountIndex int64, height int64) (accountHistory *AccountHistory, err error)
 	}
 
 	defaultAccountHistoryModel struct {
@@ -110,3 +111,15 @@ func (m *defaultAccountHistoryModel) GetValidAccountCount(height int64) (count i
 	}
 	return count, nil
 }
+
+func (m *defaultAccountHistoryModel) GetLatestAccountHistory(accountIndex int64, height int64) (accountHistory *AccountHistory, err error) {
+	dbTx := m.DB.Table(m.table).
+		Where("account_index = ? AND l2_block_height <= ? AND l2_block_height != -1", accountIndex, height).
+		Order("l2_block_height desc").Limit(1).Find(&accountHistory)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return accountHistory, nil
+}
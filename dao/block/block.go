@@ -215,7 +215,7 @@ func (m *defaultBlockModel) GetBlockByHeight(blockHeight int64) (block *Block, e
 	)
 	dbTx := m.DB.Table(m.table).Where("block_height = ?", blockHeight).Find(&block)
 	if dbTx.Error != nil {
-		return nil, types.DbErrSqlOperation
+		return nil, types.WrapDbErr(types.DbErrSqlOperation, dbTx.Error)
 	} else if dbTx.RowsAffected == 0 {
 		return nil, types.DbErrNotFound
 	}
@@ -224,7 +224,7 @@ func (m *defaultBlockModel) GetBlockByHeight(blockHeight int64) (block *Block, e
 		return block.Txs[i].TxIndex < block.Txs[j].TxIndex
 	})
 	if err != nil {
-		return nil, types.DbErrSqlOperation
+		return nil, types.WrapDbErr(types.DbErrSqlOperation, err)
 	}
 
 	return block, nil
@@ -278,7 +278,7 @@ func (m *defaultBlockModel) CreateGenesisBlock(block *Block) error {
 func (m *defaultBlockModel) GetCurrentHeight() (blockHeight int64, err error) {
 	dbTx := m.DB.Table(m.table).Select("block_height").Order("block_height desc").Limit(1).Find(&blockHeight)
 	if dbTx.Error != nil {
-		return 0, types.DbErrSqlOperation
+		return 0, types.WrapDbErr(types.DbErrSqlOperation, dbTx.Error)
 	} else if dbTx.RowsAffected == 0 {
 		return 0, types.DbErrNotFound
 	}
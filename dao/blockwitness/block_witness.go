@@ -35,6 +35,11 @@ type (
 		UpdateBlockWitnessStatus(witness *BlockWitness, status int64) error
 		GetLatestBlockWitness() (witness *BlockWitness, err error)
 		CreateBlockWitness(witness *BlockWitness) error
+		ClaimBlockWitness(prover string, leaseExpiresAt time.Time, blockSizes []int) (witness *BlockWitness, err error)
+		RenewBlockWitnessLease(height int64, prover string, leaseExpiresAt time.Time) error
+		ReleaseBlockWitness(height int64, prover string) error
+		CompleteBlockWitness(height int64) error
+		FailBlockWitnessProof(height int64, prover string, maxFailures int) (failed bool, err error)
 	}
 
 	defaultBlockWitnessModel struct {
@@ -47,6 +52,13 @@ type (
 		Height      int64 `gorm:"index:idx_height,unique"`
 		WitnessData string
 		Status      int64
+		// the number of the txs in the witness, 0 for the witnesses created by the former witness
+		BlockSize int
+		// the prover holding the lease of the witness, or the one proved it
+		Prover         string
+		LeaseExpiresAt *time.Time
+		// the times the proofs of the witness failed to be verified
+		ProofFailures int `gorm:"default:0"`
 	}
 )
 
@@ -124,3 +136,91 @@ func (m *defaultBlockWitnessModel) UpdateBlockWitnessStatus(witness *BlockWitnes
 	}
 	return nil
 }
+
+// ClaimBlockWitness leases the lowest witness which is published or whose lease is expired to the prover. The witness
+// locked by another claim is skipped, so the provers claim different witnesses concurrently. If the block sizes are
+// not empty, only the witnesses of the block sizes are claimed.
+func (m *defaultBlockWitnessModel) ClaimBlockWitness(prover string, leaseExpiresAt time.Time, blockSizes []int) (witness *BlockWitness, err error) {
+	now := time.Now()
+	claimable := `deleted_at IS NULL AND (status = ? OR (status = ? AND (lease_expires_at IS NULL OR lease_expires_at < ?)))`
+	args := []interface{}{StatusReceived, prover, leaseExpiresAt, now, StatusPublished, StatusReceived, now}
+	if len(blockSizes) > 0 {
+		claimable += ` AND block_size IN ?`
+		args = append(args, blockSizes)
+	}
+	dbTx := m.DB.Raw(`UPDATE `+m.table+` SET status = ?, prover = ?, lease_expires_at = ?, updated_at = ?
+		WHERE id = (SELECT id FROM `+m.table+` WHERE `+claimable+` ORDER BY height ASC LIMIT 1
+		FOR UPDATE SKIP LOCKED) RETURNING *`, args...).Scan(&witness)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return witness, nil
+}
+
+// RenewBlockWitnessLease extends the lease of the witness held by the prover, DbErrBlockWitnessLeaseLost is returned
+// if the witness is released or claimed by another prover.
+func (m *defaultBlockWitnessModel) RenewBlockWitnessLease(height int64, prover string, leaseExpiresAt time.Time) error {
+	dbTx := m.DB.Table(m.table).Where("height = ? AND prover = ? AND status = ?", height, prover, StatusReceived).
+		Updates(map[string]interface{}{
+			"lease_expires_at": leaseExpiresAt,
+			"updated_at":       time.Now(),
+		})
+	if dbTx.Error != nil {
+		return types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return types.DbErrBlockWitnessLeaseLost
+	}
+	return nil
+}
+
+// ReleaseBlockWitness publishes the witness held by the prover again, so it can be claimed by the others at once.
+func (m *defaultBlockWitnessModel) ReleaseBlockWitness(height int64, prover string) error {
+	dbTx := m.DB.Table(m.table).Where("height = ? AND prover = ? AND status = ?", height, prover, StatusReceived).
+		Updates(map[string]interface{}{
+			"status":           StatusPublished,
+			"prover":           "",
+			"lease_expires_at": nil,
+			"updated_at":       time.Now(),
+		})
+	if dbTx.Error != nil {
+		return types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return types.DbErrBlockWitnessLeaseLost
+	}
+	return nil
+}
+
+// CompleteBlockWitness marks the witness as proved, it is claimed no more.
+func (m *defaultBlockWitnessModel) CompleteBlockWitness(height int64) error {
+	dbTx := m.DB.Table(m.table).Where("height = ?", height).
+		Updates(map[string]interface{}{
+			"status":           StatusProved,
+			"lease_expires_at": nil,
+			"updated_at":       time.Now(),
+		})
+	if dbTx.Error != nil {
+		return types.DbErrSqlOperation
+	}
+	return nil
+}
+
+// FailBlockWitnessProof counts the failed proof of the prover holding the lease of the witness, and releases the
+// witness to be proved again. Once the proofs failed maxFailures times, by the same prover or not, the witness is
+// marked as StatusProofFailed and claimed no more. DbErrBlockWitnessLeaseLost is returned if the prover doesn't hold
+// the lease.
+func (m *defaultBlockWitnessModel) FailBlockWitnessProof(height int64, prover string, maxFailures int) (
+	failed bool, err error) {
+	var witness *BlockWitness
+	dbTx := m.DB.Raw(`UPDATE `+m.table+` SET proof_failures = proof_failures + 1,
+		status = CASE WHEN proof_failures + 1 >= ? THEN ? ELSE ? END, prover = '', lease_expires_at = NULL, updated_at = ?
+		WHERE height = ? AND prover = ? AND status = ? AND deleted_at IS NULL RETURNING *`,
+		maxFailures, StatusProofFailed, StatusPublished, time.Now(), height, prover, StatusReceived).Scan(&witness)
+	if dbTx.Error != nil {
+		return false, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return false, types.DbErrBlockWitnessLeaseLost
+	}
+	return witness.Status == StatusProofFailed, nil
+}
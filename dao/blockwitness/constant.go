@@ -20,6 +20,9 @@ package blockwitness
 const (
 	StatusPublished = iota
 	StatusReceived
+	StatusProved
+	// the proofs of the witness failed to be verified several times, the witness is claimed no more
+	StatusProofFailed
 )
 
 const (
@@ -2,6 +2,7 @@ package dbcache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 )
 
@@ -12,6 +13,71 @@ type Cache interface {
 	Get(ctx context.Context, key string, value interface{}) (interface{}, error)
 	Set(ctx context.Context, key string, value interface{}) error
 	Delete(ctx context.Context, key string) error
+	// Scan calls fn with each key matching the pattern, the keys are iterated in batches.
+	Scan(ctx context.Context, match string, fn func(key string) error) error
+}
+
+// EntryVersion is the version of the format of the states cached by the committer, the entries of other versions,
+// e.g. the bare states written by the former committers, are not trusted.
+const EntryVersion = 1
+
+var (
+	ErrEntryVersion = errors.New("unsupported cache entry version")
+	ErrEntryStale   = errors.New("cache entry is above the current block")
+)
+
+// Entry is a state cached by the committer together with its position when the state is written, i.e. the state
+// after the tx at the tx index of the block is executed.
+type Entry struct {
+	Version     int
+	BlockHeight int64
+	TxIndex     int64
+	Value       interface{}
+}
+
+func NewEntry(blockHeight int64, txIndex int64, value interface{}) *Entry {
+	return &Entry{
+		Version:     EntryVersion,
+		BlockHeight: blockHeight,
+		TxIndex:     txIndex,
+		Value:       value,
+	}
+}
+
+// GetEntry gets the entry of the key, the state is decoded into the value.
+func GetEntry(ctx context.Context, cache Cache, key string, value interface{}) (*Entry, error) {
+	entry := &Entry{Value: value}
+	_, err := cache.Get(ctx, key, entry)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Version != EntryVersion {
+		return nil, ErrEntryVersion
+	}
+	return entry, nil
+}
+
+// HeightFunc returns the height of the current block, the callers read it once for all the keys they get, or reuse it
+// for a while, rather than query the database for each key.
+type HeightFunc func() (int64, error)
+
+// GetCurrentEntry gets the entry of the key as GetEntry does. The entry written at a height above the current block,
+// e.g. of a block rolled back, is stale, the state should be read from the database instead. The height is only read
+// if the key is cached.
+func GetCurrentEntry(ctx context.Context, cache Cache, currentHeight HeightFunc, key string,
+	value interface{}) (*Entry, error) {
+	entry, err := GetEntry(ctx, cache, key, value)
+	if err != nil {
+		return nil, err
+	}
+	height, err := currentHeight()
+	if err != nil {
+		return nil, err
+	}
+	if entry.BlockHeight > height {
+		return nil, ErrEntryStale
+	}
+	return entry, nil
 }
 
 const (
@@ -16,7 +16,11 @@ var (
 	redisKeyNotExist = errors.New("redis: nil")
 )
 
+// the number of keys hinted to redis in a scan.
+const scanCount = 1000
+
 type RedisCache struct {
+	client     *redis.Client
 	marshal    *marshaler.Marshaler
 	expiration time.Duration
 }
@@ -28,17 +32,23 @@ func NewRedisCache(redisAdd, password string, expiration time.Duration) Cache {
 	promMetrics := metrics.NewPrometheus("zkbas")
 	cacheManager := cache.NewMetric(promMetrics, redisCacheManager)
 	return &RedisCache{
+		client:     client,
 		marshal:    marshaler.New(cacheManager),
 		expiration: expiration,
 	}
 }
 
+// IsNotExist reports whether the error is returned for a key not existing.
+func IsNotExist(err error) bool {
+	return err != nil && err.Error() == redisKeyNotExist.Error()
+}
+
 func (c *RedisCache) GetWithSet(ctx context.Context, key string, valueStruct interface{}, query QueryFunc) (interface{}, error) {
 	value, err := c.marshal.Get(ctx, key, valueStruct)
 	if err == nil {
 		return value, nil
 	}
-	if err.Error() == redisKeyNotExist.Error() {
+	if IsNotExist(err) {
 		value, err = query()
 		if err != nil {
 			return nil, err
@@ -63,3 +73,14 @@ func (c *RedisCache) Set(ctx context.Context, key string, value interface{}) err
 func (c *RedisCache) Delete(ctx context.Context, key string) error {
 	return c.marshal.Delete(ctx, key)
 }
+
+func (c *RedisCache) Scan(ctx context.Context, match string, fn func(key string) error) error {
+	iter := c.client.Scan(ctx, 0, match, scanCount).Iterator()
+	for iter.Next(ctx) {
+		err := fn(iter.Val())
+		if err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
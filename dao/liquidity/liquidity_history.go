@@ -33,6 +33,7 @@ type (
 		DropLiquidityHistoryTable() error
 		GetLatestLiquidityByBlockHeight(blockHeight int64, limit int, offset int) (entities []*LiquidityHistory, err error)
 		GetLatestLiquidityCountByBlockHeight(blockHeight int64) (count int64, err error)
+		GetLatestLiquidityHistory(pairIndex int64, blockHeight int64) (entity *LiquidityHistory, err error)
 	}
 
 	defaultLiquidityHistoryModel struct {
@@ -104,3 +105,15 @@ func (m *defaultLiquidityHistoryModel) GetLatestLiquidityCountByBlockHeight(bloc
 	}
 	return count, nil
 }
+
+func (m *defaultLiquidityHistoryModel) GetLatestLiquidityHistory(pairIndex int64, blockHeight int64) (entity *LiquidityHistory, err error) {
+	dbTx := m.DB.Table(m.table).
+		Where("pair_index = ? AND l2_block_height <= ?", pairIndex, blockHeight).
+		Order("l2_block_height desc").Limit(1).Find(&entity)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return entity, nil
+}
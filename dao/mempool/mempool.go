@@ -18,7 +18,7 @@
 package mempool
 
 import (
-	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -47,7 +47,13 @@ type (
 		GetMempoolTxsByBlockHeight(l2BlockHeight int64) (rowsAffected int64, mempoolTxs []*MempoolTx, err error)
 		CreateBatchedMempoolTxs(mempoolTxs []*MempoolTx) error
 		GetPendingMempoolTxsByAccountIndex(accountIndex int64) (mempoolTxs []*MempoolTx, err error)
+		GetPendingMempoolTxsCountByAccountIndex(accountIndex int64) (count int64, err error)
 		GetMaxNonceByAccountIndex(accountIndex int64) (nonce int64, err error)
+		GetNoncesByAccountIndex(accountIndex int64, fromNonce int64) (nonces []int64, err error)
+		GetPendingMempoolTxByAccountNonce(accountIndex int64, nonce int64) (mempoolTx *MempoolTx, err error)
+		ReplacePendingMempoolTx(oldMempoolTx *MempoolTx, newMempoolTx *MempoolTx) error
+		DeletePendingMempoolTx(mempoolTx *MempoolTx) error
+		ClaimPendingMempoolTxs(mempoolTxs []*MempoolTx) (claimed []*MempoolTx, err error)
 		UpdateMempoolTxs(pendingUpdateMempoolTxs []*MempoolTx, pendingDeleteMempoolTxs []*MempoolTx) error
 	}
 
@@ -116,7 +122,7 @@ func (m *defaultMempoolModel) GetMempoolTxsByBlockHeight(l2BlockHeight int64) (r
 func (m *defaultMempoolModel) GetMempoolTxsByStatus(status int) (mempoolTxs []*MempoolTx, err error) {
 	dbTx := m.DB.Table(m.table).Where("status = ?", status).Order("created_at, id").Find(&mempoolTxs)
 	if dbTx.Error != nil {
-		return nil, types.DbErrSqlOperation
+		return nil, types.WrapDbErr(types.DbErrSqlOperation, dbTx.Error)
 	}
 	return mempoolTxs, nil
 }
@@ -180,6 +186,15 @@ func (m *defaultMempoolModel) GetPendingMempoolTxsByAccountIndex(accountIndex in
 	return mempoolTxs, nil
 }
 
+func (m *defaultMempoolModel) GetPendingMempoolTxsCountByAccountIndex(accountIndex int64) (count int64, err error) {
+	dbTx := m.DB.Table(m.table).Where("status = ? AND account_index = ? AND deleted_at is NULL", PendingTxStatus, accountIndex).
+		Count(&count)
+	if dbTx.Error != nil {
+		return 0, types.DbErrSqlOperation
+	}
+	return count, nil
+}
+
 func (m *defaultMempoolModel) GetMaxNonceByAccountIndex(accountIndex int64) (nonce int64, err error) {
 	dbTx := m.DB.Table(m.table).Select("nonce").Where("deleted_at is null and account_index = ?", accountIndex).Order("nonce desc").Limit(1).Find(&nonce)
 	if dbTx.Error != nil {
@@ -190,10 +205,97 @@ func (m *defaultMempoolModel) GetMaxNonceByAccountIndex(accountIndex int64) (non
 	return nonce, nil
 }
 
+// GetNoncesByAccountIndex returns the nonces from the given one taken by the txs of the account in the mempool, in
+// ascending order.
+func (m *defaultMempoolModel) GetNoncesByAccountIndex(accountIndex int64, fromNonce int64) (nonces []int64, err error) {
+	dbTx := m.DB.Table(m.table).Distinct("nonce").Where("deleted_at is null and account_index = ? and nonce >= ?", accountIndex, fromNonce).
+		Order("nonce").Find(&nonces)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	}
+	return nonces, nil
+}
+
+func (m *defaultMempoolModel) GetPendingMempoolTxByAccountNonce(accountIndex int64, nonce int64) (mempoolTx *MempoolTx, err error) {
+	dbTx := m.DB.Table(m.table).Where("status = ? AND account_index = ? AND nonce = ?", PendingTxStatus, accountIndex, nonce).
+		Order("id desc").Limit(1).Find(&mempoolTx)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return mempoolTx, nil
+}
+
+// ReplacePendingMempoolTx deletes the old tx and creates the new one in one transaction. It fails with
+// DbErrMempoolTxNotPending if the old tx has been picked up by the committer in the meantime.
+func (m *defaultMempoolModel) ReplacePendingMempoolTx(oldMempoolTx *MempoolTx, newMempoolTx *MempoolTx) error {
+	return m.DB.Transaction(func(tx *gorm.DB) error { // transact
+		dbTx := tx.Table(m.table).Where("id = ? AND status = ?", oldMempoolTx.ID, PendingTxStatus).Delete(&MempoolTx{})
+		if dbTx.Error != nil {
+			return dbTx.Error
+		}
+		if dbTx.RowsAffected == 0 {
+			return types.DbErrMempoolTxNotPending
+		}
+		dbTx = tx.Table(m.table).Create(newMempoolTx)
+		if dbTx.Error != nil {
+			return dbTx.Error
+		}
+		if dbTx.RowsAffected == 0 {
+			return types.DbErrFailToCreateMempoolTx
+		}
+		return nil
+	})
+}
+
+func (m *defaultMempoolModel) DeletePendingMempoolTx(mempoolTx *MempoolTx) error {
+	dbTx := m.DB.Table(m.table).Where("id = ? AND status = ?", mempoolTx.ID, PendingTxStatus).Delete(&MempoolTx{})
+	if dbTx.Error != nil {
+		return types.DbErrSqlOperation
+	}
+	if dbTx.RowsAffected == 0 {
+		return types.DbErrMempoolTxNotPending
+	}
+	return nil
+}
+
+// ClaimPendingMempoolTxs marks the pending txs as executed before the committer executes them, so they can't be
+// replaced or cancelled by their senders any more. The txs replaced or cancelled already are not claimed, the claimed
+// ones are returned in the given order.
+func (m *defaultMempoolModel) ClaimPendingMempoolTxs(mempoolTxs []*MempoolTx) (claimed []*MempoolTx, err error) {
+	if len(mempoolTxs) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint, 0, len(mempoolTxs))
+	for _, mempoolTx := range mempoolTxs {
+		ids = append(ids, mempoolTx.ID)
+	}
+	var claimedIds []uint
+	dbTx := m.DB.Raw(`UPDATE `+m.table+` SET status = ?, updated_at = ?
+		WHERE id IN ? AND status = ? AND deleted_at IS NULL RETURNING id`,
+		ExecutedTxStatus, time.Now(), ids, PendingTxStatus).Scan(&claimedIds)
+	if dbTx.Error != nil {
+		return nil, types.WrapDbErr(types.DbErrSqlOperation, dbTx.Error)
+	}
+	claimedIdSet := make(map[uint]bool, len(claimedIds))
+	for _, id := range claimedIds {
+		claimedIdSet[id] = true
+	}
+	for _, mempoolTx := range mempoolTxs {
+		if claimedIdSet[mempoolTx.ID] {
+			mempoolTx.Status = ExecutedTxStatus
+			claimed = append(claimed, mempoolTx)
+		}
+	}
+	return claimed, nil
+}
+
 func (m *defaultMempoolModel) UpdateMempoolTxs(pendingUpdateMempoolTxs []*MempoolTx, pendingDeleteMempoolTxs []*MempoolTx) (err error) {
 	return m.DB.Transaction(func(tx *gorm.DB) error { // transact
 
-		// update mempool
+		// update mempool, the executed txs are claimed before they are executed, so they can't have been replaced or
+		// cancelled by their senders
 		for _, mempoolTx := range pendingUpdateMempoolTxs {
 			dbTx := tx.Table(MempoolTableName).Where("id = ?", mempoolTx.ID).
 				Select("*").
@@ -202,17 +304,16 @@ func (m *defaultMempoolModel) UpdateMempoolTxs(pendingUpdateMempoolTxs []*Mempoo
 				return dbTx.Error
 			}
 			if dbTx.RowsAffected == 0 {
-				return errors.New("no new mempoolTx")
+				return types.DbErrMempoolTxNotPending
 			}
 		}
+		// the pending txs dropped by the committer may have been replaced or cancelled by their senders already, so
+		// no row affected is fine
 		for _, pendingDeleteMempoolTx := range pendingDeleteMempoolTxs {
 			dbTx := tx.Table(MempoolTableName).Where("id = ?", pendingDeleteMempoolTx.ID).Delete(&pendingDeleteMempoolTx)
 			if dbTx.Error != nil {
 				return dbTx.Error
 			}
-			if dbTx.RowsAffected == 0 {
-				return errors.New("delete invalid mempool tx")
-			}
 		}
 
 		return nil
@@ -35,6 +35,7 @@ type (
 		GetLatestNftIndex() (nftIndex int64, err error)
 		GetNftListByAccountIndex(accountIndex, limit, offset int64) (nfts []*L2Nft, err error)
 		GetAccountNftTotalCount(accountIndex int64) (int64, error)
+		GetNftsList(limit int, offset int64) (nfts []*L2Nft, err error)
 	}
 	defaultL2NftModel struct {
 		table string
@@ -115,3 +116,13 @@ func (m *defaultL2NftModel) GetAccountNftTotalCount(accountIndex int64) (int64,
 	}
 	return count, nil
 }
+
+func (m *defaultL2NftModel) GetNftsList(limit int, offset int64) (nftList []*L2Nft, err error) {
+	dbTx := m.DB.Table(m.table).Limit(limit).Offset(int(offset)).Order("nft_index").Find(&nftList)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return nftList, nil
+}
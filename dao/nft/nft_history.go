@@ -37,6 +37,7 @@ type (
 		GetLatestNftAssetsByBlockHeight(height int64, limit int, offset int) (
 			rowsAffected int64, nftAssets []*L2NftHistory, err error,
 		)
+		GetLatestNftHistory(nftIndex int64, height int64) (nftAsset *L2NftHistory, err error)
 	}
 	defaultL2NftHistoryModel struct {
 		table string
@@ -109,3 +110,15 @@ func (m *defaultL2NftHistoryModel) GetLatestNftAssetsByBlockHeight(height int64,
 	}
 	return dbTx.RowsAffected, accountNftAssets, nil
 }
+
+func (m *defaultL2NftHistoryModel) GetLatestNftHistory(nftIndex int64, height int64) (nftAsset *L2NftHistory, err error) {
+	dbTx := m.DB.Table(m.table).
+		Where("nft_index = ? AND l2_block_height <= ?", nftIndex, height).
+		Order("l2_block_height desc").Limit(1).Find(&nftAsset)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return nftAsset, nil
+}
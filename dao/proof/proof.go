@@ -52,6 +52,8 @@ type (
 		ProofInfo   string
 		BlockNumber int64 `gorm:"index:idx_number,unique"`
 		Status      int64
+		// the prover generated the proof
+		Prover string
 	}
 )
 
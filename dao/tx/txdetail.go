@@ -33,6 +33,7 @@ type (
 		DropTxDetailTable() error
 		GetTxDetailByAccountIndex(accountIndex int64) (txDetails []*TxDetail, err error)
 		GetTxIdsByAccountIndex(accountIndex int64) (txIds []int64, err error)
+		GetTxDetailsByTxId(txId int64) (txDetails []*TxDetail, err error)
 	}
 
 	defaultTxDetailModel struct {
@@ -97,3 +98,13 @@ func (m *defaultTxDetailModel) GetTxIdsByAccountIndex(accountIndex int64) (txIds
 	})
 	return txIds, nil
 }
+
+func (m *defaultTxDetailModel) GetTxDetailsByTxId(txId int64) (txDetails []*TxDetail, err error) {
+	dbTx := m.DB.Table(m.table).Where("tx_id = ?", txId).Order("\"order\"").Find(&txDetails)
+	if dbTx.Error != nil {
+		return nil, types.DbErrSqlOperation
+	} else if dbTx.RowsAffected == 0 {
+		return nil, types.DbErrNotFound
+	}
+	return txDetails, nil
+}
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"time"
+
 	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/rest"
+
+	"github.com/bnb-chain/zkbas/core/hook"
 )
 
 type Config struct {
@@ -24,4 +28,19 @@ type Config struct {
 		TxExpiration      int
 		PriceExpiration   int
 	}
+	TxPool struct {
+		// the minimum gas fee increase in percent for a tx to replace a pending tx with the same nonce
+		ReplacementFeeBump int64 `json:",default=10"`
+		// the admission limits of new txs, 0 means no limit
+		MaxPendingTxsPerAccount int64 `json:",default=64"`
+		MaxPendingTxs           int64 `json:",default=10000"`
+		// the maximum distance between the nonce of a new tx and the committed nonce of its sender
+		MaxNonceGap int64 `json:",default=64"`
+		// the maximum number of txs sent in one batch
+		MaxBatchSize int `json:",default=100"`
+	}
+	TxHooks hook.Config
+	// the interval of rebuilding the state of the latest verified block for the proofs, a rebuild reads every tree
+	// from the history tables
+	SnapshotRefreshInterval time.Duration `json:",default=10m"`
 }
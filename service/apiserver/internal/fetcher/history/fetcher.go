@@ -0,0 +1,102 @@
+package history
+
+import (
+	"errors"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+var ErrNotCreated = errors.New("block is not created")
+
+// Fetcher fetches the states (account, nft, liquidity) at a block height from the history tables, the state at a
+// height is the latest history not newer than it. Only the heights of the blocks created by the committer are
+// queryable, as the states of the later blocks are unknown yet.
+type Fetcher interface {
+	GetAccount(accountIndex int64, height int64) (*types.AccountInfo, error)
+	GetLiquidity(pairIndex int64, height int64) (*types.LiquidityInfo, error)
+	GetNft(nftIndex int64, height int64) (*types.NftInfo, error)
+}
+
+func NewFetcher(chainDb *sdb.ChainDB) Fetcher {
+	return &fetcher{
+		chainDb: chainDb,
+	}
+}
+
+type fetcher struct {
+	chainDb *sdb.ChainDB
+}
+
+func (f *fetcher) GetAccount(accountIndex int64, height int64) (*types.AccountInfo, error) {
+	err := f.checkHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	return sdb.GetHistoryAccount(f.chainDb, accountIndex, height)
+}
+
+func (f *fetcher) GetLiquidity(pairIndex int64, height int64) (*types.LiquidityInfo, error) {
+	err := f.checkHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	l, err := sdb.GetHistoryLiquidity(f.chainDb, pairIndex, height)
+	if err != nil {
+		return nil, err
+	}
+	return types.ConstructLiquidityInfo(
+		pairIndex,
+		l.AssetAId,
+		l.AssetA,
+		l.AssetBId,
+		l.AssetB,
+		l.LpAmount,
+		l.KLast,
+		l.FeeRate,
+		l.TreasuryAccountIndex,
+		l.TreasuryRate,
+	)
+}
+
+func (f *fetcher) GetNft(nftIndex int64, height int64) (*types.NftInfo, error) {
+	err := f.checkHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	n, err := sdb.GetHistoryNft(f.chainDb, nftIndex, height)
+	if err != nil {
+		return nil, err
+	}
+	return types.ConstructNftInfo(nftIndex,
+		n.CreatorAccountIndex,
+		n.OwnerAccountIndex,
+		n.NftContentHash,
+		n.NftL1TokenId,
+		n.NftL1Address,
+		n.CreatorTreasuryRate,
+		n.CollectionId), nil
+}
+
+// checkHeight checks the block at the height has been created, the block being proposed has no history yet.
+func (f *fetcher) checkHeight(height int64) error {
+	currentHeight, err := f.chainDb.BlockModel.GetCurrentHeight()
+	if err != nil {
+		return err
+	}
+	if height >= 0 && height < currentHeight {
+		return nil
+	}
+	if height != currentHeight {
+		return ErrNotCreated
+	}
+	currentBlock, err := f.chainDb.BlockModel.GetBlockByHeight(currentHeight)
+	if err != nil {
+		return err
+	}
+	if currentBlock.BlockStatus == block.StatusProposing {
+		return ErrNotCreated
+	}
+	return nil
+}
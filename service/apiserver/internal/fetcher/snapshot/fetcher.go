@@ -0,0 +1,110 @@
+package snapshot
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+)
+
+const batchReloadSize = 1000
+
+var (
+	ErrNotReady  = errors.New("state is not built yet")
+	ErrNotServed = errors.New("only the state of the latest verified block is served")
+)
+
+// Fetcher provides the state of the latest verified block, which is rebuilt in memory from the history tables in the
+// background. A rebuild reads every tree from the history tables, so it is never triggered by the queries, and the
+// states of the other blocks are not served, see the exit tool for the proofs at an older block.
+type Fetcher interface {
+	// View calls fn with the state db of the latest verified block built, the height must be 0 or the one of the
+	// block. The state db must not be used after fn returns.
+	View(height int64, fn func(height int64, statedb *sdb.StateDB) error) error
+}
+
+// NewFetcher builds the state of the latest verified block, and builds it again every refresh interval if more blocks
+// are verified.
+func NewFetcher(chainDb *sdb.ChainDB, refreshInterval time.Duration) Fetcher {
+	f := &fetcher{
+		chainDb: chainDb,
+	}
+	go f.refresh(refreshInterval)
+	return f
+}
+
+// view is the state db at a height.
+type view struct {
+	height  int64
+	statedb *sdb.StateDB
+
+	// the trees are mutated when the proofs are read, so the state db is used exclusively.
+	mu sync.Mutex
+}
+
+type fetcher struct {
+	chainDb *sdb.ChainDB
+
+	mu   sync.RWMutex // guards view only, the state db is built without it
+	view *view
+}
+
+func (f *fetcher) View(height int64, fn func(height int64, statedb *sdb.StateDB) error) error {
+	f.mu.RLock()
+	v := f.view
+	f.mu.RUnlock()
+	if v == nil {
+		return ErrNotReady
+	}
+	if height != 0 && height != v.height {
+		return ErrNotServed
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return fn(v.height, v.statedb)
+}
+
+// Height returns the height of the state served, 0 if it is not built yet.
+func (f *fetcher) Height() int64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.view == nil {
+		return 0
+	}
+	return f.view.height
+}
+
+func (f *fetcher) refresh(interval time.Duration) {
+	for {
+		err := f.build()
+		if err != nil {
+			logx.Errorf("build state of the latest verified block failed: %v", err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// build builds the state of the latest verified block if it is newer than the one served. The state served is
+// replaced once the new one is built, the queries using the former one are not interrupted.
+func (f *fetcher) build() error {
+	height, err := f.chainDb.BlockModel.GetLatestVerifiedHeight()
+	if err != nil {
+		return err
+	}
+	if height <= f.Height() {
+		return nil
+	}
+	logx.Infof("build state at block %d", height)
+	statedb, err := sdb.NewHistoryStateDB(f.chainDb, "apiserver", height, batchReloadSize)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.view = &view{height: height, statedb: statedb}
+	f.mu.Unlock()
+	logx.Infof("state at block %d is built", height)
+	return nil
+}
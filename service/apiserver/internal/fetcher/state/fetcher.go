@@ -2,19 +2,28 @@ package state
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/bnb-chain/zkbas/common/chain"
 	accdao "github.com/bnb-chain/zkbas/dao/account"
+	blockdao "github.com/bnb-chain/zkbas/dao/block"
 	"github.com/bnb-chain/zkbas/dao/dbcache"
 	liqdao "github.com/bnb-chain/zkbas/dao/liquidity"
 	nftdao "github.com/bnb-chain/zkbas/dao/nft"
 	"github.com/bnb-chain/zkbas/types"
 )
 
+// heightTTL is how long the height of the current block is reused, the entries of a block rolled back may be read
+// within it, and the ones of a new block are read from the database instead.
+const heightTTL = time.Second
+
 //go:generate mockgen -source api.go -destination api_mock.go -package state
 
 // Fetcher will fetch the latest states (account,nft,liquidity) from redis, which is written by committer;
-// and if the required data cannot be found then database will be used.
+// and if the required data cannot be found or is written above the current block then database will be used.
+// The height of the current block is shared by the queries for a second, so the cache is not followed by a database
+// query each time.
 type Fetcher interface {
 	GetLatestAccount(accountIndex int64) (accountInfo *types.AccountInfo, err error)
 	GetLatestLiquidity(pairIndex int64) (liquidityInfo *types.LiquidityInfo, err error)
@@ -24,12 +33,14 @@ type Fetcher interface {
 func NewFetcher(redisCache dbcache.Cache,
 	accountModel accdao.AccountModel,
 	liquidityModel liqdao.LiquidityModel,
-	nftModel nftdao.L2NftModel) Fetcher {
+	nftModel nftdao.L2NftModel,
+	blockModel blockdao.BlockModel) Fetcher {
 	return &fetcher{
 		redisCache:     redisCache,
 		accountModel:   accountModel,
 		liquidityModel: liquidityModel,
 		nftModel:       nftModel,
+		blockModel:     blockModel,
 	}
 }
 
@@ -38,14 +49,40 @@ type fetcher struct {
 	accountModel   accdao.AccountModel
 	liquidityModel liqdao.LiquidityModel
 	nftModel       nftdao.L2NftModel
+	blockModel     blockdao.BlockModel
+
+	mu           sync.Mutex // guards the height
+	height       int64
+	heightReadAt time.Time
+}
+
+// currentHeight returns the height of the current block, which is read from the database at most once per heightTTL.
+func (f *fetcher) currentHeight() (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if time.Since(f.heightReadAt) < heightTTL {
+		return f.height, nil
+	}
+	height, err := f.blockModel.GetCurrentHeight()
+	if err != nil {
+		return 0, err
+	}
+	f.height, f.heightReadAt = height, time.Now()
+	return height, nil
+}
+
+// getCachedEntry reads the state cached by the committer into the value.
+func (f *fetcher) getCachedEntry(key string, value interface{}) error {
+	_, err := dbcache.GetCurrentEntry(context.Background(), f.redisCache, f.currentHeight, key, value)
+	return err
 }
 
 func (f *fetcher) GetLatestAccount(accountIndex int64) (*types.AccountInfo, error) {
 	var fa *types.AccountInfo
 	account := &accdao.Account{}
 
-	redisAccount, err := f.redisCache.Get(context.Background(), dbcache.AccountKeyByIndex(accountIndex), account)
-	if err == nil && redisAccount != nil {
+	err := f.getCachedEntry(dbcache.AccountKeyByIndex(accountIndex), account)
+	if err == nil {
 		fa, err = chain.ToFormatAccountInfo(account)
 		if err == nil {
 			return fa, nil
@@ -66,8 +103,8 @@ func (f *fetcher) GetLatestAccount(accountIndex int64) (*types.AccountInfo, erro
 func (f *fetcher) GetLatestLiquidity(pairIndex int64) (liquidityInfo *types.LiquidityInfo, err error) {
 	l := &liqdao.Liquidity{}
 
-	redisLiquidity, err := f.redisCache.Get(context.Background(), dbcache.LiquidityKeyByIndex(pairIndex), l)
-	if err == nil && redisLiquidity != "" {
+	err = f.getCachedEntry(dbcache.LiquidityKeyByIndex(pairIndex), l)
+	if err == nil {
 	} else {
 		l, err = f.liquidityModel.GetLiquidityByPairIndex(pairIndex)
 		if err != nil {
@@ -92,8 +129,8 @@ func (f *fetcher) GetLatestLiquidity(pairIndex int64) (liquidityInfo *types.Liqu
 func (f *fetcher) GetLatestNft(nftIndex int64) (*types.NftInfo, error) {
 	n := &nftdao.L2Nft{}
 
-	redisNft, err := f.redisCache.Get(context.Background(), dbcache.NftKeyByIndex(nftIndex), n)
-	if err == nil && redisNft != "" {
+	err := f.getCachedEntry(dbcache.NftKeyByIndex(nftIndex), n)
+	if err == nil {
 	} else {
 		n, err = f.nftModel.GetNftAsset(nftIndex)
 		if err != nil {
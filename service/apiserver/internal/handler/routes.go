@@ -10,6 +10,7 @@ import (
 	info "github.com/bnb-chain/zkbas/service/apiserver/internal/handler/info"
 	nft "github.com/bnb-chain/zkbas/service/apiserver/internal/handler/nft"
 	pair "github.com/bnb-chain/zkbas/service/apiserver/internal/handler/pair"
+	proof "github.com/bnb-chain/zkbas/service/apiserver/internal/handler/proof"
 	root "github.com/bnb-chain/zkbas/service/apiserver/internal/handler/root"
 	transaction "github.com/bnb-chain/zkbas/service/apiserver/internal/handler/transaction"
 	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
@@ -40,6 +41,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/v1/account",
 				Handler: account.GetAccountHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/historicalAccount",
+				Handler: account.GetHistoricalAccountHandler(serverCtx),
+			},
 		},
 	)
 
@@ -140,6 +146,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/v1/pair",
 				Handler: pair.GetPairHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/historicalPair",
+				Handler: pair.GetHistoricalPairHandler(serverCtx),
+			},
 		},
 	)
 
@@ -185,6 +196,21 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/v1/sendTx",
 				Handler: transaction.SendTxHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/v1/sendTxs",
+				Handler: transaction.SendTxsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/v1/simulateTx",
+				Handler: transaction.SimulateTxHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/v1/cancelTx",
+				Handler: transaction.CancelTxHandler(serverCtx),
+			},
 		},
 	)
 
@@ -200,6 +226,31 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/v1/accountNfts",
 				Handler: nft.GetAccountNftsHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/historicalNft",
+				Handler: nft.GetHistoricalNftHandler(serverCtx),
+			},
+		},
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/accountAssetProof",
+				Handler: proof.GetAccountAssetProofHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/pairProof",
+				Handler: proof.GetPairProofHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/v1/nftProof",
+				Handler: proof.GetNftProofHandler(serverCtx),
+			},
 		},
 	)
 }
@@ -0,0 +1,81 @@
+package account
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbas/types"
+)
+
+const (
+	queryByIndex = "index"
+	queryByName  = "name"
+	queryByPk    = "pk"
+)
+
+func accountIndex(svcCtx *svc.ServiceContext, by, value string) (int64, error) {
+	index := int64(0)
+	var err error
+	switch by {
+	case queryByIndex:
+		index, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || index < 0 {
+			return 0, types2.AppErrInvalidParam.RefineError("invalid value for account index")
+		}
+	case queryByName:
+		index, err = svcCtx.MemCache.GetAccountIndexByName(value)
+	case queryByPk:
+		index, err = svcCtx.MemCache.GetAccountIndexByPk(value)
+	default:
+		return 0, types2.AppErrInvalidParam.RefineError("param by should be index|name|pk")
+	}
+
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return 0, types2.AppErrNotFound
+		}
+		return 0, types2.AppErrInternal
+	}
+	return index, nil
+}
+
+func accountInfo(svcCtx *svc.ServiceContext, account *types2.AccountInfo) (*types.Account, error) {
+	maxAssetId, err := svcCtx.AssetModel.GetMaxId()
+	if err != nil {
+		return nil, types2.AppErrInternal
+	}
+
+	resp := &types.Account{
+		Index:  account.AccountIndex,
+		Status: uint32(account.Status),
+		Name:   account.AccountName,
+		Pk:     account.PublicKey,
+		Nonce:  account.Nonce,
+		Assets: make([]*types.AccountAsset, 0),
+	}
+	for _, asset := range account.AssetInfo {
+		if asset.AssetId > maxAssetId {
+			continue //it is used for offer related, or empty balance; max ip id should be less than max asset id
+		}
+		if (asset.Balance == nil || asset.Balance.Cmp(big.NewInt(0)) == 0) &&
+			(asset.LpAmount == nil || asset.LpAmount.Cmp(big.NewInt(0)) == 0) {
+			continue
+		}
+		assetName, _ := svcCtx.MemCache.GetAssetNameById(asset.AssetId)
+		resp.Assets = append(resp.Assets, &types.AccountAsset{
+			Id:       uint32(asset.AssetId),
+			Name:     assetName,
+			Balance:  asset.Balance.String(),
+			LpAmount: asset.LpAmount.String(),
+		})
+	}
+
+	sort.Slice(resp.Assets, func(i, j int) bool {
+		return resp.Assets[i].Id < resp.Assets[j].Id
+	})
+
+	return resp, nil
+}
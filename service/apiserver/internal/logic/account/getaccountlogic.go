@@ -2,9 +2,6 @@ package account
 
 import (
 	"context"
-	"math/big"
-	"sort"
-	"strconv"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
@@ -13,12 +10,6 @@ import (
 	types2 "github.com/bnb-chain/zkbas/types"
 )
 
-const (
-	queryByIndex = "index"
-	queryByName  = "name"
-	queryByPk    = "pk"
-)
-
 type GetAccountLogic struct {
 	logx.Logger
 	ctx    context.Context
@@ -34,26 +25,9 @@ func NewGetAccountLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetAcc
 }
 
 func (l *GetAccountLogic) GetAccount(req *types.ReqGetAccount) (resp *types.Account, err error) {
-	index := int64(0)
-	switch req.By {
-	case queryByIndex:
-		index, err = strconv.ParseInt(req.Value, 10, 64)
-		if err != nil || index < 0 {
-			return nil, types2.AppErrInvalidParam.RefineError("invalid value for account index")
-		}
-	case queryByName:
-		index, err = l.svcCtx.MemCache.GetAccountIndexByName(req.Value)
-	case queryByPk:
-		index, err = l.svcCtx.MemCache.GetAccountIndexByPk(req.Value)
-	default:
-		return nil, types2.AppErrInvalidParam.RefineError("param by should be index|name|pk")
-	}
-
+	index, err := accountIndex(l.svcCtx, req.By, req.Value)
 	if err != nil {
-		if err == types2.DbErrNotFound {
-			return nil, types2.AppErrNotFound
-		}
-		return nil, types2.AppErrInternal
+		return nil, err
 	}
 
 	account, err := l.svcCtx.StateFetcher.GetLatestAccount(index)
@@ -64,39 +38,5 @@ func (l *GetAccountLogic) GetAccount(req *types.ReqGetAccount) (resp *types.Acco
 		return nil, types2.AppErrInternal
 	}
 
-	maxAssetId, err := l.svcCtx.AssetModel.GetMaxId()
-	if err != nil {
-		return nil, types2.AppErrInternal
-	}
-
-	resp = &types.Account{
-		Index:  account.AccountIndex,
-		Status: uint32(account.Status),
-		Name:   account.AccountName,
-		Pk:     account.PublicKey,
-		Nonce:  account.Nonce,
-		Assets: make([]*types.AccountAsset, 0),
-	}
-	for _, asset := range account.AssetInfo {
-		if asset.AssetId > maxAssetId {
-			continue //it is used for offer related, or empty balance; max ip id should be less than max asset id
-		}
-		if (asset.Balance == nil || asset.Balance.Cmp(big.NewInt(0)) == 0) &&
-			(asset.LpAmount == nil || asset.LpAmount.Cmp(big.NewInt(0)) == 0) {
-			continue
-		}
-		assetName, _ := l.svcCtx.MemCache.GetAssetNameById(asset.AssetId)
-		resp.Assets = append(resp.Assets, &types.AccountAsset{
-			Id:       uint32(asset.AssetId),
-			Name:     assetName,
-			Balance:  asset.Balance.String(),
-			LpAmount: asset.LpAmount.String(),
-		})
-	}
-
-	sort.Slice(resp.Assets, func(i, j int) bool {
-		return resp.Assets[i].Id < resp.Assets[j].Id
-	})
-
-	return resp, nil
+	return accountInfo(l.svcCtx, account)
 }
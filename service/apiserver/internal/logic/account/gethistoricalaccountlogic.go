@@ -0,0 +1,53 @@
+package account
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/fetcher/history"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbas/types"
+)
+
+type GetHistoricalAccountLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetHistoricalAccountLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetHistoricalAccountLogic {
+	return &GetHistoricalAccountLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetHistoricalAccountLogic) GetHistoricalAccount(req *types.ReqGetHistoricalAccount) (resp *types.HistoricalAccount, err error) {
+	index, err := accountIndex(l.svcCtx, req.By, req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := l.svcCtx.HistoryFetcher.GetAccount(index, req.BlockHeight)
+	if err != nil {
+		switch err {
+		case history.ErrNotCreated:
+			return nil, types2.AppErrInvalidParam.RefineError("block is not created")
+		case types2.DbErrNotFound:
+			return nil, types2.AppErrNotFound
+		default:
+			logx.Errorf("fail to get account %d at height %d, err: %s", index, req.BlockHeight, err.Error())
+			return nil, types2.AppErrInternal
+		}
+	}
+
+	resp = &types.HistoricalAccount{BlockHeight: req.BlockHeight}
+	resp.Account, err = accountInfo(l.svcCtx, account)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
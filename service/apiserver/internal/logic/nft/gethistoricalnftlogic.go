@@ -0,0 +1,60 @@
+package nft
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/fetcher/history"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbas/types"
+)
+
+type GetHistoricalNftLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetHistoricalNftLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetHistoricalNftLogic {
+	return &GetHistoricalNftLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetHistoricalNftLogic) GetHistoricalNft(req *types.ReqGetHistoricalNft) (resp *types.HistoricalNft, err error) {
+	if req.Index < 0 {
+		return nil, types2.AppErrInvalidParam.RefineError("invalid value for nft index")
+	}
+
+	nftItem, err := l.svcCtx.HistoryFetcher.GetNft(req.Index, req.BlockHeight)
+	if err != nil {
+		switch err {
+		case history.ErrNotCreated:
+			return nil, types2.AppErrInvalidParam.RefineError("block is not created")
+		case types2.DbErrNotFound:
+			return nil, types2.AppErrNotFound
+		default:
+			logx.Errorf("fail to get nft %d at height %d, err: %s", req.Index, req.BlockHeight, err.Error())
+			return nil, types2.AppErrInternal
+		}
+	}
+
+	resp = &types.HistoricalNft{
+		BlockHeight: req.BlockHeight,
+		Nft: &types.Nft{
+			Index:               nftItem.NftIndex,
+			CreatorAccountIndex: nftItem.CreatorAccountIndex,
+			OwnerAccountIndex:   nftItem.OwnerAccountIndex,
+			ContentHash:         nftItem.NftContentHash,
+			L1Address:           nftItem.NftL1Address,
+			L1TokenId:           nftItem.NftL1TokenId,
+			CreatorTreasuryRate: nftItem.CreatorTreasuryRate,
+			CollectionId:        nftItem.CollectionId,
+		},
+	}
+	return resp, nil
+}
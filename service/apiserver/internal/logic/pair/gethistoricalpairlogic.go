@@ -0,0 +1,60 @@
+package pair
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/fetcher/history"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbas/types"
+)
+
+type GetHistoricalPairLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetHistoricalPairLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetHistoricalPairLogic {
+	return &GetHistoricalPairLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetHistoricalPairLogic) GetHistoricalPair(req *types.ReqGetHistoricalPair) (resp *types.HistoricalPair, err error) {
+	pair, err := l.svcCtx.HistoryFetcher.GetLiquidity(int64(req.Index), req.BlockHeight)
+	if err != nil {
+		switch err {
+		case history.ErrNotCreated:
+			return nil, types2.AppErrInvalidParam.RefineError("block is not created")
+		case types2.DbErrNotFound:
+			return nil, types2.AppErrNotFound
+		default:
+			logx.Errorf("fail to get pair %d at height %d, err: %s", req.Index, req.BlockHeight, err.Error())
+			return nil, types2.AppErrInternal
+		}
+	}
+
+	assetAName, _ := l.svcCtx.MemCache.GetAssetNameById(pair.AssetAId)
+	assetBName, _ := l.svcCtx.MemCache.GetAssetNameById(pair.AssetBId)
+	resp = &types.HistoricalPair{
+		BlockHeight: req.BlockHeight,
+		Pair: &types.Pair{
+			Index:         req.Index,
+			AssetAId:      uint32(pair.AssetAId),
+			AssetAName:    assetAName,
+			AssetAAmount:  pair.AssetA.String(),
+			AssetBId:      uint32(pair.AssetBId),
+			AssetBName:    assetBName,
+			AssetBAmount:  pair.AssetB.String(),
+			FeeRate:       pair.FeeRate,
+			TreasuryRate:  pair.TreasuryRate,
+			TotalLpAmount: pair.LpAmount.String(),
+		},
+	}
+	return resp, nil
+}
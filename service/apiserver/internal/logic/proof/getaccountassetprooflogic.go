@@ -0,0 +1,81 @@
+package proof
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	"github.com/bnb-chain/zkbas/tree"
+	types2 "github.com/bnb-chain/zkbas/types"
+)
+
+type GetAccountAssetProofLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetAccountAssetProofLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetAccountAssetProofLogic {
+	return &GetAccountAssetProofLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetAccountAssetProofLogic) GetAccountAssetProof(req *types.ReqGetAccountAssetProof) (resp *types.AccountAssetProof, err error) {
+	if req.AssetId >= 1<<tree.AssetTreeHeight {
+		return nil, types2.AppErrInvalidParam.RefineError("invalid asset id")
+	}
+	accountIndex, assetId := int64(req.AccountIndex), int64(req.AssetId)
+
+	err = l.svcCtx.SnapshotFetcher.View(req.BlockHeight, func(height int64, statedb *sdb.StateDB) error {
+		accountProof, assetProof, err := statedb.GetAccountAssetProof(accountIndex, assetId)
+		if err != nil {
+			return err
+		}
+		assetTree, err := statedb.AccountAssetTrees.Get(accountIndex)
+		if err != nil {
+			return err
+		}
+
+		account := statedb.AccountMap[accountIndex]
+		asset := &types.AssetLeaf{
+			Id:                       req.AssetId,
+			Balance:                  types2.ZeroBigInt.String(),
+			LpAmount:                 types2.ZeroBigInt.String(),
+			OfferCanceledOrFinalized: types2.ZeroBigInt.String(),
+		}
+		if accountAsset, ok := account.AssetInfo[assetId]; ok {
+			asset.Balance = accountAsset.Balance.String()
+			asset.LpAmount = accountAsset.LpAmount.String()
+			asset.OfferCanceledOrFinalized = accountAsset.OfferCanceledOrFinalized.String()
+		}
+		resp = &types.AccountAssetProof{
+			Roots: stateRoots(height, statedb),
+			Account: &types.AccountLeaf{
+				Index:           account.AccountIndex,
+				NameHash:        account.AccountNameHash,
+				Pk:              account.PublicKey,
+				Nonce:           account.Nonce,
+				CollectionNonce: account.CollectionNonce,
+				AssetRoot:       common.Bytes2Hex(assetTree.Root()),
+			},
+			AccountProof: merkleProof(accountProof),
+			Asset:        asset,
+			AssetProof:   merkleProof(assetProof),
+		}
+		return nil
+	})
+	if err != nil {
+		if err != types2.DbErrNotFound {
+			logx.Errorf("get proof of asset %d of account %d failed: %v", assetId, accountIndex, err)
+		}
+		return nil, appError(err)
+	}
+	return resp, nil
+}
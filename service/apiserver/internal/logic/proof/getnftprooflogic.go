@@ -0,0 +1,64 @@
+package proof
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	"github.com/bnb-chain/zkbas/tree"
+	types2 "github.com/bnb-chain/zkbas/types"
+)
+
+type GetNftProofLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetNftProofLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetNftProofLogic {
+	return &GetNftProofLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetNftProofLogic) GetNftProof(req *types.ReqGetNftProof) (resp *types.NftProof, err error) {
+	if req.NftIndex < 0 || req.NftIndex >= 1<<tree.NftTreeHeight {
+		return nil, types2.AppErrInvalidParam.RefineError("invalid nft index")
+	}
+
+	err = l.svcCtx.SnapshotFetcher.View(req.BlockHeight, func(height int64, statedb *sdb.StateDB) error {
+		proof, err := statedb.GetNftProof(req.NftIndex)
+		if err != nil {
+			return err
+		}
+
+		nft := statedb.NftMap[req.NftIndex]
+		resp = &types.NftProof{
+			Roots: stateRoots(height, statedb),
+			Nft: &types.NftLeaf{
+				Index:               nft.NftIndex,
+				CreatorAccountIndex: nft.CreatorAccountIndex,
+				OwnerAccountIndex:   nft.OwnerAccountIndex,
+				ContentHash:         nft.NftContentHash,
+				L1Address:           nft.NftL1Address,
+				L1TokenId:           nft.NftL1TokenId,
+				CreatorTreasuryRate: nft.CreatorTreasuryRate,
+				CollectionId:        nft.CollectionId,
+			},
+			Proof: merkleProof(proof),
+		}
+		return nil
+	})
+	if err != nil {
+		if err != types2.DbErrNotFound {
+			logx.Errorf("get proof of nft %d failed: %v", req.NftIndex, err)
+		}
+		return nil, appError(err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,67 @@
+package proof
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	"github.com/bnb-chain/zkbas/tree"
+	types2 "github.com/bnb-chain/zkbas/types"
+)
+
+type GetPairProofLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGetPairProofLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetPairProofLogic {
+	return &GetPairProofLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetPairProofLogic) GetPairProof(req *types.ReqGetPairProof) (resp *types.PairProof, err error) {
+	if req.PairIndex >= 1<<tree.LiquidityTreeHeight {
+		return nil, types2.AppErrInvalidParam.RefineError("invalid pair index")
+	}
+	pairIndex := int64(req.PairIndex)
+
+	err = l.svcCtx.SnapshotFetcher.View(req.BlockHeight, func(height int64, statedb *sdb.StateDB) error {
+		proof, err := statedb.GetLiquidityProof(pairIndex)
+		if err != nil {
+			return err
+		}
+
+		liquidity := statedb.LiquidityMap[pairIndex]
+		resp = &types.PairProof{
+			Roots: stateRoots(height, statedb),
+			Pair: &types.PairLeaf{
+				Index:                liquidity.PairIndex,
+				AssetAId:             liquidity.AssetAId,
+				AssetA:               liquidity.AssetA,
+				AssetBId:             liquidity.AssetBId,
+				AssetB:               liquidity.AssetB,
+				LpAmount:             liquidity.LpAmount,
+				KLast:                liquidity.KLast,
+				FeeRate:              liquidity.FeeRate,
+				TreasuryAccountIndex: liquidity.TreasuryAccountIndex,
+				TreasuryRate:         liquidity.TreasuryRate,
+			},
+			Proof: merkleProof(proof),
+		}
+		return nil
+	})
+	if err != nil {
+		if err != types2.DbErrNotFound {
+			logx.Errorf("get proof of pair %d failed: %v", pairIndex, err)
+		}
+		return nil, appError(err)
+	}
+	return resp, nil
+}
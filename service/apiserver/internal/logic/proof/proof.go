@@ -0,0 +1,44 @@
+package proof
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/fetcher/snapshot"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbas/types"
+)
+
+func stateRoots(height int64, statedb *sdb.StateDB) *types.StateRoots {
+	return &types.StateRoots{
+		BlockHeight:   height,
+		StateRoot:     statedb.GetStateRoot(),
+		AccountRoot:   common.Bytes2Hex(statedb.AccountTree.Root()),
+		LiquidityRoot: common.Bytes2Hex(statedb.LiquidityTree.Root()),
+		NftRoot:       common.Bytes2Hex(statedb.NftTree.Root()),
+	}
+}
+
+func merkleProof(proof *sdb.MerkleProof) *types.MerkleProof {
+	siblings := make([]string, 0, len(proof.Siblings))
+	for _, sibling := range proof.Siblings {
+		siblings = append(siblings, common.Bytes2Hex(sibling))
+	}
+	return &types.MerkleProof{
+		Leaf:     common.Bytes2Hex(proof.Leaf),
+		Siblings: siblings,
+	}
+}
+
+func appError(err error) error {
+	switch err {
+	case snapshot.ErrNotServed:
+		return types2.AppErrInvalidParam.RefineError("only the latest verified block is served")
+	case snapshot.ErrNotReady:
+		return types2.AppErrStateNotReady
+	case types2.DbErrNotFound:
+		return types2.AppErrNotFound
+	default:
+		return types2.AppErrInternal
+	}
+}
@@ -0,0 +1,103 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/bnb-chain/zkbas-crypto/ffmath"
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	curve "github.com/bnb-chain/zkbas-crypto/ecc/ztwistededwards/tebn254"
+	zkbasCommon "github.com/bnb-chain/zkbas/common"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbas/types"
+)
+
+// cancelTxMsgTag separates the cancel message from the tx messages, which are also signed by the account key.
+var cancelTxMsgTag = ffmath.Mod(new(big.Int).SetBytes(zkbasCommon.KeccakHash([]byte("CancelTx"))), curve.Modulus)
+
+type CancelTxLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewCancelTxLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CancelTxLogic {
+	return &CancelTxLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CancelTxLogic) CancelTx(req *types.ReqCancelTx) (resp *types.TxHash, err error) {
+	mempoolTx, err := l.svcCtx.MempoolModel.GetMempoolTxByTxHash(req.TxHash)
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return nil, types2.AppErrTxNotPending
+		}
+		return nil, types2.AppErrInternal
+	}
+	if !types2.IsL2Tx(mempoolTx.TxType) {
+		return nil, types2.AppErrInvalidTxType
+	}
+
+	account, err := l.svcCtx.StateFetcher.GetLatestAccount(mempoolTx.AccountIndex)
+	if err != nil {
+		if err == types2.DbErrNotFound {
+			return nil, types2.AppErrNotFound
+		}
+		return nil, types2.AppErrInternal
+	}
+	sig, err := hex.DecodeString(req.Signature)
+	if err != nil {
+		return nil, types2.AppErrInvalidParam.RefineError("signature")
+	}
+	if err := verifyCancelTxSignature(mempoolTx.AccountIndex, mempoolTx.Nonce, mempoolTx.TxHash, sig, account.PublicKey); err != nil {
+		return nil, types2.AppErrInvalidSignature
+	}
+
+	if err := l.svcCtx.MempoolModel.DeletePendingMempoolTx(mempoolTx); err != nil {
+		if err == types2.DbErrMempoolTxNotPending {
+			return nil, types2.AppErrTxNotPending
+		}
+		return nil, types2.AppErrInternal
+	}
+	return &types.TxHash{TxHash: mempoolTx.TxHash}, nil
+}
+
+// computeCancelTxMsgHash computes the message to be signed for cancelling a pending tx:
+// MiMC(tag, account index, nonce, tx hash, chain id).
+func computeCancelTxMsgHash(accountIndex int64, nonce int64, txHash string) []byte {
+	hFunc := mimc.NewMiMC()
+	var buf bytes.Buffer
+	buf.Write(cancelTxMsgTag.FillBytes(make([]byte, 32)))
+	legendTxTypes.WriteInt64IntoBuf(&buf, accountIndex)
+	legendTxTypes.WriteInt64IntoBuf(&buf, nonce)
+	buf.Write(ffmath.Mod(new(big.Int).SetBytes(common.FromHex(txHash)), curve.Modulus).FillBytes(make([]byte, 32)))
+	legendTxTypes.WriteInt64IntoBuf(&buf, legendTxTypes.ChainId)
+	hFunc.Write(buf.Bytes())
+	return hFunc.Sum(nil)
+}
+
+func verifyCancelTxSignature(accountIndex int64, nonce int64, txHash string, sig []byte, pubKey string) error {
+	msgHash := computeCancelTxMsgHash(accountIndex, nonce, txHash)
+	pk, err := legendTxTypes.ParsePublicKey(pubKey)
+	if err != nil {
+		return err
+	}
+	isValid, err := pk.Verify(sig, msgHash, mimc.NewMiMC())
+	if err != nil {
+		return err
+	}
+	if !isValid {
+		return types2.AppErrInvalidSignature
+	}
+	return nil
+}
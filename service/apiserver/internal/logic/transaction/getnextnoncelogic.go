@@ -27,7 +27,7 @@ func NewGetNextNonceLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetN
 
 func (l *GetNextNonceLogic) GetNextNonce(req *types.ReqGetNextNonce) (*types.NextNonce, error) {
 	bc := core.NewBlockChainForDryRun(l.svcCtx.AccountModel, l.svcCtx.LiquidityModel, l.svcCtx.NftModel, l.svcCtx.MempoolModel,
-		l.svcCtx.RedisCache)
+		l.svcCtx.BlockModel, l.svcCtx.RedisCache, nil)
 	nonce, err := bc.StateDB().GetPendingNonce(int64(req.AccountIndex))
 	if err != nil {
 		if err == types2.DbErrNotFound {
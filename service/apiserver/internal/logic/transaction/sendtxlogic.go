@@ -2,11 +2,13 @@ package transaction
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"github.com/bnb-chain/zkbas/core"
 	"github.com/bnb-chain/zkbas/core/executor"
+	"github.com/bnb-chain/zkbas/core/hook"
 	"github.com/bnb-chain/zkbas/dao/mempool"
 	"github.com/bnb-chain/zkbas/dao/tx"
 	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
@@ -30,13 +32,19 @@ func NewSendTxLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SendTxLogi
 
 func (s *SendTxLogic) SendTx(req *types.ReqSendTx) (resp *types.TxHash, err error) {
 	resp = &types.TxHash{}
-	executor, err := s.getExecutor(int(req.TxType), req.TxInfo)
+	bc := core.NewBlockChainForDryRun(s.svcCtx.AccountModel, s.svcCtx.LiquidityModel, s.svcCtx.NftModel, s.svcCtx.MempoolModel,
+		s.svcCtx.BlockModel, s.svcCtx.RedisCache, s.svcCtx.TxHook)
+	t := &tx.Tx{TxType: int64(req.TxType), TxInfo: req.TxInfo}
+	executor, err := s.getExecutor(bc, t)
 	if err != nil {
 		return resp, types2.AppErrInvalidTx
 	}
 	if err := executor.Prepare(); err != nil {
 		return resp, err
 	}
+	if err := hook.PreExecute(s.svcCtx.TxHook, bc.StateDB(), t); err != nil {
+		return resp, rejectTx(s.svcCtx, executor, err)
+	}
 	if err := executor.VerifyInputs(); err != nil {
 		return resp, types2.AppErrInvalidTxField.RefineError(err.Error())
 	}
@@ -45,20 +53,29 @@ func (s *SendTxLogic) SendTx(req *types.ReqSendTx) (resp *types.TxHash, err erro
 	if err != nil {
 		return resp, types2.AppErrInternal
 	}
-	if err := s.svcCtx.MempoolModel.CreateBatchedMempoolTxs([]*mempool.MempoolTx{mempoolTx}); err != nil {
-		logx.Errorf("fail to create mempool tx: %v, err: %s", mempoolTx, err.Error())
-		failTx := &tx.FailTx{
-			TxHash:    mempoolTx.TxHash,
-			TxType:    mempoolTx.TxType,
-			TxStatus:  tx.StatusFail,
-			AssetAId:  types2.NilAssetId,
-			AssetBId:  types2.NilAssetId,
-			TxAmount:  types2.NilAssetAmountStr,
-			TxInfo:    req.TxInfo,
-			ExtraInfo: err.Error(),
-			Memo:      "",
+
+	// a tx reusing the nonce of a pending tx replaces it if it pays enough more gas
+	pendingTx, err := s.svcCtx.MempoolModel.GetPendingMempoolTxByAccountNonce(mempoolTx.AccountIndex, mempoolTx.Nonce)
+	if err != nil && err != types2.DbErrNotFound {
+		return resp, types2.AppErrInternal
+	}
+	if pendingTx != nil {
+		if err := s.verifyReplacementFee(pendingTx, mempoolTx); err != nil {
+			return resp, err
+		}
+		err = s.svcCtx.MempoolModel.ReplacePendingMempoolTx(pendingTx, mempoolTx)
+	} else {
+		if err := verifyAdmission(s.svcCtx, []*mempool.MempoolTx{mempoolTx}); err != nil {
+			return resp, err
+		}
+		err = s.svcCtx.MempoolModel.CreateBatchedMempoolTxs([]*mempool.MempoolTx{mempoolTx})
+	}
+	if err != nil {
+		if err == types2.DbErrMempoolTxNotPending {
+			return resp, types2.AppErrTxNotPending
 		}
-		_ = s.svcCtx.FailTxModel.CreateFailTx(failTx)
+		logx.Errorf("fail to create mempool tx: %v, err: %s", mempoolTx, err.Error())
+		recordFailTx(s.svcCtx, mempoolTx, err.Error())
 		return resp, types2.AppErrInternal
 	}
 
@@ -66,12 +83,109 @@ func (s *SendTxLogic) SendTx(req *types.ReqSendTx) (resp *types.TxHash, err erro
 	return resp, nil
 }
 
-func (s *SendTxLogic) getExecutor(txType int, txInfo string) (executor.TxExecutor, error) {
-	bc := core.NewBlockChainForDryRun(s.svcCtx.AccountModel, s.svcCtx.LiquidityModel, s.svcCtx.NftModel, s.svcCtx.MempoolModel,
-		s.svcCtx.RedisCache)
-	t := &tx.Tx{TxType: int64(txType), TxInfo: txInfo}
+// verifyAdmission checks the limits of the tx pool before new pending txs are accepted, replacements
+// do not grow the pool and are not limited.
+func verifyAdmission(svcCtx *svc.ServiceContext, mempoolTxs []*mempool.MempoolTx) error {
+	limits := svcCtx.Config.TxPool
+
+	if limits.MaxPendingTxs > 0 {
+		count, err := svcCtx.MempoolModel.GetMempoolTxsTotalCount()
+		if err != nil {
+			return types2.AppErrInternal
+		}
+		if count+int64(len(mempoolTxs)) > limits.MaxPendingTxs {
+			return types2.AppErrTxPoolFull.RefineError("too many pending txs")
+		}
+	}
+
+	accountTxs := make(map[int64][]*mempool.MempoolTx)
+	for _, mempoolTx := range mempoolTxs {
+		accountTxs[mempoolTx.AccountIndex] = append(accountTxs[mempoolTx.AccountIndex], mempoolTx)
+	}
+	for accountIndex, txs := range accountTxs {
+		if limits.MaxPendingTxsPerAccount > 0 {
+			count, err := svcCtx.MempoolModel.GetPendingMempoolTxsCountByAccountIndex(accountIndex)
+			if err != nil {
+				return types2.AppErrInternal
+			}
+			if count+int64(len(txs)) > limits.MaxPendingTxsPerAccount {
+				return types2.AppErrTxPoolFull.RefineError("too many pending txs of account ", accountIndex)
+			}
+		}
+
+		if limits.MaxNonceGap > 0 {
+			account, err := svcCtx.StateFetcher.GetLatestAccount(accountIndex)
+			if err != nil {
+				return types2.AppErrInternal
+			}
+			for _, mempoolTx := range txs {
+				if mempoolTx.Nonce-account.Nonce >= limits.MaxNonceGap {
+					return types2.AppErrNonceTooHigh.RefineError("committed nonce of account ", accountIndex, " is ", account.Nonce)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// rejectTx records the tx rejected by the hooks as a fail tx with the reason. The tx not screened as the hooks are
+// unavailable is not recorded.
+func rejectTx(svcCtx *svc.ServiceContext, executor executor.TxExecutor, err error) error {
+	if hook.IsUnavailable(err) {
+		return types2.AppErrTxHookUnavailable
+	}
+	rejectedErr, ok := err.(*hook.RejectedError)
+	if !ok {
+		return types2.AppErrInternal
+	}
+	mempoolTx, err := executor.GenerateMempoolTx()
+	if err != nil {
+		return types2.AppErrInternal
+	}
+	recordFailTx(svcCtx, mempoolTx, rejectedErr.Reason)
+	return types2.AppErrTxRejected.RefineError(rejectedErr.Reason)
+}
+
+func recordFailTx(svcCtx *svc.ServiceContext, mempoolTx *mempool.MempoolTx, reason string) {
+	failTx := &tx.FailTx{
+		TxHash:    mempoolTx.TxHash,
+		TxType:    mempoolTx.TxType,
+		TxStatus:  tx.StatusFail,
+		AssetAId:  types2.NilAssetId,
+		AssetBId:  types2.NilAssetId,
+		TxAmount:  types2.NilAssetAmountStr,
+		TxInfo:    mempoolTx.TxInfo,
+		ExtraInfo: reason,
+		Memo:      "",
+	}
+	_ = svcCtx.FailTxModel.CreateFailTx(failTx)
+}
+
+func (s *SendTxLogic) verifyReplacementFee(pendingTx, mempoolTx *mempool.MempoolTx) error {
+	if pendingTx.GasFeeAssetId != mempoolTx.GasFeeAssetId {
+		return types2.AppErrReplacementTxUnderpriced.RefineError("gas fee asset differs from the pending tx")
+	}
+	pendingFee, ok := new(big.Int).SetString(pendingTx.GasFee, 10)
+	if !ok {
+		return types2.AppErrInternal
+	}
+	fee, ok := new(big.Int).SetString(mempoolTx.GasFee, 10)
+	if !ok {
+		return types2.AppErrInvalidTxField.RefineError("invalid gas fee")
+	}
+
+	// fee * 100 >= pendingFee * (100 + bump), and the fee must increase in any case
+	bump := s.svcCtx.Config.TxPool.ReplacementFeeBump
+	minFee := new(big.Int).Mul(pendingFee, big.NewInt(100+bump))
+	if fee.Cmp(pendingFee) <= 0 || new(big.Int).Mul(fee, big.NewInt(100)).Cmp(minFee) < 0 {
+		return types2.AppErrReplacementTxUnderpriced.RefineError(
+			"gas fee should be at least ", bump, "% higher than ", pendingTx.GasFee)
+	}
+	return nil
+}
 
-	switch txType {
+func (s *SendTxLogic) getExecutor(bc *core.BlockChain, t *tx.Tx) (executor.TxExecutor, error) {
+	switch t.TxType {
 	case types2.TxTypeTransfer:
 		return executor.NewTransferExecutor(bc, t)
 	case types2.TxTypeSwap:
@@ -95,7 +209,7 @@ func (s *SendTxLogic) getExecutor(txType int, txInfo string) (executor.TxExecuto
 	case types2.TxTypeMintNft:
 		return executor.NewMintNftExecutor(bc, t)
 	default:
-		logx.Errorf("invalid tx type: %v", txType)
+		logx.Errorf("invalid tx type: %v", t.TxType)
 		return nil, types2.AppErrInvalidTxType
 	}
 }
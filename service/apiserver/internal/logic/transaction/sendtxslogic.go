@@ -0,0 +1,111 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas/core"
+	"github.com/bnb-chain/zkbas/core/executor"
+	"github.com/bnb-chain/zkbas/core/hook"
+	"github.com/bnb-chain/zkbas/dao/mempool"
+	"github.com/bnb-chain/zkbas/dao/tx"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbas/types"
+)
+
+type SendTxsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSendTxsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SendTxsLogic {
+	return &SendTxsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SendTxs dry runs the txs one by one, each tx is verified against the state changed by the former ones.
+// The txs are inserted into the mempool only if all of them are valid, otherwise the batch is rejected with the
+// error of each invalid tx and none of them is accepted.
+func (s *SendTxsLogic) SendTxs(req *types.ReqSendTxs) (resp *types.SentTxs, err error) {
+	if len(req.Txs) == 0 {
+		return nil, types2.AppErrInvalidParam.RefineError("empty txs")
+	}
+	if len(req.Txs) > s.svcCtx.Config.TxPool.MaxBatchSize {
+		return nil, types2.AppErrInvalidParam.RefineError("too many txs")
+	}
+
+	bc := core.NewBlockChainForDryRun(s.svcCtx.AccountModel, s.svcCtx.LiquidityModel, s.svcCtx.NftModel, s.svcCtx.MempoolModel,
+		s.svcCtx.BlockModel, s.svcCtx.RedisCache, s.svcCtx.TxHook)
+	mempoolTxs := make([]*mempool.MempoolTx, 0, len(req.Txs))
+	txErrs := make([]string, 0)
+	for i, rawTx := range req.Txs {
+		mempoolTx, err := s.dryRunTx(bc, rawTx)
+		if err != nil {
+			txErrs = append(txErrs, fmt.Sprintf("tx %d: %s", i, err.Error()))
+			continue
+		}
+		mempoolTxs = append(mempoolTxs, mempoolTx)
+	}
+	if len(txErrs) > 0 {
+		return nil, types2.AppErrTxBatchRejected.RefineError(strings.Join(txErrs, "; "))
+	}
+
+	if err := verifyAdmission(s.svcCtx, mempoolTxs); err != nil {
+		return nil, err
+	}
+	if err := s.svcCtx.MempoolModel.CreateBatchedMempoolTxs(mempoolTxs); err != nil {
+		logx.Errorf("fail to create mempool txs, err: %s", err.Error())
+		for _, mempoolTx := range mempoolTxs {
+			recordFailTx(s.svcCtx, mempoolTx, err.Error())
+		}
+		return nil, types2.AppErrInternal
+	}
+
+	resp = &types.SentTxs{Txs: make([]*types.SentTx, 0, len(mempoolTxs))}
+	for _, mempoolTx := range mempoolTxs {
+		resp.Txs = append(resp.Txs, &types.SentTx{TxHash: mempoolTx.TxHash})
+	}
+	return resp, nil
+}
+
+func (s *SendTxsLogic) dryRunTx(bc *core.BlockChain, rawTx types.RawTx) (*mempool.MempoolTx, error) {
+	if !types2.IsL2Tx(int64(rawTx.TxType)) {
+		return nil, types2.AppErrInvalidTxType
+	}
+
+	t := &tx.Tx{TxType: int64(rawTx.TxType), TxInfo: rawTx.TxInfo}
+	mempoolTx, err := bc.DryRunTransaction(t)
+	if err != nil {
+		if hook.IsUnavailable(err) {
+			return nil, types2.AppErrTxHookUnavailable
+		}
+		// the txs rejected by the hooks are recorded as sendTx does
+		if _, ok := err.(*hook.RejectedError); ok {
+			txExecutor, newErr := executor.NewTxExecutor(bc, t)
+			if newErr != nil {
+				return nil, types2.AppErrInternal
+			}
+			return nil, rejectTx(s.svcCtx, txExecutor, err)
+		}
+		return nil, err
+	}
+
+	// pending txs can only be replaced one by one through sendTx
+	_, err = s.svcCtx.MempoolModel.GetPendingMempoolTxByAccountNonce(mempoolTx.AccountIndex, mempoolTx.Nonce)
+	if err == nil {
+		return nil, errors.New("invalid Nonce, it is used by a pending tx")
+	}
+	if err != types2.DbErrNotFound {
+		return nil, types2.AppErrInternal
+	}
+	return mempoolTx, nil
+}
@@ -0,0 +1,149 @@
+package transaction
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas/core"
+	"github.com/bnb-chain/zkbas/core/hook"
+	"github.com/bnb-chain/zkbas/dao/tx"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/svc"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	types2 "github.com/bnb-chain/zkbas/types"
+)
+
+type SimulateTxLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewSimulateTxLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SimulateTxLogic {
+	return &SimulateTxLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// SimulateTx executes the tx in dry run mode and returns the changes it would make, nothing is written.
+// The signature is not verified so that the tx can be simulated before it is signed.
+func (l *SimulateTxLogic) SimulateTx(req *types.ReqSimulateTx) (resp *types.SimulatedTx, err error) {
+	if !types2.IsL2Tx(int64(req.TxType)) {
+		return nil, types2.AppErrInvalidTxType
+	}
+
+	bc := core.NewBlockChainForSimulation(l.svcCtx.AccountModel, l.svcCtx.LiquidityModel, l.svcCtx.NftModel,
+		l.svcCtx.MempoolModel, l.svcCtx.BlockModel, l.svcCtx.RedisCache, l.svcCtx.TxHook,
+		core.SimulateOption{SkipSignature: true, SkipNonce: req.SkipNonce})
+	t := &tx.Tx{TxType: int64(req.TxType), TxInfo: req.TxInfo}
+	mempoolTx, err := bc.DryRunTransaction(t)
+	if err != nil {
+		if hook.IsUnavailable(err) {
+			return nil, types2.AppErrTxHookUnavailable
+		}
+		if rejectedErr, ok := err.(*hook.RejectedError); ok {
+			return nil, types2.AppErrTxRejected.RefineError(rejectedErr.Reason)
+		}
+		return nil, types2.AppErrInvalidTxField.RefineError(err.Error())
+	}
+
+	resp = &types.SimulatedTx{
+		TxHash:        mempoolTx.TxHash,
+		GasFeeAssetId: mempoolTx.GasFeeAssetId,
+		GasFee:        mempoolTx.GasFee,
+		Assets:        make([]*types.AssetDelta, 0),
+		Pairs:         make([]*types.PairDelta, 0),
+		Nfts:          make([]*types.Nft, 0),
+	}
+	if err := fillSimulatedTx(resp, t.TxDetails); err != nil {
+		logx.Errorf("fail to parse tx details, err: %s", err.Error())
+		return nil, types2.AppErrInternal
+	}
+	return resp, nil
+}
+
+// fillSimulatedTx sums up the deltas of the same account asset, the deltas of a pair, and keeps the latest
+// state of each nft.
+func fillSimulatedTx(resp *types.SimulatedTx, txDetails []*tx.TxDetail) error {
+	assets := make(map[[2]int64]*types.AssetDelta)
+	pairs := make(map[int64]*types.PairDelta)
+	nfts := make(map[int64]*types.Nft)
+	for _, txDetail := range txDetails {
+		switch txDetail.AssetType {
+		case types2.FungibleAssetType:
+			delta, err := types2.ParseAccountAsset(txDetail.BalanceDelta)
+			if err != nil {
+				return err
+			}
+			key := [2]int64{txDetail.AccountIndex, txDetail.AssetId}
+			asset, ok := assets[key]
+			if !ok {
+				asset = &types.AssetDelta{
+					AccountIndex:  txDetail.AccountIndex,
+					AccountName:   txDetail.AccountName,
+					AssetId:       txDetail.AssetId,
+					BalanceDelta:  "0",
+					LpAmountDelta: "0",
+				}
+				assets[key] = asset
+				resp.Assets = append(resp.Assets, asset)
+			}
+			asset.BalanceDelta = addAmount(asset.BalanceDelta, delta.Balance)
+			asset.LpAmountDelta = addAmount(asset.LpAmountDelta, delta.LpAmount)
+		case types2.LiquidityAssetType:
+			delta, err := types2.ParseLiquidityInfo(txDetail.BalanceDelta)
+			if err != nil {
+				return err
+			}
+			pair, ok := pairs[delta.PairIndex]
+			if !ok {
+				pair = &types.PairDelta{
+					Index:         delta.PairIndex,
+					AssetAId:      delta.AssetAId,
+					AssetADelta:   "0",
+					AssetBId:      delta.AssetBId,
+					AssetBDelta:   "0",
+					LpAmountDelta: "0",
+				}
+				pairs[delta.PairIndex] = pair
+				resp.Pairs = append(resp.Pairs, pair)
+			}
+			pair.AssetADelta = addAmount(pair.AssetADelta, delta.AssetA)
+			pair.AssetBDelta = addAmount(pair.AssetBDelta, delta.AssetB)
+			pair.LpAmountDelta = addAmount(pair.LpAmountDelta, delta.LpAmount)
+		case types2.NftAssetType:
+			nftInfo, err := types2.ParseNftInfo(txDetail.BalanceDelta)
+			if err != nil {
+				return err
+			}
+			nft, ok := nfts[nftInfo.NftIndex]
+			if !ok {
+				nft = &types.Nft{}
+				nfts[nftInfo.NftIndex] = nft
+				resp.Nfts = append(resp.Nfts, nft)
+			}
+			*nft = types.Nft{
+				Index:               nftInfo.NftIndex,
+				CreatorAccountIndex: nftInfo.CreatorAccountIndex,
+				OwnerAccountIndex:   nftInfo.OwnerAccountIndex,
+				ContentHash:         nftInfo.NftContentHash,
+				L1Address:           nftInfo.NftL1Address,
+				L1TokenId:           nftInfo.NftL1TokenId,
+				CreatorTreasuryRate: nftInfo.CreatorTreasuryRate,
+				CollectionId:        nftInfo.CollectionId,
+			}
+		}
+	}
+	return nil
+}
+
+func addAmount(amount string, delta *big.Int) string {
+	if delta == nil {
+		return amount
+	}
+	sum, _ := new(big.Int).SetString(amount, 10)
+	return sum.Add(sum, delta).String()
+}
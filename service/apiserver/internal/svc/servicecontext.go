@@ -7,6 +7,8 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"github.com/bnb-chain/zkbas/core/hook"
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
 	"github.com/bnb-chain/zkbas/dao/account"
 	"github.com/bnb-chain/zkbas/dao/asset"
 	"github.com/bnb-chain/zkbas/dao/block"
@@ -18,7 +20,9 @@ import (
 	"github.com/bnb-chain/zkbas/dao/tx"
 	"github.com/bnb-chain/zkbas/service/apiserver/internal/cache"
 	"github.com/bnb-chain/zkbas/service/apiserver/internal/config"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/fetcher/history"
 	"github.com/bnb-chain/zkbas/service/apiserver/internal/fetcher/price"
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/fetcher/snapshot"
 	"github.com/bnb-chain/zkbas/service/apiserver/internal/fetcher/state"
 )
 
@@ -40,8 +44,11 @@ type ServiceContext struct {
 	AssetModel            asset.AssetModel
 	SysConfigModel        sysconfig.SysConfigModel
 
-	PriceFetcher price.Fetcher
-	StateFetcher state.Fetcher
+	PriceFetcher    price.Fetcher
+	StateFetcher    state.Fetcher
+	HistoryFetcher  history.Fetcher
+	SnapshotFetcher snapshot.Fetcher
+	TxHook          hook.Hook
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
@@ -56,6 +63,12 @@ func NewServiceContext(c config.Config) *ServiceContext {
 	liquidityModel := liquidity.NewLiquidityModel(gormPointer)
 	nftModel := nft.NewL2NftModel(gormPointer)
 	assetModel := asset.NewAssetModel(gormPointer)
+	blockModel := block.NewBlockModel(gormPointer)
+	txHook, err := hook.NewHook(&c.TxHooks)
+	if err != nil {
+		logx.Must(err)
+	}
+	chainDb := sdb.NewChainDB(gormPointer)
 	memCache := cache.NewMemCache(accountModel, assetModel, c.MemCache.AccountExpiration, c.MemCache.BlockExpiration,
 		c.MemCache.TxExpiration, c.MemCache.AssetExpiration, c.MemCache.PriceExpiration)
 	return &ServiceContext{
@@ -70,12 +83,15 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		FailTxModel:           tx.NewFailTxModel(gormPointer),
 		LiquidityModel:        liquidityModel,
 		LiquidityHistoryModel: liquidity.NewLiquidityHistoryModel(gormPointer),
-		BlockModel:            block.NewBlockModel(gormPointer),
+		BlockModel:            blockModel,
 		NftModel:              nftModel,
 		AssetModel:            assetModel,
 		SysConfigModel:        sysconfig.NewSysConfigModel(gormPointer),
 
-		PriceFetcher: price.NewFetcher(memCache, c.CoinMarketCap.Url, c.CoinMarketCap.Token),
-		StateFetcher: state.NewFetcher(redisCache, accountModel, liquidityModel, nftModel),
+		PriceFetcher:    price.NewFetcher(memCache, c.CoinMarketCap.Url, c.CoinMarketCap.Token),
+		StateFetcher:    state.NewFetcher(redisCache, accountModel, liquidityModel, nftModel, blockModel),
+		HistoryFetcher:  history.NewFetcher(chainDb),
+		SnapshotFetcher: snapshot.NewFetcher(chainDb, c.SnapshotRefreshInterval),
+		TxHook:          txHook,
 	}
 }
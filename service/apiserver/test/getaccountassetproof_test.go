@@ -0,0 +1,83 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	"github.com/bnb-chain/zkbas/tree"
+)
+
+func (s *ApiServerSuite) TestGetAccountAssetProof() {
+	type args struct {
+		accountIndex uint32
+		assetId      uint32
+		blockHeight  int64
+	}
+
+	type testcase struct {
+		name     string
+		args     args
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"not found", args{math.MaxUint32, 0, 0}, 400},
+		{"invalid asset id", args{0, math.MaxUint32, 0}, 400},
+		{"not verified", args{0, 0, math.MaxInt64}, 400},
+	}
+
+	statusCode, accounts := GetAccounts(s, 0, 100)
+	if statusCode == http.StatusOK && len(accounts.Accounts) > 0 {
+		tests = append(tests, []testcase{
+			{"found", args{uint32(accounts.Accounts[0].Index), 0, 0}, 200},
+		}...)
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetAccountAssetProof(s, tt.args.accountIndex, tt.args.assetId, tt.args.blockHeight)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, result.Account.AssetRoot, common.Bytes2Hex(tree.ComputeMerkleRoot(
+					uint64(tt.args.assetId), common.FromHex(result.AssetProof.Leaf), fromHexes(result.AssetProof.Siblings))))
+				assert.Equal(t, result.Roots.AccountRoot, common.Bytes2Hex(tree.ComputeMerkleRoot(
+					uint64(tt.args.accountIndex), common.FromHex(result.AccountProof.Leaf), fromHexes(result.AccountProof.Siblings))))
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+}
+
+func GetAccountAssetProof(s *ApiServerSuite, accountIndex, assetId uint32, blockHeight int64) (int, *types.AccountAssetProof) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/accountAssetProof?account_index=%d&asset_id=%d&block_height=%d",
+		s.url, accountIndex, assetId, blockHeight))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.AccountAssetProof{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
+
+func fromHexes(hexes []string) [][]byte {
+	bytes := make([][]byte, 0, len(hexes))
+	for _, h := range hexes {
+		bytes = append(bytes, common.FromHex(h))
+	}
+	return bytes
+}
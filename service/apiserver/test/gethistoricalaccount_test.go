@@ -0,0 +1,80 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetHistoricalAccount() {
+	type args struct {
+		by          string
+		value       string
+		blockHeight int64
+	}
+
+	type testcase struct {
+		name     string
+		args     args
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"not found by index", args{"index", "9999999999", 1}, 400},
+		{"invalid by", args{"invalidby", "", 1}, 400},
+		{"invalid block height", args{"index", "0", -1}, 400},
+		{"block not created", args{"index", "0", math.MaxInt64}, 400},
+	}
+
+	statusCode, accounts := GetAccounts(s, 0, 100)
+	_, height := GetCurrentHeight(s)
+	if statusCode == http.StatusOK && len(accounts.Accounts) > 0 && height != nil && height.Height > 1 {
+		blockHeight := height.Height - 1
+		tests = append(tests, []testcase{
+			{"found by index", args{"index", strconv.Itoa(int(accounts.Accounts[0].Index)), blockHeight}, 200},
+			{"found by name", args{"name", accounts.Accounts[0].Name, blockHeight}, 200},
+			{"found by pk", args{"pk", accounts.Accounts[0].Pk, blockHeight}, 200},
+		}...)
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetHistoricalAccount(s, tt.args.by, tt.args.value, tt.args.blockHeight)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, tt.args.blockHeight, result.BlockHeight)
+				assert.NotNil(t, result.Account.Pk)
+				assert.NotNil(t, result.Account.Name)
+				assert.True(t, result.Account.Nonce >= 0)
+				assert.NotNil(t, result.Account.Assets)
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetHistoricalAccount(s *ApiServerSuite, by, value string, blockHeight int64) (int, *types.HistoricalAccount) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/historicalAccount?by=%s&value=%s&block_height=%d", s.url, by, value, blockHeight))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.HistoricalAccount{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
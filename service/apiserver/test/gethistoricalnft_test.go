@@ -0,0 +1,78 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetHistoricalNft() {
+	type args struct {
+		index       int64
+		blockHeight int64
+	}
+
+	type testcase struct {
+		name     string
+		args     args
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"not found", args{math.MaxInt64, 1}, 400},
+		{"invalid index", args{-1, 1}, 400},
+		{"invalid block height", args{0, -1}, 400},
+		{"block not created", args{0, math.MaxInt64}, 400},
+	}
+
+	statusCode, accounts := GetAccounts(s, 2, 100)
+	_, height := GetCurrentHeight(s)
+	if statusCode == http.StatusOK && len(accounts.Accounts) > 0 && height != nil && height.Height > 1 {
+		_, nfts := GetAccountNfts(s, "account_index", strconv.Itoa(int(accounts.Accounts[0].Index)), 0, 10)
+		if nfts != nil && len(nfts.Nfts) > 0 {
+			tests = append(tests, []testcase{
+				{"found by index", args{nfts.Nfts[0].Index, height.Height - 1}, 200},
+			}...)
+		}
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetHistoricalNft(s, tt.args.index, tt.args.blockHeight)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, tt.args.blockHeight, result.BlockHeight)
+				assert.Equal(t, tt.args.index, result.Nft.Index)
+				assert.NotNil(t, result.Nft.ContentHash)
+				assert.NotNil(t, result.Nft.OwnerAccountIndex)
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetHistoricalNft(s *ApiServerSuite, nftIndex int64, blockHeight int64) (int, *types.HistoricalNft) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/historicalNft?index=%d&block_height=%d", s.url, nftIndex, blockHeight))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.HistoricalNft{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+)
+
+func (s *ApiServerSuite) TestGetHistoricalPair() {
+	type args struct {
+		index       uint32
+		blockHeight int64
+	}
+
+	type testcase struct {
+		name     string
+		args     args
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"not found", args{math.MaxUint32, 1}, 400},
+		{"invalid block height", args{0, -1}, 400},
+		{"block not created", args{0, math.MaxInt64}, 400},
+	}
+
+	statusCode, pairs := GetPairs(s, 0, 100)
+	_, height := GetCurrentHeight(s)
+	if statusCode == http.StatusOK && len(pairs.Pairs) > 0 && height != nil && height.Height > 1 {
+		tests = append(tests, []testcase{
+			{"found by index", args{pairs.Pairs[0].Index, height.Height - 1}, 200},
+		}...)
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetHistoricalPair(s, tt.args.index, tt.args.blockHeight)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, tt.args.blockHeight, result.BlockHeight)
+				assert.Equal(t, tt.args.index, result.Pair.Index)
+				assert.NotNil(t, result.Pair.AssetAAmount)
+				assert.NotNil(t, result.Pair.AssetBAmount)
+				assert.NotNil(t, result.Pair.TotalLpAmount)
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+
+}
+
+func GetHistoricalPair(s *ApiServerSuite, pairIndex uint32, blockHeight int64) (int, *types.HistoricalPair) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/historicalPair?index=%d&block_height=%d", s.url, pairIndex, blockHeight))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.HistoricalPair{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
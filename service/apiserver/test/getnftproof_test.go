@@ -0,0 +1,68 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	"github.com/bnb-chain/zkbas/tree"
+)
+
+func (s *ApiServerSuite) TestGetNftProof() {
+	type testcase struct {
+		name     string
+		args     int64 //nft index
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"invalid nft index", -1, 400},
+		{"not found", 1<<40 - 1, 400},
+	}
+
+	statusCode, accounts := GetAccounts(s, 0, 100)
+	if statusCode == http.StatusOK {
+		for _, account := range accounts.Accounts {
+			statusCode, nfts := GetAccountNfts(s, "account_index", fmt.Sprint(account.Index), 0, 1)
+			if statusCode == http.StatusOK && len(nfts.Nfts) > 0 {
+				tests = append(tests, testcase{"found by index", nfts.Nfts[0].Index, 200})
+				break
+			}
+		}
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetNftProof(s, tt.args)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, result.Roots.NftRoot, common.Bytes2Hex(tree.ComputeMerkleRoot(
+					uint64(tt.args), common.FromHex(result.Proof.Leaf), fromHexes(result.Proof.Siblings))))
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+}
+
+func GetNftProof(s *ApiServerSuite, nftIndex int64) (int, *types.NftProof) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/nftProof?nft_index=%d", s.url, nftIndex))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.NftProof{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
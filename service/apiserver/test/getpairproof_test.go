@@ -0,0 +1,64 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/service/apiserver/internal/types"
+	"github.com/bnb-chain/zkbas/tree"
+)
+
+func (s *ApiServerSuite) TestGetPairProof() {
+	type testcase struct {
+		name     string
+		args     uint32 //pair index
+		httpCode int
+	}
+
+	tests := []testcase{
+		{"invalid pair index", math.MaxUint32, 400},
+	}
+
+	statusCode, pairs := GetPairs(s, 0, 100)
+	if statusCode == http.StatusOK && len(pairs.Pairs) > 0 {
+		tests = append(tests, []testcase{
+			{"found by index", pairs.Pairs[0].Index, 200},
+		}...)
+	}
+
+	for _, tt := range tests {
+		s.T().Run(tt.name, func(t *testing.T) {
+			httpCode, result := GetPairProof(s, tt.args)
+			assert.Equal(t, tt.httpCode, httpCode)
+			if httpCode == http.StatusOK {
+				assert.Equal(t, result.Roots.LiquidityRoot, common.Bytes2Hex(tree.ComputeMerkleRoot(
+					uint64(tt.args), common.FromHex(result.Proof.Leaf), fromHexes(result.Proof.Siblings))))
+				fmt.Printf("result: %+v \n", result)
+			}
+		})
+	}
+}
+
+func GetPairProof(s *ApiServerSuite, pairIndex uint32) (int, *types.PairProof) {
+	resp, err := http.Get(fmt.Sprintf("%s/api/v1/pairProof?pair_index=%d", s.url, pairIndex))
+	assert.NoError(s.T(), err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(s.T(), err)
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	result := types.PairProof{}
+	//nolint: errcheck
+	json.Unmarshal(body, &result)
+	return resp.StatusCode, &result
+}
@@ -1,8 +1,13 @@
 package committer
 
 import (
+	"context"
+	"os"
+	"os/signal"
+
 	"github.com/zeromicro/go-zero/core/conf"
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/proc"
 
 	"github.com/bnb-chain/zkbas/service/committer/committer"
 )
@@ -17,6 +22,17 @@ func Run(configFile string) error {
 		return err
 	}
 
-	committer.Run()
-	return nil
+	// SIGTERM is handled by go-zero, which kills the process if it is still alive after the timeout.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	proc.SetTimeToForceQuit(config.ShutdownTimeout)
+	proc.AddWrapUpListener(cancel)
+
+	err = committer.Run(ctx)
+	if err != nil && ctx.Err() != nil {
+		// The round being persisted is abandoned, it is restored from the executed txs on restart.
+		logx.Infof("committer stopped, current round abandoned: %v", err)
+		return nil
+	}
+	return err
 }
@@ -0,0 +1,45 @@
+package committer
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+)
+
+type CacheCheckConfig struct {
+	// the interval of checking the states cached in redis in background, 0 disables the checking
+	Interval time.Duration `json:",optional"`
+	// the number of the accounts, liquidity and nfts sampled in a check
+	Samples int `json:",default=100"`
+}
+
+// checkCache samples the states cached in redis against the database every interval until the context is done. The
+// mismatches are only reported, the cache can be repaired by the cache rebuild tool.
+func (c *Committer) checkCache(ctx context.Context, checker *sdb.CacheChecker) {
+	ticker := time.NewTicker(c.config.CacheCheck.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mismatches := 0
+			checked, err := checker.Sample(c.config.CacheCheck.Samples, func(mismatch *sdb.CacheMismatch) error {
+				mismatches++
+				logx.Errorf("inconsistent cache entry %s written at block %d tx %d: %s", mismatch.Key,
+					mismatch.BlockHeight, mismatch.TxIndex, mismatch.Reason)
+				return nil
+			})
+			if err != nil {
+				logx.Errorf("check redis cache failed: %v", err)
+				continue
+			}
+			if mismatches > 0 {
+				logx.Errorf("%d of %d cache entries checked are inconsistent, rebuild the cache", mismatches, checked)
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package committer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -8,13 +9,13 @@ import (
 	"github.com/zeromicro/go-zero/core/logx"
 
 	"github.com/bnb-chain/zkbas/core"
+	"github.com/bnb-chain/zkbas/core/hook"
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
 	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/dbcache"
 	"github.com/bnb-chain/zkbas/dao/mempool"
 	"github.com/bnb-chain/zkbas/dao/tx"
-)
-
-const (
-	MaxCommitterInterval = 60 * 1
+	"github.com/bnb-chain/zkbas/types"
 )
 
 type Config struct {
@@ -22,22 +23,46 @@ type Config struct {
 
 	BlockConfig struct {
 		OptionalBlockSizes []int
+		// the number of txs taken from the tx pool and verified in parallel, 0 or 1 means one by one
+		ParallelTxs int `json:",optional"`
+		SealPolicy  SealPolicyConfig
 	}
+
+	// the time given to the current round to be persisted after SIGTERM, before the process is killed
+	ShutdownTimeout time.Duration `json:",default=20s"`
+	RetryConfig     RetryConfig
+	//nolint:staticcheck
+	CacheCheck CacheCheckConfig `json:",optional"`
+	// the quotes of the gas assets, the gas fees of the txs are compared by their values in usd. The fees are only
+	// compared by their amounts with the decimals of the gas assets if it is not configured.
+	//nolint:staticcheck
+	CoinMarketCap CoinMarketCapConfig `json:",optional"`
+}
+
+// RetryConfig is the backoff of retrying the transient failures of the database or redis.
+type RetryConfig struct {
+	MinInterval time.Duration `json:",default=100ms"`
+	MaxInterval time.Duration `json:",default=10s"`
 }
 
 type Committer struct {
 	config             *Config
-	maxTxsPerBlock     int
 	optionalBlockSizes []int
+	sealPolicy         SealPolicy
 
-	bc *core.BlockChain
+	bc           *core.BlockChain
+	txPool       *TxPool
+	cacheChecker *sdb.CacheChecker
+	gasPrices    *gasPrices
 
 	executedMemPoolTxs []*mempool.MempoolTx
+	gasAssetDecimals   map[int64]uint32
 }
 
 func NewCommitter(config *Config) (*Committer, error) {
-	if len(config.BlockConfig.OptionalBlockSizes) == 0 {
-		return nil, errors.New("nil optional block sizes")
+	sealPolicy, err := NewSealPolicy(config.BlockConfig.SealPolicy, config.BlockConfig.OptionalBlockSizes)
+	if err != nil {
+		return nil, fmt.Errorf("new seal policy error: %v", err)
 	}
 
 	bc, err := core.NewBlockChain(&config.ChainConfig, "committer")
@@ -47,110 +72,258 @@ func NewCommitter(config *Config) (*Committer, error) {
 
 	committer := &Committer{
 		config:             config,
-		maxTxsPerBlock:     config.BlockConfig.OptionalBlockSizes[len(config.BlockConfig.OptionalBlockSizes)-1],
 		optionalBlockSizes: config.BlockConfig.OptionalBlockSizes,
+		sealPolicy:         sealPolicy,
 
 		bc: bc,
 
 		executedMemPoolTxs: make([]*mempool.MempoolTx, 0),
+		gasAssetDecimals:   make(map[int64]uint32),
+	}
+	committer.txPool = NewTxPool(committer.getAccountNonce, committer.getGasAsset)
+	if config.CoinMarketCap.Url != "" {
+		committer.gasPrices = newGasPrices(bc.L2AssetInfoModel, config.CoinMarketCap)
+	}
+	if config.CacheCheck.Interval > 0 {
+		redisCache := dbcache.NewRedisCache(config.CacheRedis[0].Host, config.CacheRedis[0].Pass, 15*time.Minute)
+		committer.cacheChecker = sdb.NewCacheChecker(bc.ChainDB, redisCache)
 	}
 	return committer, nil
 }
 
-func (c *Committer) Run() {
-	curBlock, err := c.restoreExecutedTxs()
+// Run produces blocks until the context is done. The txs executed in a round are persisted before the context
+// is checked, so a shutdown never leaves a half written round behind; a block which is being committed when the
+// context is done is either committed or abandoned, in which case it is rebuilt from the executed txs on restart.
+// Transient failures of the database or redis are retried with backoff, other failures are returned.
+func (c *Committer) Run(ctx context.Context) error {
+	c.bc.Pruner.Start()
+	defer c.bc.Pruner.Stop()
+	if c.cacheChecker != nil {
+		checkCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go c.checkCache(checkCtx, c.cacheChecker)
+	}
+	if c.gasPrices != nil {
+		priceCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go c.gasPrices.run(priceCtx)
+	}
+
+	var curBlock *block.Block
+	err := retry(ctx, c.config.RetryConfig, func() (err error) {
+		curBlock, err = c.restoreExecutedTxs()
+		return err
+	})
 	if err != nil {
-		panic("restore executed tx failed: " + err.Error())
+		return fmt.Errorf("restore executed txs failed: %v", err)
 	}
 
-	for {
+	for ctx.Err() == nil {
 		if curBlock.BlockStatus > block.StatusProposing {
 			curBlock, err = c.bc.ProposeNewBlock()
 			if err != nil {
-				panic("propose new block failed: " + err.Error())
+				return fmt.Errorf("propose new block failed: %v", err)
 			}
 		}
 
 		// Read pending transactions from mempool_tx table.
-		pendingTxs, err := c.bc.MempoolModel.GetMempoolTxsByStatus(mempool.PendingTxStatus)
+		pendingTxs, err := c.waitPendingTxs(ctx, curBlock)
 		if err != nil {
-			logx.Error("get pending transactions from mempool failed:", err)
-			return
-		}
-		for len(pendingTxs) == 0 {
-			if c.shouldCommit(curBlock) {
+			if ctx.Err() != nil {
 				break
 			}
-
-			time.Sleep(100 * time.Millisecond)
-			pendingTxs, err = c.bc.MempoolModel.GetMempoolTxsByStatus(mempool.PendingTxStatus)
-			if err != nil {
-				logx.Error("get pending transactions from mempool failed:", err)
-				return
-			}
+			return fmt.Errorf("get pending transactions from mempool failed: %v", err)
 		}
 
 		pendingUpdateMempoolTxs := make([]*mempool.MempoolTx, 0, len(pendingTxs))
 		pendingDeleteMempoolTxs := make([]*mempool.MempoolTx, 0, len(pendingTxs))
-		for _, mempoolTx := range pendingTxs {
-			if c.shouldCommit(curBlock) {
+		// the txs not screened as the tx hooks are unavailable, they are claimed and become pending again
+		releasedMempoolTxs := make([]*mempool.MempoolTx, 0)
+		for _, mempoolTx := range c.txPool.Sync(pendingTxs) {
+			logx.Errorf("drop mempool tx ID: %d, nonce %d is occupied by another tx", mempoolTx.ID, mempoolTx.Nonce)
+			mempoolTx.Status = mempool.FailTxStatus
+			pendingDeleteMempoolTxs = append(pendingDeleteMempoolTxs, mempoolTx)
+		}
+		expireTime := c.expireTime(curBlock)
+		expiredTxs := c.txPool.Expire(expireTime)
+		for _, mempoolTx := range expiredTxs {
+			logx.Infof("drop mempool tx ID: %d, tx or its predecessor is expired", mempoolTx.ID)
+			mempoolTx.Status = mempool.FailTxStatus
+			pendingDeleteMempoolTxs = append(pendingDeleteMempoolTxs, mempoolTx)
+		}
+		for ctx.Err() == nil {
+			if c.shouldCommit(curBlock, false) {
 				break
 			}
 
-			tx := convertMempoolTxToTx(mempoolTx)
-			err = c.bc.ApplyTransaction(tx)
+			mempoolTxs, staleTxs, err := c.nextTxs(curBlock)
+			if err != nil {
+				// The txs taken are still pending, they are synced into the tx pool again in the next round.
+				logx.Error("get next transaction from tx pool failed:", err)
+				break
+			}
+			for _, staleTx := range staleTxs {
+				logx.Errorf("drop mempool tx ID: %d, nonce %d is stale", staleTx.ID, staleTx.Nonce)
+				staleTx.Status = mempool.FailTxStatus
+				pendingDeleteMempoolTxs = append(pendingDeleteMempoolTxs, staleTx)
+			}
+			if len(mempoolTxs) == 0 {
+				break
+			}
+			err = retry(ctx, c.config.RetryConfig, func() (err error) {
+				mempoolTxs, err = c.claimTxs(mempoolTxs)
+				return recoverable("claim pending txs", err)
+			})
 			if err != nil {
-				logx.Errorf("apply mempool tx ID: %d failed, err %v ", mempoolTx.ID, err)
-				mempoolTx.Status = mempool.FailTxStatus
-				pendingDeleteMempoolTxs = append(pendingDeleteMempoolTxs, mempoolTx)
+				return err
+			}
+			if len(mempoolTxs) == 0 {
 				continue
 			}
-			mempoolTx.Status = mempool.ExecutedTxStatus
-			pendingUpdateMempoolTxs = append(pendingUpdateMempoolTxs, mempoolTx)
+
+			txs := make([]*tx.Tx, 0, len(mempoolTxs))
+			for _, mempoolTx := range mempoolTxs {
+				txs = append(txs, convertMempoolTxToTx(mempoolTx))
+			}
+			executedTxsBefore := len(c.bc.Statedb.Txs)
+			errs := c.bc.ApplyTransactions(txs)
+			releasedTxsBefore := len(releasedMempoolTxs)
+			for i, mempoolTx := range mempoolTxs {
+				if hook.IsUnavailable(errs[i]) {
+					logx.Errorf("apply mempool tx ID: %d failed, err %v, it is kept pending", mempoolTx.ID, errs[i])
+					mempoolTx.Status = mempool.PendingTxStatus
+					releasedMempoolTxs = append(releasedMempoolTxs, mempoolTx)
+					continue
+				}
+				if errs[i] != nil {
+					logx.Errorf("apply mempool tx ID: %d failed, err %v ", mempoolTx.ID, errs[i])
+					mempoolTx.Status = mempool.FailTxStatus
+					pendingDeleteMempoolTxs = append(pendingDeleteMempoolTxs, mempoolTx)
+					if rejectedErr, ok := errs[i].(*hook.RejectedError); ok {
+						c.recordFailTx(mempoolTx, rejectedErr.Reason)
+					}
+					continue
+				}
+				mempoolTx.Status = mempool.ExecutedTxStatus
+				c.txPool.Executed(mempoolTx)
+				pendingUpdateMempoolTxs = append(pendingUpdateMempoolTxs, mempoolTx)
+			}
 
 			// Write the proposed block into database when the first transaction executed.
-			if len(c.bc.Statedb.Txs) == 1 {
-				err = c.createNewBlock(curBlock)
+			if executedTxsBefore == 0 && len(c.bc.Statedb.Txs) > 0 {
+				err = retry(ctx, c.config.RetryConfig, func() error {
+					return recoverable("create new block", c.createNewBlock(curBlock))
+				})
 				if err != nil {
-					panic("create new block failed" + err.Error())
+					return err
 				}
 			}
+
+			// The tx hooks are unavailable, the txs are executed again in the next round.
+			if len(releasedMempoolTxs) > releasedTxsBefore {
+				break
+			}
 		}
 
-		err = c.bc.StateDB().SyncStateCacheToRedis()
+		err = retry(ctx, c.config.RetryConfig, func() error {
+			return recoverable("sync redis cache", c.bc.StateDB().SyncStateCacheToRedis(curBlock.BlockHeight))
+		})
 		if err != nil {
-			panic("sync redis cache failed: " + err.Error())
+			return err
 		}
 
-		err = c.bc.MempoolModel.UpdateMempoolTxs(pendingUpdateMempoolTxs, pendingDeleteMempoolTxs)
+		updatedMempoolTxs := make([]*mempool.MempoolTx, 0, len(pendingUpdateMempoolTxs)+len(releasedMempoolTxs))
+		updatedMempoolTxs = append(updatedMempoolTxs, pendingUpdateMempoolTxs...)
+		updatedMempoolTxs = append(updatedMempoolTxs, releasedMempoolTxs...)
+		err = retry(ctx, c.config.RetryConfig, func() error {
+			return recoverable("update mempool", c.bc.MempoolModel.UpdateMempoolTxs(updatedMempoolTxs, pendingDeleteMempoolTxs))
+		})
 		if err != nil {
-			panic("update mempool failed: " + err.Error())
+			return err
 		}
 		c.executedMemPoolTxs = append(c.executedMemPoolTxs, pendingUpdateMempoolTxs...)
+		c.recordExpiredTxs(expiredTxs, expireTime)
 
-		if c.shouldCommit(curBlock) {
-			curBlock, err = c.commitNewBlock(curBlock)
+		if c.shouldCommit(curBlock, true) {
+			curBlock, err = c.commitNewBlock(ctx, curBlock)
 			if err != nil {
-				panic("commit new block failed: " + err.Error())
+				return fmt.Errorf("commit new block failed: %v", err)
 			}
 		}
+
+		// Pending txs are all waiting for their predecessors, avoid polling the database in a busy loop.
+		if len(pendingUpdateMempoolTxs) == 0 {
+			_ = sleep(ctx, 100*time.Millisecond)
+		}
 	}
+
+	logx.Info("committer stopped")
+	return nil
+}
+
+// waitPendingTxs polls the pending txs from the mempool until there are some, or the current block should be
+// committed.
+func (c *Committer) waitPendingTxs(ctx context.Context, curBlock *block.Block) ([]*mempool.MempoolTx, error) {
+	for {
+		var pendingTxs []*mempool.MempoolTx
+		err := retry(ctx, c.config.RetryConfig, func() (err error) {
+			pendingTxs, err = c.bc.MempoolModel.GetMempoolTxsByStatus(mempool.PendingTxStatus)
+			return recoverable("get pending txs", err)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(pendingTxs) > 0 || c.shouldCommit(curBlock, true) {
+			return pendingTxs, nil
+		}
+
+		err = sleep(ctx, 100*time.Millisecond)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// nextTxs takes the txs applied together from the tx pool, together with the stale txs found on the way. At most
+// one tx of each account is taken, as the next one is not ready until the former one is executed.
+func (c *Committer) nextTxs(curBlock *block.Block) (mempoolTxs, staleTxs []*mempool.MempoolTx, err error) {
+	limit := c.config.BlockConfig.ParallelTxs
+	if limit < 1 {
+		limit = 1
+	}
+	if capacity := c.sealPolicy.Capacity(c.blockStats(curBlock, false)); limit > capacity {
+		limit = capacity
+	}
+
+	mempoolTxs = make([]*mempool.MempoolTx, 0, limit)
+	for len(mempoolTxs) < limit {
+		mempoolTx, stale, err := c.txPool.Next()
+		if err != nil {
+			return nil, nil, err
+		}
+		staleTxs = append(staleTxs, stale...)
+		if mempoolTx == nil {
+			break
+		}
+		mempoolTxs = append(mempoolTxs, mempoolTx)
+	}
+	return mempoolTxs, staleTxs, nil
 }
 
 func (c *Committer) restoreExecutedTxs() (*block.Block, error) {
 	bc := c.bc
 	curHeight, err := bc.BlockModel.GetCurrentHeight()
 	if err != nil {
-		return nil, err
+		return nil, recoverable("get current height", err)
 	}
 	curBlock, err := bc.BlockModel.GetBlockByHeight(curHeight)
 	if err != nil {
-		return nil, err
+		return nil, recoverable("get current block", err)
 	}
 
 	executedTxs, err := c.bc.MempoolModel.GetMempoolTxsByStatus(mempool.ExecutedTxStatus)
 	if err != nil {
-		return nil, err
+		return nil, recoverable("get executed txs", err)
 	}
 
 	if curBlock.BlockStatus > block.StatusProposing {
@@ -160,33 +333,151 @@ func (c *Committer) restoreExecutedTxs() (*block.Block, error) {
 		return curBlock, nil
 	}
 
+	// the txs are claimed before they are executed, the ones failed to be executed are dropped at the end of the
+	// round, or here if the committer stopped before that.
+	restoredTxs := make([]*mempool.MempoolTx, 0, len(executedTxs))
+	releasedTxs := make([]*mempool.MempoolTx, 0)
+	failedTxs := make([]*mempool.MempoolTx, 0)
+	// the txs following a released one of the same account can't be executed before it
+	releasedAccounts := make(map[int64]bool)
 	for _, mempoolTx := range executedTxs {
+		if types.IsL2Tx(mempoolTx.TxType) && releasedAccounts[mempoolTx.AccountIndex] {
+			mempoolTx.Status = mempool.PendingTxStatus
+			releasedTxs = append(releasedTxs, mempoolTx)
+			continue
+		}
 		tx := convertMempoolTxToTx(mempoolTx)
 		err = c.bc.ApplyTransaction(tx)
+		if hook.IsUnavailable(err) {
+			logx.Errorf("apply executed mempool tx ID: %d failed, err %v, it is kept pending", mempoolTx.ID, err)
+			mempoolTx.Status = mempool.PendingTxStatus
+			releasedTxs = append(releasedTxs, mempoolTx)
+			releasedAccounts[mempoolTx.AccountIndex] = true
+			continue
+		}
 		if err != nil {
-			return nil, err
+			logx.Errorf("apply executed mempool tx ID: %d failed, err %v ", mempoolTx.ID, err)
+			mempoolTx.Status = mempool.FailTxStatus
+			failedTxs = append(failedTxs, mempoolTx)
+			continue
+		}
+		restoredTxs = append(restoredTxs, mempoolTx)
+	}
+	if len(releasedTxs) > 0 || len(failedTxs) > 0 {
+		err = c.bc.MempoolModel.UpdateMempoolTxs(releasedTxs, failedTxs)
+		if err != nil {
+			return nil, recoverable("drop failed txs", err)
 		}
 	}
 
-	c.executedMemPoolTxs = append(c.executedMemPoolTxs, executedTxs...)
+	c.executedMemPoolTxs = append(c.executedMemPoolTxs, restoredTxs...)
 	return curBlock, nil
 }
 
+// claimTxs claims the txs taken from the tx pool in the mempool, the ones replaced or cancelled by their senders in
+// the meantime are skipped, their replacements are synced into the tx pool in the next round.
+func (c *Committer) claimTxs(mempoolTxs []*mempool.MempoolTx) ([]*mempool.MempoolTx, error) {
+	claimed, err := c.bc.MempoolModel.ClaimPendingMempoolTxs(mempoolTxs)
+	if err != nil {
+		return nil, err
+	}
+	if len(claimed) < len(mempoolTxs) {
+		logx.Infof("skip %d mempool txs replaced or cancelled by their senders", len(mempoolTxs)-len(claimed))
+	}
+	return claimed, nil
+}
+
+func (c *Committer) getAccountNonce(accountIndex int64) (int64, error) {
+	err := c.bc.StateDB().PrepareAccountsAndAssets([]int64{accountIndex}, nil)
+	if err != nil {
+		return 0, err
+	}
+	return c.bc.StateDB().GetCommittedNonce(accountIndex)
+}
+
+// getGasAsset returns the decimals of the gas asset and its price, every asset is priced 1 if the quotes are not
+// configured.
+func (c *Committer) getGasAsset(assetId int64) (uint32, float64, error) {
+	decimals, ok := c.gasAssetDecimals[assetId]
+	if !ok {
+		asset, err := c.bc.L2AssetInfoModel.GetAssetById(assetId)
+		if err != nil {
+			return 0, 0, err
+		}
+		decimals = asset.Decimals
+		c.gasAssetDecimals[assetId] = decimals
+	}
+	if c.gasPrices == nil {
+		return decimals, 1, nil
+	}
+	return decimals, c.gasPrices.price(assetId), nil
+}
+
+// expireTime returns the time before which the txs can not be included in the current block or
+// any later block anymore.
+func (c *Committer) expireTime(curBlock *block.Block) int64 {
+	if len(c.bc.Statedb.Txs) > 0 {
+		return curBlock.CreatedAt.UnixMilli()
+	}
+	return time.Now().UnixMilli()
+}
+
+func (c *Committer) recordExpiredTxs(expiredTxs []*mempool.MempoolTx, expireTime int64) {
+	for _, mempoolTx := range expiredTxs {
+		reason := fmt.Sprintf("tx expired at %d", mempoolTx.ExpiredAt)
+		if mempoolTx.ExpiredAt >= expireTime {
+			reason = "tx with lower nonce of the same account expired"
+		}
+		c.recordFailTx(mempoolTx, reason)
+	}
+}
+
+func (c *Committer) recordFailTx(mempoolTx *mempool.MempoolTx, reason string) {
+	failTx := &tx.FailTx{
+		TxHash:        mempoolTx.TxHash,
+		TxType:        mempoolTx.TxType,
+		GasFee:        mempoolTx.GasFee,
+		GasFeeAssetId: mempoolTx.GasFeeAssetId,
+		TxStatus:      tx.StatusFail,
+		AssetAId:      mempoolTx.AssetId,
+		AssetBId:      types.NilAssetId,
+		TxAmount:      mempoolTx.TxAmount,
+		NativeAddress: mempoolTx.NativeAddress,
+		TxInfo:        mempoolTx.TxInfo,
+		ExtraInfo:     reason,
+		Memo:          mempoolTx.Memo,
+	}
+	if err := c.bc.FailTxModel.CreateFailTx(failTx); err != nil {
+		logx.Errorf("create fail tx for mempool tx ID: %d failed, err %v", mempoolTx.ID, err)
+	}
+}
+
 func (c *Committer) createNewBlock(curBlock *block.Block) error {
 	return c.bc.BlockModel.CreateNewBlock(curBlock)
 }
 
-func (c *Committer) shouldCommit(curBlock *block.Block) bool {
-	var now = time.Now()
-	if (len(c.bc.Statedb.Txs) > 0 && now.Unix()-curBlock.CreatedAt.Unix() >= MaxCommitterInterval) ||
-		len(c.bc.Statedb.Txs) >= c.maxTxsPerBlock {
-		return true
-	}
+// shouldCommit reports whether the current block should be sealed, drained tells whether the txs executable right
+// now are all executed.
+func (c *Committer) shouldCommit(curBlock *block.Block, drained bool) bool {
+	return c.sealPolicy.ShouldSeal(c.blockStats(curBlock, drained))
+}
 
-	return false
+func (c *Committer) blockStats(curBlock *block.Block, drained bool) *BlockStats {
+	stats := &BlockStats{
+		Age:        time.Since(curBlock.CreatedAt),
+		Txs:        len(c.bc.Statedb.Txs),
+		WaitingTxs: c.txPool.Size(),
+		Drained:    drained,
+	}
+	for _, tx := range c.bc.Statedb.Txs {
+		if !types.IsL2Tx(tx.TxType) {
+			stats.PriorityTxs++
+		}
+	}
+	return stats
 }
 
-func (c *Committer) commitNewBlock(curBlock *block.Block) (*block.Block, error) {
+func (c *Committer) commitNewBlock(ctx context.Context, curBlock *block.Block) (*block.Block, error) {
 	for _, tx := range c.executedMemPoolTxs {
 		tx.Status = mempool.SuccessTxStatus
 	}
@@ -197,8 +488,10 @@ func (c *Committer) commitNewBlock(curBlock *block.Block) (*block.Block, error)
 		return nil, err
 	}
 
-	// Update database in a transaction.
-	err = c.bc.BlockModel.CreateCompressedBlock(c.executedMemPoolTxs, blockStates)
+	// Update database in a transaction. The block states are computed once, only the write is retried.
+	err = retry(ctx, c.config.RetryConfig, func() error {
+		return recoverable("create compressed block", c.bc.BlockModel.CreateCompressedBlock(c.executedMemPoolTxs, blockStates))
+	})
 	if err != nil {
 		return nil, err
 	}
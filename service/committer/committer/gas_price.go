@@ -0,0 +1,81 @@
+package committer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas/common/price"
+	"github.com/bnb-chain/zkbas/dao/asset"
+)
+
+type CoinMarketCapConfig struct {
+	// the url of the latest quotes followed by the symbol, empty disables the quotes
+	Url   string `json:",optional"`
+	Token string `json:",optional"`
+	// the interval of refreshing the prices of the gas assets in background
+	RefreshInterval time.Duration `json:",default=1m"`
+}
+
+// gasPrices keeps the prices in usd of the gas assets, they are refreshed in background so that the committer is
+// never blocked by coinmarketcap.
+type gasPrices struct {
+	assetModel asset.AssetModel
+	config     CoinMarketCapConfig
+
+	mu     sync.RWMutex
+	prices map[int64]float64 // asset id -> price
+}
+
+func newGasPrices(assetModel asset.AssetModel, config CoinMarketCapConfig) *gasPrices {
+	return &gasPrices{
+		assetModel: assetModel,
+		config:     config,
+		prices:     make(map[int64]float64),
+	}
+}
+
+// price returns the price of the gas asset, it is 0 if the asset is not quoted yet.
+func (g *gasPrices) price(assetId int64) float64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.prices[assetId]
+}
+
+// run refreshes the prices every interval until the context is done.
+func (g *gasPrices) run(ctx context.Context) {
+	ticker := time.NewTicker(g.config.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		g.refresh()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh quotes the gas assets one by one, the former price of an asset is kept if it fails to be quoted.
+func (g *gasPrices) refresh() {
+	assets, err := g.assetModel.GetGasAssets()
+	if err != nil {
+		logx.Errorf("get gas assets failed: %v", err)
+		return
+	}
+	for _, gasAsset := range assets {
+		p, err := price.GetCurrencyPrice(g.config.Url, g.config.Token, gasAsset.AssetSymbol)
+		if err != nil {
+			logx.Errorf("get price of gas asset %s failed: %v", gasAsset.AssetSymbol, err)
+			continue
+		}
+		if p == 0 {
+			logx.Errorf("gas asset %s is not quoted, its fees are ranked last", gasAsset.AssetSymbol)
+		}
+		g.mu.Lock()
+		g.prices[int64(gasAsset.AssetId)] = p
+		g.mu.Unlock()
+	}
+}
@@ -0,0 +1,112 @@
+package committer
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// the classes of the postgres errors which may succeed if the transaction is retried: the connection exceptions, the
+// serialization failures and deadlocks, and the shutdown of the server.
+var transientSQLStates = []string{"08", "40001", "40P01", "57P01", "57P02", "57P03"}
+
+// RecoverableError is a transient failure of the database or redis, the operation can be retried as is.
+type RecoverableError struct {
+	Op  string
+	Err error
+}
+
+func (e *RecoverableError) Error() string {
+	return fmt.Sprintf("%s failed: %v", e.Op, e.Err)
+}
+
+func (e *RecoverableError) Unwrap() error {
+	return e.Err
+}
+
+// recoverable marks the error of the operation as recoverable if it is transient, the other errors are returned
+// with the operation so that the committer fails.
+func recoverable(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if !isTransient(err) {
+		return fmt.Errorf("%s failed: %w", op, err)
+	}
+	return &RecoverableError{Op: op, Err: err}
+}
+
+// isTransient reports whether the error is a failure of the connection to the database or redis, a timeout, or a
+// serialization failure of a transaction.
+func isTransient(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if pgconn.Timeout(err) || pgconn.SafeToRetry(err) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		for _, state := range transientSQLStates {
+			if strings.HasPrefix(pgErr.Code, state) {
+				return true
+			}
+		}
+	}
+	// the pool of the redis client has no free connection in time.
+	return strings.Contains(err.Error(), "redis: connection pool timeout")
+}
+
+func IsRecoverable(err error) bool {
+	var recoverableErr *RecoverableError
+	return errors.As(err, &recoverableErr)
+}
+
+// retry calls fn until it succeeds, fails with an unrecoverable error, or the context is done. The interval
+// between two calls doubles from the min interval up to the max interval.
+func retry(ctx context.Context, config RetryConfig, fn func() error) error {
+	interval := config.MinInterval
+	for {
+		err := fn()
+		if err == nil || !IsRecoverable(err) {
+			return err
+		}
+
+		logx.Errorf("%v, retry in %v", err, interval)
+		if sleepErr := sleep(ctx, interval); sleepErr != nil {
+			return err
+		}
+		interval *= 2
+		if interval > config.MaxInterval {
+			interval = config.MaxInterval
+		}
+	}
+}
+
+// sleep waits for the duration, it returns early with the error of the context if the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package committer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/types"
+)
+
+func TestRetry(t *testing.T) {
+	config := RetryConfig{MinInterval: time.Millisecond, MaxInterval: 4 * time.Millisecond}
+	transientErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	// Recoverable errors are retried until the operation succeeds.
+	calls := 0
+	err := retry(context.Background(), config, func() error {
+		calls++
+		if calls < 4 {
+			return recoverable("query", transientErr)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, calls)
+
+	// Other errors are returned at once.
+	calls = 0
+	err = retry(context.Background(), config, func() error {
+		calls++
+		return transientErr
+	})
+	assert.Equal(t, transientErr, err)
+	assert.Equal(t, 1, calls)
+	calls = 0
+	err = retry(context.Background(), config, func() error {
+		calls++
+		return recoverable("query", types.DbErrMempoolTxNotPending)
+	})
+	assert.False(t, IsRecoverable(err))
+	assert.ErrorIs(t, err, types.DbErrMempoolTxNotPending)
+	assert.Equal(t, 1, calls)
+
+	// The last error is returned when the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = retry(ctx, config, func() error {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		return recoverable("query", transientErr)
+	})
+	assert.True(t, IsRecoverable(err))
+	assert.ErrorIs(t, err, transientErr)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRecoverable(t *testing.T) {
+	for _, err := range []error{
+		&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+		types.WrapDbErr(types.DbErrSqlOperation, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}),
+		types.WrapDbErr(types.DbErrSqlOperation, &pgconn.PgError{Code: "40001"}),
+		types.WrapDbErr(types.DbErrSqlOperation, &pgconn.PgError{Code: "08006"}),
+		fmt.Errorf("cache to redis failed: %w", context.DeadlineExceeded),
+		errors.New("redis: connection pool timeout"),
+	} {
+		assert.True(t, IsRecoverable(recoverable("query", err)), err.Error())
+	}
+
+	for _, err := range []error{
+		types.DbErrMempoolTxNotPending,
+		types.DbErrNotFound,
+		types.WrapDbErr(types.DbErrSqlOperation, &pgconn.PgError{Code: "23505"}),
+		errors.New("no new mempoolTx"),
+	} {
+		recoverableErr := recoverable("query", err)
+		assert.False(t, IsRecoverable(recoverableErr), err.Error())
+		assert.ErrorIs(t, recoverableErr, err)
+	}
+	assert.ErrorIs(t, types.WrapDbErr(types.DbErrSqlOperation, &pgconn.PgError{Code: "23505"}), types.DbErrSqlOperation)
+}
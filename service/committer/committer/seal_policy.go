@@ -0,0 +1,135 @@
+package committer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/std"
+)
+
+const pubDataBytesPerTx = 32 * std.PubDataSizePerTx
+
+type SealPolicyConfig struct {
+	// the time after which a block with txs is sealed
+	MaxAge time.Duration `json:",default=60s"`
+	// the max number of txs in a block, 0 means the largest optional block size
+	MaxTxs int `json:",optional"`
+	// the max bytes of the pub data of a block, padding included, 0 means no limit
+	MaxPubDataBytes int `json:",optional"`
+	// a block filling an optional block size is sealed if the next block size would be padded by more
+	// than the percentage with the txs waiting, 100 means never
+	MaxPaddingPercent int `json:",default=100"`
+	// seal the block once the txs ready are executed, if it contains priority txs from L1
+	SealPriorityTxs bool `json:",optional"`
+}
+
+// BlockStats is the state of the block being built which the seal policy decides on.
+type BlockStats struct {
+	Age         time.Duration
+	Txs         int
+	PriorityTxs int
+	// the txs waiting in the tx pool, which are not necessarily executable
+	WaitingTxs int
+	// whether the txs executable right now are all executed
+	Drained bool
+}
+
+// SealPolicy decides when the committer stops putting txs in a block and seals it.
+type SealPolicy interface {
+	// Capacity returns the number of txs which can still be put in the block.
+	Capacity(stats *BlockStats) int
+	// ShouldSeal reports whether the block should be sealed now.
+	ShouldSeal(stats *BlockStats) bool
+}
+
+type defaultSealPolicy struct {
+	config     SealPolicyConfig
+	blockSizes []int
+	maxTxs     int
+}
+
+func NewSealPolicy(config SealPolicyConfig, blockSizes []int) (SealPolicy, error) {
+	if len(blockSizes) == 0 {
+		return nil, errors.New("nil optional block sizes")
+	}
+	maxTxs := blockSizes[len(blockSizes)-1]
+	if config.MaxTxs > maxTxs {
+		return nil, fmt.Errorf("max txs %d is larger than the largest block size %d", config.MaxTxs, maxTxs)
+	}
+	if config.MaxTxs > 0 {
+		maxTxs = config.MaxTxs
+	}
+	if config.MaxPubDataBytes > 0 {
+		maxPubDataTxs := 0
+		for _, blockSize := range blockSizes {
+			if blockSize*pubDataBytesPerTx <= config.MaxPubDataBytes {
+				maxPubDataTxs = blockSize
+			}
+		}
+		if maxPubDataTxs == 0 {
+			return nil, fmt.Errorf("max pub data bytes %d is less than the pub data of the smallest block size %d",
+				config.MaxPubDataBytes, blockSizes[0])
+		}
+		if maxPubDataTxs < maxTxs {
+			maxTxs = maxPubDataTxs
+		}
+	}
+	if config.MaxPaddingPercent < 0 || config.MaxPaddingPercent > 100 {
+		return nil, fmt.Errorf("invalid max padding percent %d", config.MaxPaddingPercent)
+	}
+
+	return &defaultSealPolicy{
+		config:     config,
+		blockSizes: blockSizes,
+		maxTxs:     maxTxs,
+	}, nil
+}
+
+func (p *defaultSealPolicy) Capacity(stats *BlockStats) int {
+	capacity := p.maxTxs - stats.Txs
+	if capacity <= 0 {
+		return 0
+	}
+	if p.config.MaxPaddingPercent >= 100 {
+		return capacity
+	}
+
+	// Stop at the end of the current block size, where the next one is checked for the padding.
+	for i, blockSize := range p.blockSizes {
+		if blockSize < stats.Txs {
+			continue
+		}
+		if blockSize > stats.Txs {
+			return minInt(capacity, blockSize-stats.Txs)
+		}
+		if i == len(p.blockSizes)-1 {
+			return 0
+		}
+		nextBlockSize := p.blockSizes[i+1]
+		paddedTxs := nextBlockSize - minInt(stats.Txs+stats.WaitingTxs, nextBlockSize)
+		if paddedTxs*100 > nextBlockSize*p.config.MaxPaddingPercent {
+			return 0
+		}
+		return minInt(capacity, nextBlockSize-stats.Txs)
+	}
+	return 0
+}
+
+func (p *defaultSealPolicy) ShouldSeal(stats *BlockStats) bool {
+	if stats.Txs == 0 {
+		return false
+	}
+	if stats.Age >= p.config.MaxAge || p.Capacity(stats) == 0 {
+		return true
+	}
+	// The priority txs are served first, seal after the following ones are executed to pack them in one block.
+	return p.config.SealPriorityTxs && stats.PriorityTxs > 0 && stats.Drained
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,70 @@
+package committer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSealPolicy(t *testing.T, config SealPolicyConfig) SealPolicy {
+	if config.MaxAge == 0 {
+		config.MaxAge = time.Minute
+	}
+	if config.MaxPaddingPercent == 0 {
+		config.MaxPaddingPercent = 100
+	}
+	policy, err := NewSealPolicy(config, []int{1, 10, 100})
+	assert.NoError(t, err)
+	return policy
+}
+
+func TestSealPolicyLimits(t *testing.T) {
+	policy := newTestSealPolicy(t, SealPolicyConfig{})
+	assert.Equal(t, 100, policy.Capacity(&BlockStats{}))
+	assert.False(t, policy.ShouldSeal(&BlockStats{Age: time.Hour}))
+	assert.False(t, policy.ShouldSeal(&BlockStats{Age: time.Second, Txs: 99}))
+	assert.True(t, policy.ShouldSeal(&BlockStats{Age: time.Minute, Txs: 1}))
+	assert.True(t, policy.ShouldSeal(&BlockStats{Age: time.Second, Txs: 100}))
+
+	policy = newTestSealPolicy(t, SealPolicyConfig{MaxTxs: 50})
+	assert.Equal(t, 10, policy.Capacity(&BlockStats{Txs: 40}))
+	assert.True(t, policy.ShouldSeal(&BlockStats{Txs: 50}))
+
+	// A block of 100 txs has more pub data than allowed, it is sealed at 10 txs.
+	policy = newTestSealPolicy(t, SealPolicyConfig{MaxPubDataBytes: 50 * pubDataBytesPerTx})
+	assert.Equal(t, 1, policy.Capacity(&BlockStats{Txs: 9}))
+	assert.True(t, policy.ShouldSeal(&BlockStats{Txs: 10}))
+
+	_, err := NewSealPolicy(SealPolicyConfig{MaxTxs: 101}, []int{1, 10, 100})
+	assert.Error(t, err)
+	_, err = NewSealPolicy(SealPolicyConfig{MaxPubDataBytes: pubDataBytesPerTx - 1}, []int{1, 10, 100})
+	assert.Error(t, err)
+}
+
+func TestSealPolicyPadding(t *testing.T) {
+	policy := newTestSealPolicy(t, SealPolicyConfig{MaxPaddingPercent: 50})
+
+	// The txs are taken up to the end of the current block size.
+	assert.Equal(t, 1, policy.Capacity(&BlockStats{WaitingTxs: 20}))
+	assert.Equal(t, 7, policy.Capacity(&BlockStats{Txs: 3, WaitingTxs: 20}))
+	assert.False(t, policy.ShouldSeal(&BlockStats{Txs: 3}))
+
+	// The next block size is padded by 60% with the txs waiting.
+	assert.Equal(t, 0, policy.Capacity(&BlockStats{Txs: 1, WaitingTxs: 3}))
+	assert.True(t, policy.ShouldSeal(&BlockStats{Txs: 1, WaitingTxs: 3}))
+
+	// The next block size is padded by 50% with the txs waiting.
+	assert.Equal(t, 90, policy.Capacity(&BlockStats{Txs: 10, WaitingTxs: 40}))
+	assert.False(t, policy.ShouldSeal(&BlockStats{Txs: 10, WaitingTxs: 40}))
+}
+
+func TestSealPolicyPriorityTxs(t *testing.T) {
+	policy := newTestSealPolicy(t, SealPolicyConfig{})
+	assert.False(t, policy.ShouldSeal(&BlockStats{Txs: 2, PriorityTxs: 1, Drained: true}))
+
+	policy = newTestSealPolicy(t, SealPolicyConfig{SealPriorityTxs: true})
+	assert.False(t, policy.ShouldSeal(&BlockStats{Txs: 2, PriorityTxs: 1}))
+	assert.False(t, policy.ShouldSeal(&BlockStats{Txs: 2, Drained: true}))
+	assert.True(t, policy.ShouldSeal(&BlockStats{Txs: 2, PriorityTxs: 1, Drained: true}))
+}
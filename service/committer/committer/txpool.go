@@ -0,0 +1,251 @@
+package committer
+
+import (
+	"math/big"
+
+	"github.com/bnb-chain/zkbas/dao/mempool"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+type NonceFunc func(accountIndex int64) (int64, error)
+
+// GasAssetFunc returns the decimals of the gas asset and its price, the price is 0 if it is unknown.
+type GasAssetFunc func(assetId int64) (decimals uint32, price float64, err error)
+
+// TxPool is the in-memory view of the pending mempool txs used by the committer.
+// Priority operations from L1 are always served first and in arrival order. L2 txs
+// are grouped by account, and a tx is only executable once its nonce matches the
+// account's next nonce; among the executable txs the one paying the highest gas fee,
+// valued by the price of its gas asset, is served first. The fees in the assets whose
+// price is unknown are ranked last.
+type TxPool struct {
+	priorityTxs []*mempool.MempoolTx
+	accountTxs  map[int64]map[int64]*mempool.MempoolTx // account index -> nonce -> tx
+	txIds       map[uint]bool
+	// the next nonces of the accounts, read by the nonce func at the first use and
+	// advanced by Executed.
+	expectNonces map[int64]int64
+
+	nonce    NonceFunc
+	gasAsset GasAssetFunc
+}
+
+func NewTxPool(nonce NonceFunc, gasAsset GasAssetFunc) *TxPool {
+	return &TxPool{
+		priorityTxs:  make([]*mempool.MempoolTx, 0),
+		accountTxs:   make(map[int64]map[int64]*mempool.MempoolTx),
+		txIds:        make(map[uint]bool),
+		expectNonces: make(map[int64]int64),
+		nonce:        nonce,
+		gasAsset:     gasAsset,
+	}
+}
+
+// Sync reconciles the pool with the pending txs read from the database: new txs are
+// added, and txs which are no longer pending are removed. If two pending txs of one
+// account share the same nonce, only the one paying more gas is kept, the other one
+// is returned as dropped.
+func (p *TxPool) Sync(pendingTxs []*mempool.MempoolTx) (dropped []*mempool.MempoolTx) {
+	pendingIds := make(map[uint]bool, len(pendingTxs))
+	for _, mempoolTx := range pendingTxs {
+		pendingIds[mempoolTx.ID] = true
+	}
+	p.removeIf(func(mempoolTx *mempool.MempoolTx) bool {
+		return !pendingIds[mempoolTx.ID]
+	})
+
+	for _, mempoolTx := range pendingTxs {
+		if p.txIds[mempoolTx.ID] {
+			continue
+		}
+
+		if !types.IsL2Tx(mempoolTx.TxType) {
+			p.priorityTxs = append(p.priorityTxs, mempoolTx)
+			p.txIds[mempoolTx.ID] = true
+			continue
+		}
+
+		txs, ok := p.accountTxs[mempoolTx.AccountIndex]
+		if !ok {
+			txs = make(map[int64]*mempool.MempoolTx)
+			p.accountTxs[mempoolTx.AccountIndex] = txs
+		}
+		if existTx, ok := txs[mempoolTx.Nonce]; ok {
+			if p.compareGasFee(existTx, mempoolTx) >= 0 {
+				dropped = append(dropped, mempoolTx)
+				continue
+			}
+			delete(p.txIds, existTx.ID)
+			dropped = append(dropped, existTx)
+		}
+		txs[mempoolTx.Nonce] = mempoolTx
+		p.txIds[mempoolTx.ID] = true
+	}
+
+	return dropped
+}
+
+// Expire removes the L2 txs which expire before the given time in milliseconds, together with the txs
+// with higher nonces of the same accounts, which can never be executed without their predecessors.
+func (p *TxPool) Expire(now int64) (expired []*mempool.MempoolTx) {
+	for accountIndex, txs := range p.accountTxs {
+		expiredNonce := int64(-1)
+		for nonce, mempoolTx := range txs {
+			if mempoolTx.ExpiredAt < now && (expiredNonce < 0 || nonce < expiredNonce) {
+				expiredNonce = nonce
+			}
+		}
+		if expiredNonce < 0 {
+			continue
+		}
+
+		for nonce, mempoolTx := range txs {
+			if nonce >= expiredNonce {
+				expired = append(expired, mempoolTx)
+				delete(txs, nonce)
+				delete(p.txIds, mempoolTx.ID)
+			}
+		}
+		if len(txs) == 0 {
+			delete(p.accountTxs, accountIndex)
+		}
+	}
+	return expired
+}
+
+// Next pops the next executable tx, it returns nil if no tx is executable right now.
+// Txs whose nonce is already behind the account's nonce can never be executed, they
+// are removed from the pool and returned as stale.
+func (p *TxPool) Next() (next *mempool.MempoolTx, stale []*mempool.MempoolTx, err error) {
+	if len(p.priorityTxs) > 0 {
+		next = p.priorityTxs[0]
+		p.priorityTxs = p.priorityTxs[1:]
+		delete(p.txIds, next.ID)
+		return next, nil, nil
+	}
+
+	for accountIndex, txs := range p.accountTxs {
+		expectNonce, err := p.expectNonce(accountIndex)
+		if err != nil {
+			if err != types.DbErrNotFound {
+				return nil, stale, err
+			}
+			// The sender does not exist, none of its txs can be executed.
+			expectNonce = maxNonce(txs) + 1
+		}
+
+		for nonce, mempoolTx := range txs {
+			if nonce < expectNonce {
+				stale = append(stale, mempoolTx)
+				delete(txs, nonce)
+				delete(p.txIds, mempoolTx.ID)
+			}
+		}
+		if len(txs) == 0 {
+			delete(p.accountTxs, accountIndex)
+			continue
+		}
+
+		candidate, ok := txs[expectNonce]
+		if !ok {
+			continue
+		}
+		if next == nil || p.compareGasFee(candidate, next) > 0 ||
+			(p.compareGasFee(candidate, next) == 0 && candidate.ID < next.ID) {
+			next = candidate
+		}
+	}
+
+	if next != nil {
+		p.remove(next)
+	}
+	return next, stale, nil
+}
+
+// Executed advances the next nonce of the sender of an L2 tx which is executed, the
+// nonce is unchanged if the tx taken by Next fails to be executed.
+func (p *TxPool) Executed(mempoolTx *mempool.MempoolTx) {
+	if !types.IsL2Tx(mempoolTx.TxType) {
+		return
+	}
+	p.expectNonces[mempoolTx.AccountIndex] = mempoolTx.Nonce + 1
+}
+
+func (p *TxPool) Size() int {
+	return len(p.txIds)
+}
+
+// expectNonce returns the next nonce of the account, the accounts which do not exist
+// are not cached as they may be registered later.
+func (p *TxPool) expectNonce(accountIndex int64) (int64, error) {
+	if nonce, ok := p.expectNonces[accountIndex]; ok {
+		return nonce, nil
+	}
+	nonce, err := p.nonce(accountIndex)
+	if err != nil {
+		return 0, err
+	}
+	p.expectNonces[accountIndex] = nonce
+	return nonce, nil
+}
+
+func (p *TxPool) remove(mempoolTx *mempool.MempoolTx) {
+	p.removeIf(func(tx *mempool.MempoolTx) bool {
+		return tx.ID == mempoolTx.ID
+	})
+}
+
+func (p *TxPool) removeIf(cond func(mempoolTx *mempool.MempoolTx) bool) {
+	priorityTxs := p.priorityTxs[:0]
+	for _, mempoolTx := range p.priorityTxs {
+		if cond(mempoolTx) {
+			delete(p.txIds, mempoolTx.ID)
+			continue
+		}
+		priorityTxs = append(priorityTxs, mempoolTx)
+	}
+	p.priorityTxs = priorityTxs
+
+	for accountIndex, txs := range p.accountTxs {
+		for nonce, mempoolTx := range txs {
+			if cond(mempoolTx) {
+				delete(txs, nonce)
+				delete(p.txIds, mempoolTx.ID)
+			}
+		}
+		if len(txs) == 0 {
+			delete(p.accountTxs, accountIndex)
+		}
+	}
+}
+
+func (p *TxPool) compareGasFee(a, b *mempool.MempoolTx) int {
+	return p.gasFeeValue(a).Cmp(p.gasFeeValue(b))
+}
+
+// gasFeeValue converts the gas fee of a tx to its value by the price of the gas asset,
+// so that fees paid in different gas assets can be compared with each other.
+func (p *TxPool) gasFeeValue(mempoolTx *mempool.MempoolTx) *big.Float {
+	gasFee, ok := new(big.Float).SetString(mempoolTx.GasFee)
+	if !ok {
+		return new(big.Float)
+	}
+	decimals, price, err := p.gasAsset(mempoolTx.GasFeeAssetId)
+	if err != nil {
+		return new(big.Float)
+	}
+
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	value := gasFee.Quo(gasFee, unit)
+	return value.Mul(value, big.NewFloat(price))
+}
+
+func maxNonce(txs map[int64]*mempool.MempoolTx) int64 {
+	max := int64(-1)
+	for nonce := range txs {
+		if nonce > max {
+			max = nonce
+		}
+	}
+	return max
+}
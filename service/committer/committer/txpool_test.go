@@ -0,0 +1,209 @@
+package committer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbas/dao/mempool"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// newTestTxPool creates a tx pool where asset 1 has 6 decimals and the others have 18 decimals, the assets are priced
+// 1 unless they are in the prices.
+func newTestTxPool(nonces map[int64]int64, prices map[int64]float64) *TxPool {
+	return NewTxPool(func(accountIndex int64) (int64, error) {
+		nonce, ok := nonces[accountIndex]
+		if !ok {
+			return 0, types.DbErrNotFound
+		}
+		return nonce, nil
+	}, func(assetId int64) (uint32, float64, error) {
+		price, ok := prices[assetId]
+		if !ok {
+			price = 1
+		}
+		if assetId == 1 {
+			return 6, price, nil
+		}
+		return 18, price, nil
+	})
+}
+
+func newTestMempoolTx(id uint, txType int64, accountIndex, nonce int64, gasFeeAssetId int64, gasFee string) *mempool.MempoolTx {
+	return &mempool.MempoolTx{
+		Model:         gorm.Model{ID: id},
+		TxType:        txType,
+		AccountIndex:  accountIndex,
+		Nonce:         nonce,
+		GasFeeAssetId: gasFeeAssetId,
+		GasFee:        gasFee,
+	}
+}
+
+func TestTxPoolOrdering(t *testing.T) {
+	nonces := map[int64]int64{2: 0, 3: 5}
+	pool := newTestTxPool(nonces, nil)
+
+	dropped := pool.Sync([]*mempool.MempoolTx{
+		newTestMempoolTx(1, types.TxTypeTransfer, 2, 1, 0, "1000"),
+		newTestMempoolTx(2, types.TxTypeTransfer, 2, 0, 0, "10"),
+		newTestMempoolTx(3, types.TxTypeTransfer, 3, 5, 1, "1"),
+		newTestMempoolTx(4, types.TxTypeDeposit, 0, 0, 0, "0"),
+		newTestMempoolTx(5, types.TxTypeTransfer, 3, 4, 0, "1"),
+	})
+	assert.Empty(t, dropped)
+	assert.Equal(t, 5, pool.Size())
+
+	// Priority operations come first.
+	next, stale, err := pool.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), next.ID)
+	assert.Empty(t, stale)
+
+	// 1 unit of a 6 decimals asset pays more than 10 units of an 18 decimals asset.
+	next, stale, err = pool.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), next.ID)
+	assert.Len(t, stale, 1)
+	assert.Equal(t, uint(5), stale[0].ID)
+	pool.Executed(next)
+
+	// The tx with the highest fee of account 2 must wait for its predecessor.
+	next, _, err = pool.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), next.ID)
+	pool.Executed(next)
+
+	next, _, err = pool.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), next.ID)
+
+	next, _, err = pool.Next()
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+	assert.Equal(t, 0, pool.Size())
+}
+
+func TestTxPoolHoldFutureNonce(t *testing.T) {
+	nonces := map[int64]int64{2: 0}
+	pool := newTestTxPool(nonces, nil)
+
+	pool.Sync([]*mempool.MempoolTx{
+		newTestMempoolTx(1, types.TxTypeTransfer, 2, 1, 0, "10"),
+	})
+	next, stale, err := pool.Next()
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+	assert.Empty(t, stale)
+	assert.Equal(t, 1, pool.Size())
+
+	// The predecessor arrives later, both txs become executable in nonce order.
+	dropped := pool.Sync([]*mempool.MempoolTx{
+		newTestMempoolTx(1, types.TxTypeTransfer, 2, 1, 0, "10"),
+		newTestMempoolTx(2, types.TxTypeTransfer, 2, 0, 0, "1"),
+		newTestMempoolTx(3, types.TxTypeTransfer, 2, 0, 0, "5"),
+	})
+	assert.Len(t, dropped, 1)
+	assert.Equal(t, uint(2), dropped[0].ID)
+
+	next, _, err = pool.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), next.ID)
+	pool.Executed(next)
+
+	next, _, err = pool.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), next.ID)
+}
+
+func TestTxPoolGasFeeValue(t *testing.T) {
+	// asset 0 is worth 300 and asset 1 is worth 1, asset 2 is not quoted.
+	pool := newTestTxPool(map[int64]int64{2: 0, 3: 0, 4: 0}, map[int64]float64{0: 300, 1: 1, 2: 0})
+
+	pool.Sync([]*mempool.MempoolTx{
+		newTestMempoolTx(1, types.TxTypeTransfer, 2, 0, 1, "100000000"),
+		newTestMempoolTx(2, types.TxTypeTransfer, 3, 0, 0, "1000000000000000000"),
+		newTestMempoolTx(3, types.TxTypeTransfer, 4, 0, 2, "1000000000000000000000"),
+	})
+
+	// 1 unit of asset 0 is worth more than 100 units of asset 1, the fee in asset 2 is ranked last.
+	for _, id := range []uint{2, 1, 3} {
+		next, _, err := pool.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, id, next.ID)
+	}
+}
+
+func TestTxPoolCacheNonce(t *testing.T) {
+	nonces := map[int64]int64{2: 0}
+	calls := 0
+	pool := NewTxPool(func(accountIndex int64) (int64, error) {
+		calls++
+		return nonces[accountIndex], nil
+	}, func(int64) (uint32, float64, error) {
+		return 18, 1, nil
+	})
+
+	pool.Sync([]*mempool.MempoolTx{
+		newTestMempoolTx(1, types.TxTypeTransfer, 2, 0, 0, "10"),
+		newTestMempoolTx(2, types.TxTypeTransfer, 2, 1, 0, "10"),
+	})
+	next, _, err := pool.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), next.ID)
+	executed := next
+
+	// The successor waits until the tx is executed, the nonce is not read again.
+	next, _, err = pool.Next()
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+	pool.Executed(executed)
+
+	next, _, err = pool.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), next.ID)
+	assert.Equal(t, 1, calls)
+}
+
+func TestTxPoolSyncRemovesNonPendingTxs(t *testing.T) {
+	pool := newTestTxPool(map[int64]int64{2: 0}, nil)
+
+	pool.Sync([]*mempool.MempoolTx{
+		newTestMempoolTx(1, types.TxTypeTransfer, 2, 1, 0, "10"),
+		newTestMempoolTx(2, types.TxTypeDeposit, 0, 0, 0, "0"),
+	})
+	assert.Equal(t, 2, pool.Size())
+
+	pool.Sync([]*mempool.MempoolTx{})
+	assert.Equal(t, 0, pool.Size())
+}
+
+func TestTxPoolExpire(t *testing.T) {
+	pool := newTestTxPool(map[int64]int64{2: 0, 3: 0}, nil)
+
+	txs := []*mempool.MempoolTx{
+		newTestMempoolTx(1, types.TxTypeTransfer, 2, 0, 0, "10"),
+		newTestMempoolTx(2, types.TxTypeTransfer, 2, 1, 0, "10"),
+		newTestMempoolTx(3, types.TxTypeTransfer, 2, 2, 0, "10"),
+		newTestMempoolTx(4, types.TxTypeTransfer, 3, 0, 0, "10"),
+		newTestMempoolTx(5, types.TxTypeDeposit, 0, 0, 0, "0"),
+	}
+	for _, tx := range txs {
+		tx.ExpiredAt = 2000
+	}
+	txs[1].ExpiredAt = 1000
+	pool.Sync(txs)
+
+	// The successors of an expired tx are removed as well.
+	expired := pool.Expire(1500)
+	assert.Len(t, expired, 2)
+	assert.ElementsMatch(t, []uint{2, 3}, []uint{expired[0].ID, expired[1].ID})
+	assert.Equal(t, 3, pool.Size())
+
+	// Priority operations never expire.
+	expired = pool.Expire(2500)
+	assert.Len(t, expired, 2)
+	assert.Equal(t, 1, pool.Size())
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type Config struct {
+	// the address the grpc server listens on
+	ListenOn string
+	Postgres struct {
+		DataSource string
+	}
+	LogConf logx.LogConf
+	KeyPath struct {
+		VerifyingKeyPath []string
+	}
+	BlockConfig struct {
+		OptionalBlockSizes []int
+	}
+	// the duration a witness is leased to the prover, it is claimed by the others once expired
+	LeaseDuration time.Duration `json:",optional"`
+	// the witness is marked as failed once its proofs failed the times, 3 by default
+	MaxProofFailures int `json:",optional"`
+	TLS              struct {
+		CertFile string
+		KeyFile  string
+		// the ca of the certificates of the provers, they must present one for mutual tls if it is configured
+		ClientCAFile string `json:",optional"`
+	}
+	// the provers allowed to fetch the jobs, each of them authenticates with its own token
+	Provers []ProverConfig
+}
+
+type ProverConfig struct {
+	Id    string
+	Token string
+}
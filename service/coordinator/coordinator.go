@@ -0,0 +1,81 @@
+package coordinator
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/proc"
+	"google.golang.org/grpc"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbas/common/prove"
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/blockwitness"
+	"github.com/bnb-chain/zkbas/dao/proof"
+	"github.com/bnb-chain/zkbas/service/coordinator/config"
+	"github.com/bnb-chain/zkbas/service/coordinator/coordinator"
+	"github.com/bnb-chain/zkbas/service/coordinator/pb"
+)
+
+func Run(configFile string) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		return fmt.Errorf("gorm connect db error, err: %v", err)
+	}
+	if len(c.KeyPath.VerifyingKeyPath) != len(c.BlockConfig.OptionalBlockSizes) {
+		return fmt.Errorf("the verifying keys don't match the block sizes")
+	}
+	verifyingKeys := make(map[int]groth16.VerifyingKey, len(c.BlockConfig.OptionalBlockSizes))
+	for i, blockSize := range c.BlockConfig.OptionalBlockSizes {
+		verifyingKeys[blockSize], err = prove.LoadVerifyingKey(c.KeyPath.VerifyingKeyPath[i])
+		if err != nil {
+			return fmt.Errorf("load verifying key %s error: %v", c.KeyPath.VerifyingKeyPath[i], err)
+		}
+	}
+	co := coordinator.NewCoordinator(blockwitness.NewBlockWitnessModel(db), proof.NewProofModel(db),
+		block.NewBlockModel(db), c.LeaseDuration, c.MaxProofFailures, func(blockSize int) (groth16.VerifyingKey, error) {
+			verifyingKey, ok := verifyingKeys[blockSize]
+			if !ok {
+				return nil, fmt.Errorf("can't find correct vk for block size %d", blockSize)
+			}
+			return verifyingKey, nil
+		})
+
+	listener, err := net.Listen("tcp", c.ListenOn)
+	if err != nil {
+		return fmt.Errorf("listen on %s error: %v", c.ListenOn, err)
+	}
+	creds, err := coordinator.NewServerCredentials(c.TLS.CertFile, c.TLS.KeyFile, c.TLS.ClientCAFile)
+	if err != nil {
+		return err
+	}
+	tokens := make(map[string]string, len(c.Provers))
+	for _, prover := range c.Provers {
+		if prover.Id == "" || prover.Token == "" {
+			return fmt.Errorf("the id and the token of the provers are required")
+		}
+		tokens[prover.Id] = prover.Token
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("no prover is configured")
+	}
+	server := grpc.NewServer(grpc.Creds(creds),
+		grpc.UnaryInterceptor(coordinator.NewProverAuth(tokens).UnaryInterceptor))
+	pb.RegisterCoordinatorServer(server, coordinator.NewServer(co))
+	proc.AddShutdownListener(func() {
+		server.GracefulStop()
+		logx.Close()
+	})
+
+	logx.Infof("coordinator is listening on %s", c.ListenOn)
+	return server.Serve(listener)
+}
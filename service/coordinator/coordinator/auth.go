@@ -0,0 +1,127 @@
+package coordinator
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// the metadata key of the token of the prover, the value is "Bearer <token>".
+const authorizationKey = "authorization"
+
+// NewServerCredentials loads the certificate of the coordinator. The provers must present the certificates signed by
+// the client ca, i.e. mutual tls, if it is not empty.
+func NewServerCredentials(certFile, keyFile, clientCAFile string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load certificate %s error: %v", certFile, err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		config.ClientCAs, err = loadCertPool(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// NewClientCredentials loads the ca of the coordinator's certificate, the certificate of the prover is presented for
+// mutual tls if it is not empty.
+func NewClientCredentials(caFile, certFile, keyFile string) (credentials.TransportCredentials, error) {
+	rootCAs, err := loadCertPool(caFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		RootCAs:    rootCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load certificate %s error: %v", certFile, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(config), nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read ca %s error: %v", file, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(buf) {
+		return nil, fmt.Errorf("no certificate in ca %s", file)
+	}
+	return pool, nil
+}
+
+// ProverAuth authenticates the provers by their own tokens, a prover only fetches and submits the jobs with its id.
+type ProverAuth struct {
+	tokens map[string]string // prover id -> token
+}
+
+func NewProverAuth(tokens map[string]string) *ProverAuth {
+	return &ProverAuth{
+		tokens: tokens,
+	}
+}
+
+// UnaryInterceptor rejects the calls whose token is not the one of the prover id in the request.
+func (a *ProverAuth) UnaryInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	r, ok := req.(interface{ GetProverId() string })
+	if !ok {
+		return nil, status.Error(codes.PermissionDenied, "request without prover id")
+	}
+	if !a.authenticate(ctx, r.GetProverId()) {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token of prover %s", r.GetProverId())
+	}
+	return handler(ctx, req)
+}
+
+func (a *ProverAuth) authenticate(ctx context.Context, proverId string) bool {
+	expected, ok := a.tokens[proverId]
+	if !ok || expected == "" {
+		return false
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	for _, value := range md.Get(authorizationKey) {
+		token := strings.TrimPrefix(value, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenCredentials attaches the token of the prover to the calls, it is only sent over tls.
+type TokenCredentials string
+
+func (t TokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{authorizationKey: "Bearer " + string(t)}, nil
+}
+
+func (t TokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
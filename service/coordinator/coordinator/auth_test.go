@@ -0,0 +1,118 @@
+package coordinator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/bnb-chain/zkbas/dao/proof"
+	"github.com/bnb-chain/zkbas/service/coordinator/pb"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// writeCert writes the certificate signed by the parent, or a self-signed ca if the parent is nil, and its key.
+func writeCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey,
+) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name+".crt"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name+".key"),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return cert, key
+}
+
+func TestProverAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := writeCert(t, dir, "ca", nil, nil)
+	writeCert(t, dir, "bufnet", ca, caKey)
+	writeCert(t, dir, "prover", ca, caKey)
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	co := NewCoordinator(&testBlockWitnessModel{}, &testProofModel{proofs: map[int64]*proof.Proof{}}, &testBlockModel{},
+		time.Minute, 0, func(int) (groth16.VerifyingKey, error) {
+			return nil, nil
+		})
+	serverCreds, err := NewServerCredentials(path("bufnet.crt"), path("bufnet.key"), path("ca.crt"))
+	assert.NoError(t, err)
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(grpc.Creds(serverCreds), grpc.UnaryInterceptor(NewProverAuth(map[string]string{
+		"prover": "token",
+		"other":  "other-token",
+	}).UnaryInterceptor))
+	pb.RegisterCoordinatorServer(server, NewServer(co))
+	go server.Serve(listener) //nolint:errcheck
+	defer server.Stop()
+
+	dial := func(creds credentials.TransportCredentials, token string) *Client {
+		conn, err := grpc.Dial("bufnet", grpc.WithTransportCredentials(creds),
+			grpc.WithPerRPCCredentials(TokenCredentials(token)),
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+				return listener.Dial()
+			}))
+		assert.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return NewClient(pb.NewCoordinatorClient(conn))
+	}
+	clientCreds, err := NewClientCredentials(path("ca.crt"), path("prover.crt"), path("prover.key"))
+	assert.NoError(t, err)
+
+	// the prover with its own token is served.
+	_, err = dial(clientCreds, "token").FetchJob("prover", nil)
+	assert.Equal(t, types.DbErrNotFound, err)
+
+	// the token of the other prover or an unknown one is rejected.
+	_, err = dial(clientCreds, "other-token").FetchJob("prover", nil)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = dial(clientCreds, "token").Heartbeat("other", 1)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = dial(clientCreds, "token").FetchJob("unknown", nil)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, codes.Unauthenticated, status.Code(dial(clientCreds, "").SubmitProof("prover", 1, "")))
+
+	// the prover without the certificate signed by the client ca is rejected by mutual tls.
+	noCertCreds, err := NewClientCredentials(path("ca.crt"), "", "")
+	assert.NoError(t, err)
+	_, err = dial(noCertCreds, "token").FetchJob("prover", nil)
+	assert.Error(t, err)
+	assert.NotEqual(t, types.DbErrNotFound, err)
+}
@@ -0,0 +1,90 @@
+package coordinator
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bnb-chain/zkbas/dao/blockwitness"
+	"github.com/bnb-chain/zkbas/service/coordinator/pb"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// the timeout of the calls to the remote coordinator
+const callTimeout = time.Minute
+
+// Client calls the remote coordinator, it returns the same errors as Coordinator.
+type Client struct {
+	cli pb.CoordinatorClient
+}
+
+func NewClient(cli pb.CoordinatorClient) *Client {
+	return &Client{
+		cli: cli,
+	}
+}
+
+func (c *Client) FetchJob(proverId string, blockSizes []int) (*blockwitness.BlockWitness, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	req := &pb.ReqFetchJob{ProverId: proverId}
+	for _, blockSize := range blockSizes {
+		req.BlockSizes = append(req.BlockSizes, int32(blockSize))
+	}
+	resp, err := c.cli.FetchJob(ctx, req)
+	if err != nil {
+		return nil, fromStatus(err)
+	}
+	leaseExpiresAt := time.UnixMilli(resp.LeaseExpiresAt)
+	return &blockwitness.BlockWitness{
+		Height:         resp.BlockHeight,
+		WitnessData:    resp.WitnessData,
+		Status:         blockwitness.StatusReceived,
+		Prover:         proverId,
+		LeaseExpiresAt: &leaseExpiresAt,
+	}, nil
+}
+
+func (c *Client) Heartbeat(proverId string, height int64) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	resp, err := c.cli.Heartbeat(ctx, &pb.ReqHeartbeat{ProverId: proverId, BlockHeight: height})
+	if err != nil {
+		return time.Time{}, fromStatus(err)
+	}
+	return time.UnixMilli(resp.LeaseExpiresAt), nil
+}
+
+// ReleaseJob does nothing, the witness is claimed by the others once its lease is expired.
+func (c *Client) ReleaseJob(string, int64) error {
+	return nil
+}
+
+func (c *Client) SubmitProof(proverId string, height int64, proofInfo string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	_, err := c.cli.SubmitProof(ctx, &pb.ReqSubmitProof{
+		ProverId:    proverId,
+		BlockHeight: height,
+		ProofInfo:   proofInfo,
+	})
+	if status.Code(err) == codes.InvalidArgument {
+		return ErrInvalidProof
+	}
+	return fromStatus(err)
+}
+
+func fromStatus(err error) error {
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.NotFound:
+		return types.DbErrNotFound
+	case codes.FailedPrecondition:
+		return types.DbErrBlockWitnessLeaseLost
+	default:
+		return err
+	}
+}
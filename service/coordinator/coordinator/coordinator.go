@@ -0,0 +1,171 @@
+package coordinator
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas/common/prove"
+	blockdao "github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/blockwitness"
+	"github.com/bnb-chain/zkbas/dao/proof"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+const (
+	DefaultLeaseDuration = time.Minute
+	// the witness is marked as failed once its proofs failed to be verified the times
+	DefaultMaxProofFailures = 3
+)
+
+var ErrInvalidProof = errors.New("invalid proof")
+
+// VerifyingKeyFunc returns the verifying key of the block size.
+type VerifyingKeyFunc func(blockSize int) (groth16.VerifyingKey, error)
+
+// Coordinator leases the block witnesses to the provers and stores the proofs they generate. The witnesses are
+// claimed with leases, see BlockWitnessModel.ClaimBlockWitness, so the provers prove different blocks in parallel.
+type Coordinator struct {
+	blockWitnessModel blockwitness.BlockWitnessModel
+	proofModel        proof.ProofModel
+	blockModel        blockdao.BlockModel
+	leaseDuration     time.Duration
+	maxProofFailures  int
+	verifyingKey      VerifyingKeyFunc
+}
+
+func NewCoordinator(blockWitnessModel blockwitness.BlockWitnessModel, proofModel proof.ProofModel,
+	blockModel blockdao.BlockModel, leaseDuration time.Duration, maxProofFailures int, verifyingKey VerifyingKeyFunc) *Coordinator {
+	if leaseDuration == 0 {
+		leaseDuration = DefaultLeaseDuration
+	}
+	if maxProofFailures == 0 {
+		maxProofFailures = DefaultMaxProofFailures
+	}
+	return &Coordinator{
+		blockWitnessModel: blockWitnessModel,
+		proofModel:        proofModel,
+		blockModel:        blockModel,
+		leaseDuration:     leaseDuration,
+		maxProofFailures:  maxProofFailures,
+		verifyingKey:      verifyingKey,
+	}
+}
+
+// FetchJob claims the lowest witness of the block sizes not proved for the prover, DbErrNotFound is returned if there
+// is none. The witnesses proved by the provers whose leases are expired are marked as proved and skipped.
+func (c *Coordinator) FetchJob(proverId string, blockSizes []int) (*blockwitness.BlockWitness, error) {
+	for {
+		blockWitness, err := c.blockWitnessModel.ClaimBlockWitness(proverId, time.Now().Add(c.leaseDuration),
+			blockSizes)
+		if err != nil {
+			return nil, err
+		}
+		_, err = c.proofModel.GetProofByBlockNumber(blockWitness.Height)
+		if err == types.DbErrNotFound {
+			return blockWitness, nil
+		}
+		if err != nil {
+			res := c.blockWitnessModel.ReleaseBlockWitness(blockWitness.Height, proverId)
+			if res != nil {
+				logx.Errorf("release block witness %d failed, err %v", blockWitness.Height, res)
+			}
+			return nil, err
+		}
+		logx.Infof("blockProof of height %d exists", blockWitness.Height)
+		err = c.blockWitnessModel.CompleteBlockWitness(blockWitness.Height)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Heartbeat renews the lease of the witness held by the prover, DbErrBlockWitnessLeaseLost is returned if the
+// witness is claimed by another prover.
+func (c *Coordinator) Heartbeat(proverId string, height int64) (time.Time, error) {
+	leaseExpiresAt := time.Now().Add(c.leaseDuration)
+	err := c.blockWitnessModel.RenewBlockWitnessLease(height, proverId, leaseExpiresAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return leaseExpiresAt, nil
+}
+
+// ReleaseJob releases the witness held by the prover, so it can be claimed by the others at once.
+func (c *Coordinator) ReleaseJob(proverId string, height int64) error {
+	return c.blockWitnessModel.ReleaseBlockWitness(height, proverId)
+}
+
+// SubmitProof verifies the proof of the witness against the public inputs of the block and stores it. The proof is
+// accepted even if the lease of the prover is lost, unless the witness is proved by another one. If the proof is not
+// verified, ErrInvalidProof is returned, and the witness is released if the prover holds its lease, see failProof.
+// The witness differing from the committed blocks is marked as failed at once, its proofs are reverted by the rollup
+// contract anyway.
+func (c *Coordinator) SubmitProof(proverId string, height int64, proofInfo string) error {
+	blockWitness, err := c.blockWitnessModel.GetBlockWitnessByNumber(height)
+	if err != nil {
+		return err
+	}
+	var cryptoBlock *block.Block
+	err = json.Unmarshal([]byte(blockWitness.WitnessData), &cryptoBlock)
+	if err != nil {
+		return err
+	}
+	err = prove.VerifyWitnessBlock(c.blockModel, height, cryptoBlock)
+	if errors.Is(err, prove.ErrWitnessMismatch) {
+		logx.Errorf("block witness %d proved by prover %s is invalid: %v", height, proverId, err)
+		return c.failProof(proverId, height, 1)
+	}
+	if err != nil {
+		return err
+	}
+	verifyingKey, err := c.verifyingKey(len(cryptoBlock.Txs))
+	if err != nil {
+		return err
+	}
+	err = prove.VerifyProofInfo(proofInfo, verifyingKey, cryptoBlock)
+	if err != nil {
+		logx.Errorf("proof of height %d submitted by prover %s is invalid: %v", height, proverId, err)
+		return c.failProof(proverId, height, c.maxProofFailures)
+	}
+
+	_, err = c.proofModel.GetProofByBlockNumber(height)
+	if err == nil {
+		logx.Errorf("blockProof of height %d exists", height)
+		return c.blockWitnessModel.CompleteBlockWitness(height)
+	}
+	if err != types.DbErrNotFound {
+		return err
+	}
+	err = c.proofModel.CreateProof(&proof.Proof{
+		ProofInfo:   proofInfo,
+		BlockNumber: height,
+		Status:      proof.NotSent,
+		Prover:      proverId,
+	})
+	if err != nil {
+		return err
+	}
+	return c.blockWitnessModel.CompleteBlockWitness(height)
+}
+
+// failProof releases the witness whose proof of the prover holding its lease failed, it is marked as StatusProofFailed
+// once its proofs failed maxFailures times. The invalid proofs of the other provers are rejected only, so they can't
+// fail the witness held by another one.
+func (c *Coordinator) failProof(proverId string, height int64, maxFailures int) error {
+	failed, err := c.blockWitnessModel.FailBlockWitnessProof(height, proverId, maxFailures)
+	if err == types.DbErrBlockWitnessLeaseLost {
+		return ErrInvalidProof
+	}
+	if err != nil {
+		return err
+	}
+	if failed {
+		logx.Errorf("proofs of height %d failed %d times, the witness is marked as failed", height, maxFailures)
+	}
+	return ErrInvalidProof
+}
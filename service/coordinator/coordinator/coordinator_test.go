@@ -0,0 +1,199 @@
+package coordinator
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/blockwitness"
+	"github.com/bnb-chain/zkbas/dao/proof"
+	"github.com/bnb-chain/zkbas/service/coordinator/pb"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+type testBlockWitnessModel struct {
+	blockwitness.BlockWitnessModel
+
+	mu        sync.Mutex
+	witnesses []*blockwitness.BlockWitness
+}
+
+func (m *testBlockWitnessModel) ClaimBlockWitness(prover string, leaseExpiresAt time.Time, blockSizes []int) (*blockwitness.BlockWitness, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.witnesses {
+		matched := len(blockSizes) == 0
+		for _, blockSize := range blockSizes {
+			matched = matched || w.BlockSize == blockSize
+		}
+		if !matched {
+			continue
+		}
+		if w.Status == blockwitness.StatusPublished ||
+			(w.Status == blockwitness.StatusReceived && w.LeaseExpiresAt.Before(time.Now())) {
+			w.Status, w.Prover, w.LeaseExpiresAt = blockwitness.StatusReceived, prover, &leaseExpiresAt
+			return w, nil
+		}
+	}
+	return nil, types.DbErrNotFound
+}
+
+func (m *testBlockWitnessModel) GetBlockWitnessByNumber(height int64) (*blockwitness.BlockWitness, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.witnesses {
+		if w.Height == height {
+			return w, nil
+		}
+	}
+	return nil, types.DbErrNotFound
+}
+
+func (m *testBlockWitnessModel) RenewBlockWitnessLease(height int64, prover string, leaseExpiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.witnesses {
+		if w.Height == height && w.Prover == prover && w.Status == blockwitness.StatusReceived {
+			w.LeaseExpiresAt = &leaseExpiresAt
+			return nil
+		}
+	}
+	return types.DbErrBlockWitnessLeaseLost
+}
+
+func (m *testBlockWitnessModel) CompleteBlockWitness(height int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.witnesses {
+		if w.Height == height {
+			w.Status, w.LeaseExpiresAt = blockwitness.StatusProved, nil
+		}
+	}
+	return nil
+}
+
+func (m *testBlockWitnessModel) FailBlockWitnessProof(height int64, prover string, maxFailures int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, w := range m.witnesses {
+		if w.Height == height && w.Prover == prover && w.Status == blockwitness.StatusReceived {
+			w.ProofFailures++
+			w.Status, w.Prover, w.LeaseExpiresAt = blockwitness.StatusPublished, "", nil
+			if w.ProofFailures >= maxFailures {
+				w.Status = blockwitness.StatusProofFailed
+			}
+			return w.Status == blockwitness.StatusProofFailed, nil
+		}
+	}
+	return false, types.DbErrBlockWitnessLeaseLost
+}
+
+type testBlockModel struct {
+	block.BlockModel
+	stateRoots map[int64]string
+}
+
+func (m *testBlockModel) GetBlockByHeightWithoutTx(height int64) (*block.Block, error) {
+	return &block.Block{BlockHeight: height, StateRoot: m.stateRoots[height]}, nil
+}
+
+type testProofModel struct {
+	proof.ProofModel
+	proofs map[int64]*proof.Proof
+}
+
+func (m *testProofModel) GetProofByBlockNumber(num int64) (*proof.Proof, error) {
+	if p, ok := m.proofs[num]; ok {
+		return p, nil
+	}
+	return nil, types.DbErrNotFound
+}
+
+func (m *testProofModel) CreateProof(row *proof.Proof) error {
+	m.proofs[row.BlockNumber] = row
+	return nil
+}
+
+func TestCoordinator(t *testing.T) {
+	expired := time.Now().Add(-time.Second)
+	witnessModel := &testBlockWitnessModel{witnesses: []*blockwitness.BlockWitness{
+		// the lease of the crashed prover is expired, but the proof was created.
+		{Height: 1, Status: blockwitness.StatusReceived, Prover: "crashed", LeaseExpiresAt: &expired, BlockSize: 1},
+		{Height: 2, Status: blockwitness.StatusPublished, WitnessData: `{"Txs":[{}]}`, BlockSize: 1},
+		// the new state root of the witness differs from the committed block.
+		{Height: 3, Status: blockwitness.StatusPublished, WitnessData: `{"Txs":[{}]}`, BlockSize: 1},
+	}}
+	blockModel := &testBlockModel{stateRoots: map[int64]string{3: "0x01"}}
+	proofModel := &testProofModel{proofs: map[int64]*proof.Proof{
+		1: {BlockNumber: 1, Prover: "crashed"},
+	}}
+	co := NewCoordinator(witnessModel, proofModel, blockModel, time.Minute, 2, func(int) (groth16.VerifyingKey, error) {
+		return nil, nil
+	})
+
+	// serve the coordinator to the client in memory.
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	pb.RegisterCoordinatorServer(server, NewServer(co))
+	go server.Serve(listener) //nolint:errcheck
+	defer server.Stop()
+	conn, err := grpc.Dial("bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}))
+	assert.NoError(t, err)
+	defer conn.Close()
+	cli := NewClient(pb.NewCoordinatorClient(conn))
+
+	// the prover of other block sizes claims nothing.
+	_, err = cli.FetchJob("prover", []int{10})
+	assert.Equal(t, types.DbErrNotFound, err)
+	w, err := cli.FetchJob("prover", []int{1, 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), w.Height)
+	assert.Equal(t, `{"Txs":[{}]}`, w.WitnessData)
+	assert.Equal(t, int64(blockwitness.StatusProved), witnessModel.witnesses[0].Status)
+	w, err = cli.FetchJob("other", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), w.Height)
+
+	leaseExpiresAt, err := cli.Heartbeat("prover", 2)
+	assert.NoError(t, err)
+	assert.True(t, leaseExpiresAt.After(time.Now()))
+	_, err = cli.Heartbeat("other", 2)
+	assert.Equal(t, types.DbErrBlockWitnessLeaseLost, err)
+
+	// the invalid proofs are not stored, the one of a prover not holding the lease is rejected only.
+	assert.Equal(t, ErrInvalidProof, cli.SubmitProof("other", 2, "invalid"))
+	assert.Equal(t, int64(blockwitness.StatusReceived), witnessModel.witnesses[1].Status)
+	assert.Equal(t, "prover", witnessModel.witnesses[1].Prover)
+
+	// the witness is released once the proof of the lease holder failed, and claimed no more once the proofs failed
+	// twice, even by the same prover.
+	assert.Equal(t, ErrInvalidProof, cli.SubmitProof("prover", 2, `{"Inputs":[1,2,3]}`))
+	assert.Equal(t, int64(blockwitness.StatusPublished), witnessModel.witnesses[1].Status)
+	assert.Equal(t, 1, witnessModel.witnesses[1].ProofFailures)
+	w, err = cli.FetchJob("prover", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), w.Height)
+	assert.Equal(t, ErrInvalidProof, cli.SubmitProof("prover", 2, "invalid"))
+	_, ok := proofModel.proofs[2]
+	assert.False(t, ok)
+	assert.Equal(t, int64(blockwitness.StatusProofFailed), witnessModel.witnesses[1].Status)
+	assert.Equal(t, 2, witnessModel.witnesses[1].ProofFailures)
+
+	// the witness differing from the committed block is failed at once.
+	assert.Equal(t, ErrInvalidProof, cli.SubmitProof("other", 3, "invalid"))
+	assert.Equal(t, int64(blockwitness.StatusProofFailed), witnessModel.witnesses[2].Status)
+	_, err = cli.FetchJob("other", nil)
+	assert.Equal(t, types.DbErrNotFound, err)
+}
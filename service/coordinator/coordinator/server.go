@@ -0,0 +1,78 @@
+package coordinator
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bnb-chain/zkbas/service/coordinator/pb"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// Server serves the coordinator to the remote provers over grpc.
+type Server struct {
+	pb.UnimplementedCoordinatorServer
+
+	coordinator *Coordinator
+}
+
+func NewServer(coordinator *Coordinator) *Server {
+	return &Server{
+		coordinator: coordinator,
+	}
+}
+
+func (s *Server) FetchJob(_ context.Context, req *pb.ReqFetchJob) (*pb.RespFetchJob, error) {
+	if req.ProverId == "" {
+		return nil, status.Error(codes.InvalidArgument, "prover id is required")
+	}
+	blockSizes := make([]int, 0, len(req.BlockSizes))
+	for _, blockSize := range req.BlockSizes {
+		blockSizes = append(blockSizes, int(blockSize))
+	}
+	blockWitness, err := s.coordinator.FetchJob(req.ProverId, blockSizes)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	logx.Infof("block witness %d is claimed by prover %s", blockWitness.Height, req.ProverId)
+	return &pb.RespFetchJob{
+		BlockHeight:    blockWitness.Height,
+		WitnessData:    blockWitness.WitnessData,
+		LeaseExpiresAt: blockWitness.LeaseExpiresAt.UnixMilli(),
+	}, nil
+}
+
+func (s *Server) Heartbeat(_ context.Context, req *pb.ReqHeartbeat) (*pb.RespHeartbeat, error) {
+	leaseExpiresAt, err := s.coordinator.Heartbeat(req.ProverId, req.BlockHeight)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.RespHeartbeat{
+		LeaseExpiresAt: leaseExpiresAt.UnixMilli(),
+	}, nil
+}
+
+func (s *Server) SubmitProof(_ context.Context, req *pb.ReqSubmitProof) (*pb.RespSubmitProof, error) {
+	err := s.coordinator.SubmitProof(req.ProverId, req.BlockHeight, req.ProofInfo)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	logx.Infof("proof of height %d is submitted by prover %s", req.BlockHeight, req.ProverId)
+	return &pb.RespSubmitProof{}, nil
+}
+
+func toStatus(err error) error {
+	switch err {
+	case types.DbErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case types.DbErrBlockWitnessLeaseLost:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case ErrInvalidProof:
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		logx.Errorf("coordinator error: %v", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,183 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.5.1-go
+// source: coordinator.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// CoordinatorClient is the client API for Coordinator service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CoordinatorClient interface {
+	// FetchJob claims the lowest witness not proved, NotFound is returned if there is none.
+	FetchJob(ctx context.Context, in *ReqFetchJob, opts ...grpc.CallOption) (*RespFetchJob, error)
+	// Heartbeat renews the lease of the job, FailedPrecondition is returned if the lease is lost.
+	Heartbeat(ctx context.Context, in *ReqHeartbeat, opts ...grpc.CallOption) (*RespHeartbeat, error)
+	// SubmitProof verifies and stores the proof of the job, InvalidArgument is returned if the proof is invalid.
+	SubmitProof(ctx context.Context, in *ReqSubmitProof, opts ...grpc.CallOption) (*RespSubmitProof, error)
+}
+
+type coordinatorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCoordinatorClient(cc grpc.ClientConnInterface) CoordinatorClient {
+	return &coordinatorClient{cc}
+}
+
+func (c *coordinatorClient) FetchJob(ctx context.Context, in *ReqFetchJob, opts ...grpc.CallOption) (*RespFetchJob, error) {
+	out := new(RespFetchJob)
+	err := c.cc.Invoke(ctx, "/coordinator.Coordinator/FetchJob", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorClient) Heartbeat(ctx context.Context, in *ReqHeartbeat, opts ...grpc.CallOption) (*RespHeartbeat, error) {
+	out := new(RespHeartbeat)
+	err := c.cc.Invoke(ctx, "/coordinator.Coordinator/Heartbeat", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coordinatorClient) SubmitProof(ctx context.Context, in *ReqSubmitProof, opts ...grpc.CallOption) (*RespSubmitProof, error) {
+	out := new(RespSubmitProof)
+	err := c.cc.Invoke(ctx, "/coordinator.Coordinator/SubmitProof", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CoordinatorServer is the server API for Coordinator service.
+// All implementations must embed UnimplementedCoordinatorServer
+// for forward compatibility
+type CoordinatorServer interface {
+	// FetchJob claims the lowest witness not proved, NotFound is returned if there is none.
+	FetchJob(context.Context, *ReqFetchJob) (*RespFetchJob, error)
+	// Heartbeat renews the lease of the job, FailedPrecondition is returned if the lease is lost.
+	Heartbeat(context.Context, *ReqHeartbeat) (*RespHeartbeat, error)
+	// SubmitProof verifies and stores the proof of the job, InvalidArgument is returned if the proof is invalid.
+	SubmitProof(context.Context, *ReqSubmitProof) (*RespSubmitProof, error)
+	mustEmbedUnimplementedCoordinatorServer()
+}
+
+// UnimplementedCoordinatorServer must be embedded to have forward compatible implementations.
+type UnimplementedCoordinatorServer struct {
+}
+
+func (UnimplementedCoordinatorServer) FetchJob(context.Context, *ReqFetchJob) (*RespFetchJob, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchJob not implemented")
+}
+func (UnimplementedCoordinatorServer) Heartbeat(context.Context, *ReqHeartbeat) (*RespHeartbeat, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedCoordinatorServer) SubmitProof(context.Context, *ReqSubmitProof) (*RespSubmitProof, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitProof not implemented")
+}
+func (UnimplementedCoordinatorServer) mustEmbedUnimplementedCoordinatorServer() {}
+
+// UnsafeCoordinatorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CoordinatorServer will
+// result in compilation errors.
+type UnsafeCoordinatorServer interface {
+	mustEmbedUnimplementedCoordinatorServer()
+}
+
+func RegisterCoordinatorServer(s grpc.ServiceRegistrar, srv CoordinatorServer) {
+	s.RegisterService(&Coordinator_ServiceDesc, srv)
+}
+
+func _Coordinator_FetchJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReqFetchJob)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServer).FetchJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/coordinator.Coordinator/FetchJob",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServer).FetchJob(ctx, req.(*ReqFetchJob))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Coordinator_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReqHeartbeat)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/coordinator.Coordinator/Heartbeat",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServer).Heartbeat(ctx, req.(*ReqHeartbeat))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Coordinator_SubmitProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReqSubmitProof)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoordinatorServer).SubmitProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/coordinator.Coordinator/SubmitProof",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoordinatorServer).SubmitProof(ctx, req.(*ReqSubmitProof))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Coordinator_ServiceDesc is the grpc.ServiceDesc for Coordinator service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Coordinator_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "coordinator.Coordinator",
+	HandlerType: (*CoordinatorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FetchJob",
+			Handler:    _Coordinator_FetchJob_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _Coordinator_Heartbeat_Handler,
+		},
+		{
+			MethodName: "SubmitProof",
+			Handler:    _Coordinator_SubmitProof_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "coordinator.proto",
+}
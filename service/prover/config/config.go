@@ -1,21 +1,54 @@
 package config
 
 import (
+	"time"
+
 	"github.com/zeromicro/go-zero/core/logx"
-	"github.com/zeromicro/go-zero/core/stores/cache"
 )
 
 type Config struct {
+	//nolint:staticcheck
 	Postgres struct {
 		DataSource string
-	}
-	CacheRedis cache.CacheConf
-	LogConf    logx.LogConf
-	KeyPath    struct {
+	} `json:",optional"`
+	LogConf logx.LogConf
+	KeyPath struct {
 		ProvingKeyPath   []string
 		VerifyingKeyPath []string
 	}
 	BlockConfig struct {
 		OptionalBlockSizes []int
+		// the block sizes proved by the prover, all the optional sizes by default
+		ProveBlockSizes []int `json:",optional"`
+		// the directory to cache the compiled circuits, they are compiled every time the prover starts if it is empty
+		R1csCacheDir string `json:",optional"`
 	}
+	// the address of the remote coordinator to fetch the witnesses from, the database is accessed directly if it is
+	// not configured
+	CoordinatorAddr string `json:",optional"`
+	//nolint:staticcheck
+	CoordinatorTLS struct {
+		// the ca of the certificate of the coordinator
+		CAFile string
+		// the certificate presented to the coordinator for mutual tls
+		CertFile string `json:",optional"`
+		KeyFile  string `json:",optional"`
+	} `json:",optional"`
+	// the token of the prover id issued by the coordinator
+	CoordinatorToken string `json:",optional"`
+	//nolint:staticcheck
+	Lease LeaseConfig `json:",optional"`
+}
+
+type LeaseConfig struct {
+	// the id of the prover recorded in the claimed witnesses and the proofs, the hostname and pid by default
+	ProverId string `json:",optional"`
+	// the duration a witness is leased to the prover, it is claimed by the others once expired. The lease duration
+	// of the remote coordinator is configured by the coordinator.
+	Duration time.Duration `json:",optional"`
+	// the interval of renewing the lease while proving, it should be much shorter than the duration
+	HeartbeatInterval time.Duration `json:",optional"`
+	// the witness is marked as failed once its proofs failed the times, 3 by default. It is configured by the
+	// coordinator for the remote coordinator.
+	MaxProofFailures int `json:",optional"`
 }
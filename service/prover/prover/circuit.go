@@ -0,0 +1,69 @@
+package prover
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas/common/prove"
+)
+
+// circuit holds the constraint system and the keys of a block size, they are loaded on the first use.
+type circuit struct {
+	blockSize        int
+	provingKeyPath   string
+	verifyingKeyPath string
+	// the directory to cache the compiled constraint system, see prove.CompileBlockCircuit
+	cacheDir string
+
+	mu         sync.Mutex
+	r1cs       frontend.CompiledConstraintSystem
+	provingKey groth16.ProvingKey
+
+	vkMu         sync.Mutex
+	verifyingKey groth16.VerifyingKey
+}
+
+func (c *circuit) VerifyingKey() (groth16.VerifyingKey, error) {
+	c.vkMu.Lock()
+	defer c.vkMu.Unlock()
+	if c.verifyingKey != nil {
+		return c.verifyingKey, nil
+	}
+	verifyingKey, err := prove.LoadVerifyingKey(c.verifyingKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("load verifying key %s error: %v", c.verifyingKeyPath, err)
+	}
+	c.verifyingKey = verifyingKey
+	return verifyingKey, nil
+}
+
+// load returns the constraint system and the keys for proving, the circuit is compiled and the proving key is read
+// at the first time.
+func (c *circuit) load() (frontend.CompiledConstraintSystem, groth16.ProvingKey, groth16.VerifyingKey, error) {
+	verifyingKey, err := c.VerifyingKey()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.r1cs == nil {
+		r1cs, err := prove.CompileBlockCircuit(c.blockSize, c.cacheDir)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("compile block size %d circuit error: %v", c.blockSize, err)
+		}
+		c.r1cs = r1cs
+	}
+	if c.provingKey == nil {
+		logx.Infof("start reading block size %d proving key", c.blockSize)
+		provingKey, err := prove.LoadProvingKey(c.provingKeyPath)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("load proving key %s error: %v", c.provingKeyPath, err)
+		}
+		c.provingKey = provingKey
+	}
+	return c.r1cs, c.provingKey, verifyingKey, nil
+}
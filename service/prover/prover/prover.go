@@ -2,22 +2,25 @@ package prover
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"sync"
+	"time"
 
-	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
-	"github.com/consensys/gnark/frontend"
-	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/zeromicro/go-zero/core/logx"
-	"github.com/zeromicro/go-zero/core/stores/redis"
+	"google.golang.org/grpc"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	"github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
 	"github.com/bnb-chain/zkbas/common/prove"
-	"github.com/bnb-chain/zkbas/common/redislock"
+	blockdao "github.com/bnb-chain/zkbas/dao/block"
 	"github.com/bnb-chain/zkbas/dao/blockwitness"
 	"github.com/bnb-chain/zkbas/dao/proof"
+	"github.com/bnb-chain/zkbas/service/coordinator/coordinator"
+	"github.com/bnb-chain/zkbas/service/coordinator/pb"
 	"github.com/bnb-chain/zkbas/service/prover/config"
 	"github.com/bnb-chain/zkbas/types"
 )
@@ -25,154 +28,199 @@ import (
 type Prover struct {
 	Config config.Config
 
-	RedisConn *redis.Redis
+	// the id of the prover and the interval of renewing the lease of the claimed witness
+	Id                string
+	HeartbeatInterval time.Duration
 
-	ProofModel        proof.ProofModel
-	BlockWitnessModel blockwitness.BlockWitnessModel
+	// the coordinator of the database, or the remote one
+	Jobs JobSource
 
-	VerifyingKeys      []groth16.VerifyingKey
-	ProvingKeys        []groth16.ProvingKey
-	OptionalBlockSizes []int
-	R1cs               []frontend.CompiledConstraintSystem
+	// the block sizes proved by the prover, all the sizes if it is empty
+	BlockSizes []int
+	circuits   map[int]*circuit
 }
 
-func WithRedis(redisType string, redisPass string) redis.Option {
-	return func(p *redis.Redis) {
-		p.Type = redisType
-		p.Pass = redisPass
-	}
-}
 func NewProver(c config.Config) *Prover {
-	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
-	if err != nil {
-		logx.Errorf("gorm connect db error, err = %s", err.Error())
-	}
-	redisConn := redis.New(c.CacheRedis[0].Host, WithRedis(c.CacheRedis[0].Type, c.CacheRedis[0].Pass))
 	prover := &Prover{
 		Config:            c,
-		RedisConn:         redisConn,
-		BlockWitnessModel: blockwitness.NewBlockWitnessModel(db),
-		ProofModel:        proof.NewProofModel(db),
-	}
-
-	prover.OptionalBlockSizes = c.BlockConfig.OptionalBlockSizes
-	prover.ProvingKeys = make([]groth16.ProvingKey, len(prover.OptionalBlockSizes))
-	prover.VerifyingKeys = make([]groth16.VerifyingKey, len(prover.OptionalBlockSizes))
-	prover.R1cs = make([]frontend.CompiledConstraintSystem, len(prover.OptionalBlockSizes))
-	for i := 0; i < len(prover.OptionalBlockSizes); i++ {
-		var circuit block.BlockConstraints
-		circuit.TxsCount = prover.OptionalBlockSizes[i]
-		circuit.Txs = make([]block.TxConstraints, circuit.TxsCount)
-		for i := 0; i < circuit.TxsCount; i++ {
-			circuit.Txs[i] = block.GetZeroTxConstraint()
-		}
-		logx.Infof("start compile block size %d circuit", circuit.TxsCount)
-		prover.R1cs[i], err = frontend.Compile(ecc.BN254, r1cs.NewBuilder, &circuit, frontend.IgnoreUnconstrainedInputs())
+		Id:                c.Lease.ProverId,
+		HeartbeatInterval: c.Lease.HeartbeatInterval,
+	}
+	if prover.Id == "" {
+		hostname, err := os.Hostname()
 		if err != nil {
-			panic("r1cs init error")
+			panic(fmt.Sprintf("get hostname error: %v", err))
+		}
+		prover.Id = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if prover.HeartbeatInterval == 0 {
+		prover.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+	logx.Infof("prover %s, heartbeat interval %v", prover.Id, prover.HeartbeatInterval)
+
+	if len(c.KeyPath.ProvingKeyPath) != len(c.BlockConfig.OptionalBlockSizes) ||
+		len(c.KeyPath.VerifyingKeyPath) != len(c.BlockConfig.OptionalBlockSizes) {
+		panic("the keys don't match the block sizes")
+	}
+	prover.circuits = make(map[int]*circuit, len(c.BlockConfig.OptionalBlockSizes))
+	for i, blockSize := range c.BlockConfig.OptionalBlockSizes {
+		prover.circuits[blockSize] = &circuit{
+			blockSize:        blockSize,
+			provingKeyPath:   c.KeyPath.ProvingKeyPath[i],
+			verifyingKeyPath: c.KeyPath.VerifyingKeyPath[i],
+			cacheDir:         c.BlockConfig.R1csCacheDir,
+		}
+	}
+	prover.BlockSizes = c.BlockConfig.ProveBlockSizes
+	for _, blockSize := range prover.BlockSizes {
+		if _, ok := prover.circuits[blockSize]; !ok {
+			panic(fmt.Sprintf("block size %d is not optional", blockSize))
+		}
+	}
+	logx.Infof("prover %s proves block sizes %v", prover.Id, prover.BlockSizes)
+
+	if c.CoordinatorAddr != "" {
+		if c.CoordinatorToken == "" {
+			panic("the token of the coordinator is required")
 		}
-		logx.Infof("circuit constraints: %d", prover.R1cs[i].GetNbConstraints())
-		logx.Info("finish compile circuit")
-		// read proving and verifying keys
-		prover.ProvingKeys[i], err = prove.LoadProvingKey(c.KeyPath.ProvingKeyPath[i])
+		creds, err := coordinator.NewClientCredentials(c.CoordinatorTLS.CAFile, c.CoordinatorTLS.CertFile,
+			c.CoordinatorTLS.KeyFile)
 		if err != nil {
-			panic("provingKey loading error")
+			panic(fmt.Sprintf("load the credentials of coordinator error: %v", err))
 		}
-		prover.VerifyingKeys[i], err = prove.LoadVerifyingKey(c.KeyPath.VerifyingKeyPath[i])
+		// the witnesses may be large.
+		conn, err := grpc.Dial(c.CoordinatorAddr, grpc.WithTransportCredentials(creds),
+			grpc.WithPerRPCCredentials(coordinator.TokenCredentials(c.CoordinatorToken)),
+			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(MaxJobSize)))
 		if err != nil {
-			panic("verifyingKey loading error")
+			panic(fmt.Sprintf("dial coordinator %s error: %v", c.CoordinatorAddr, err))
 		}
+		prover.Jobs = coordinator.NewClient(pb.NewCoordinatorClient(conn))
+		return prover
 	}
 
+	leaseDuration := c.Lease.Duration
+	if leaseDuration == 0 {
+		leaseDuration = coordinator.DefaultLeaseDuration
+	}
+	if prover.HeartbeatInterval >= leaseDuration {
+		panic("lease heartbeat interval should be shorter than the lease duration")
+	}
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		logx.Errorf("gorm connect db error, err = %s", err.Error())
+	}
+	prover.Jobs = coordinator.NewCoordinator(blockwitness.NewBlockWitnessModel(db), proof.NewProofModel(db),
+		blockdao.NewBlockModel(db), leaseDuration, c.Lease.MaxProofFailures, prover.verifyingKey)
 	return prover
 }
 
-func (p *Prover) ProveBlock() error {
-	blockWitness, err := func() (*blockwitness.BlockWitness, error) {
-		lock := redislock.GetRedisLockByKey(p.RedisConn, RedisLockKey)
-		err := redislock.TryAcquireLock(lock)
-		if err != nil {
-			return nil, err
-		}
-		//nolint:errcheck
-		defer lock.Release()
+func (p *Prover) verifyingKey(blockSize int) (groth16.VerifyingKey, error) {
+	circuit, ok := p.circuits[blockSize]
+	if !ok {
+		return nil, fmt.Errorf("can't find correct vk for block size %d", blockSize)
+	}
+	return circuit.VerifyingKey()
+}
 
-		// Fetch unproved block witness.
-		blockWitness, err := p.BlockWitnessModel.GetLatestBlockWitness()
-		if err != nil {
-			return nil, err
-		}
-		// Update status of block witness.
-		err = p.BlockWitnessModel.UpdateBlockWitnessStatus(blockWitness, blockwitness.StatusReceived)
-		if err != nil {
-			return nil, err
-		}
-		return blockWitness, nil
-	}()
+// ProveBlock fetches the lowest witness not proved and proves it. The lease of the witness is renewed while proving,
+// and the witness is released once the proving fails. The witness of a crashed prover is claimed again once its lease
+// is expired.
+func (p *Prover) ProveBlock() error {
+	blockWitness, err := p.Jobs.FetchJob(p.Id, p.BlockSizes)
 	if err != nil {
 		if err == types.DbErrNotFound {
 			return nil
 		}
 		return err
 	}
-	defer func() {
-		if err == nil {
-			return
-		}
+	logx.Infof("block witness %d is claimed by prover %s", blockWitness.Height, p.Id)
 
-		// Recover block witness status.
-		res := p.BlockWitnessModel.UpdateBlockWitnessStatus(blockWitness, blockwitness.StatusPublished)
+	stop := p.keepLease(blockWitness.Height)
+	proofInfo, err := p.proveBlock(blockWitness)
+	stop()
+	if err != nil {
+		res := p.Jobs.ReleaseJob(p.Id, blockWitness.Height)
 		if res != nil {
-			logx.Errorf("revert block witness status failed, err %v", res)
+			logx.Errorf("release block witness %d failed, err %v", blockWitness.Height, res)
+		}
+		return err
+	}
+	// the proof is still submitted if the lease is lost, unless another prover submits it first.
+	err = p.Jobs.SubmitProof(p.Id, blockWitness.Height, proofInfo)
+	if err == coordinator.ErrInvalidProof {
+		return fmt.Errorf("proof of height %d failed to be verified", blockWitness.Height)
+	}
+	return err
+}
+
+// keepLease renews the lease of the witness every heartbeat interval until the returned function is called.
+func (p *Prover) keepLease(height int64) func() {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(p.HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_, err := p.Jobs.Heartbeat(p.Id, height)
+				if err == types.DbErrBlockWitnessLeaseLost {
+					logx.Errorf("lease of block witness %d is lost", height)
+					return
+				}
+				if err != nil {
+					logx.Errorf("renew lease of block witness %d failed, err %v", height, err)
+				}
+			}
 		}
 	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
 
+func (p *Prover) proveBlock(blockWitness *blockwitness.BlockWitness) (string, error) {
 	// Parse crypto block.
 	var cryptoBlock *block.Block
-	err = json.Unmarshal([]byte(blockWitness.WitnessData), &cryptoBlock)
+	err := json.Unmarshal([]byte(blockWitness.WitnessData), &cryptoBlock)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	var keyIndex int
-	for ; keyIndex < len(p.OptionalBlockSizes); keyIndex++ {
-		if len(cryptoBlock.Txs) == p.OptionalBlockSizes[keyIndex] {
-			break
-		}
+	circuit, ok := p.circuits[len(cryptoBlock.Txs)]
+	if !ok {
+		return "", fmt.Errorf("can't find correct vk/pk")
 	}
-	if keyIndex == len(p.OptionalBlockSizes) {
-		return fmt.Errorf("can't find correct vk/pk")
+	r1cs, provingKey, verifyingKey, err := circuit.load()
+	if err != nil {
+		return "", err
 	}
 
 	// Generate proof.
-	blockProof, err := prove.GenerateProof(p.R1cs[keyIndex], p.ProvingKeys[keyIndex], p.VerifyingKeys[keyIndex], cryptoBlock)
-	if err != nil {
-		return fmt.Errorf("failed to generateProof, err: %v", err)
+	blockProof, err := prove.GenerateProof(r1cs, provingKey, verifyingKey, cryptoBlock)
+	if errors.Is(err, prove.ErrProofNotVerified) && blockProof != nil {
+		// the proof is still submitted, so the witness is released or marked as failed as the other invalid proofs.
+		logx.Errorf("proof of height %d generated is invalid: %v", blockWitness.Height, err)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to generateProof, err: %v", err)
 	}
 
 	formattedProof, err := prove.FormatProof(blockProof, cryptoBlock.OldStateRoot, cryptoBlock.NewStateRoot, cryptoBlock.BlockCommitment)
 	if err != nil {
-		return fmt.Errorf("unable to format blockProof: %v", err)
+		return "", fmt.Errorf("unable to format blockProof: %v", err)
 	}
 
 	// Marshal formatted proof.
 	proofBytes, err := json.Marshal(formattedProof)
 	if err != nil {
-		return err
-	}
-
-	// Check the existence of block proof.
-	_, err = p.ProofModel.GetProofByBlockNumber(blockWitness.Height)
-	if err == nil {
-		logx.Errorf("blockProof of height %d exists", blockWitness.Height)
-		return nil
+		return "", err
 	}
 
-	var row = &proof.Proof{
-		ProofInfo:   string(proofBytes),
-		BlockNumber: blockWitness.Height,
-		Status:      proof.NotSent,
-	}
-	err = p.ProofModel.CreateProof(row)
-	return err
+	return string(proofBytes), nil
 }
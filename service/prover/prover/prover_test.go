@@ -0,0 +1,130 @@
+package prover
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/dao/blockwitness"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+type testJobSource struct {
+	mu        sync.Mutex
+	witnesses []*blockwitness.BlockWitness
+	heartbeat int
+	released  []int64
+}
+
+func (s *testJobSource) FetchJob(string, []int) (*blockwitness.BlockWitness, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.witnesses) == 0 {
+		return nil, types.DbErrNotFound
+	}
+	w := s.witnesses[0]
+	s.witnesses = s.witnesses[1:]
+	return w, nil
+}
+
+func (s *testJobSource) Heartbeat(string, int64) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeat++
+	if s.heartbeat > 2 {
+		return time.Time{}, types.DbErrBlockWitnessLeaseLost
+	}
+	return time.Now().Add(time.Minute), nil
+}
+
+func (s *testJobSource) ReleaseJob(_ string, height int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = append(s.released, height)
+	return nil
+}
+
+func (s *testJobSource) SubmitProof(string, int64, string) error {
+	return nil
+}
+
+func TestProveBlock(t *testing.T) {
+	jobs := &testJobSource{witnesses: []*blockwitness.BlockWitness{
+		{Height: 1, Status: blockwitness.StatusReceived, WitnessData: "invalid"},
+	}}
+	p := &Prover{
+		Id:                "prover",
+		HeartbeatInterval: time.Second,
+		Jobs:              jobs,
+	}
+
+	// the witness failed to be proved is released.
+	assert.Error(t, p.ProveBlock())
+	assert.Equal(t, []int64{1}, jobs.released)
+
+	assert.NoError(t, p.ProveBlock())
+}
+
+func TestKeepLease(t *testing.T) {
+	jobs := &testJobSource{}
+	p := &Prover{
+		Id:                "prover",
+		HeartbeatInterval: 10 * time.Millisecond,
+		Jobs:              jobs,
+	}
+
+	stop := p.keepLease(1)
+	time.Sleep(100 * time.Millisecond)
+	stop()
+
+	// the heartbeat stops once the lease is lost.
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	assert.Equal(t, 3, jobs.heartbeat)
+}
+
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+func TestVerifyingKey(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(t, err)
+	_, vk, err := groth16.Setup(ccs)
+	assert.NoError(t, err)
+	vkPath := filepath.Join(t.TempDir(), "zkbas1.vk")
+	f, err := os.Create(vkPath)
+	assert.NoError(t, err)
+	_, err = vk.WriteTo(f)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	// the keys are not loaded until they are used.
+	p := &Prover{circuits: map[int]*circuit{
+		1: {blockSize: 1, verifyingKeyPath: vkPath},
+	}}
+	assert.Nil(t, p.circuits[1].verifyingKey)
+	loaded, err := p.verifyingKey(1)
+	assert.NoError(t, err)
+	assert.False(t, loaded.IsDifferent(vk))
+	cached, err := p.verifyingKey(1)
+	assert.NoError(t, err)
+	assert.True(t, loaded == cached)
+
+	_, err = p.verifyingKey(10)
+	assert.Error(t, err)
+}
@@ -16,4 +16,23 @@
 
 package prover
 
-const RedisLockKey = "prover_mutex_key"
+import (
+	"time"
+
+	"github.com/bnb-chain/zkbas/dao/blockwitness"
+)
+
+const (
+	DefaultHeartbeatInterval = 15 * time.Second
+
+	// the max size of the witness fetched from the coordinator
+	MaxJobSize = 64 * 1024 * 1024
+)
+
+// JobSource leases the block witnesses to the prover, see coordinator.Coordinator.
+type JobSource interface {
+	FetchJob(proverId string, blockSizes []int) (*blockwitness.BlockWitness, error)
+	Heartbeat(proverId string, height int64) (time.Time, error)
+	ReleaseJob(proverId string, height int64) error
+	SubmitProof(proverId string, height int64, proofInfo string) error
+}
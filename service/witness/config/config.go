@@ -15,7 +15,14 @@ type Config struct {
 		//nolint:staticcheck
 		LevelDBOption tree.LevelDBOption `json:",optional"`
 		//nolint:staticcheck
+		PebbleDBOption tree.PebbleDBOption `json:",optional"`
+		//nolint:staticcheck
 		RedisDBOption tree.RedisDBOption `json:",optional"`
+		//nolint:staticcheck
+		Prune tree.PruneConfig `json:",optional"`
+		// the number of the asset trees kept in memory after they are committed
+		//nolint:staticcheck
+		AssetTreeCacheSize int `json:",optional"`
 	}
 	LogConf logx.LogConf
 }
@@ -20,6 +20,7 @@ func Run(configFile string) error {
 	logx.MustSetup(c.LogConf)
 	logx.DisableStat()
 	proc.AddShutdownListener(func() {
+		w.Shutdown()
 		logx.Close()
 	})
 
@@ -32,7 +33,6 @@ func Run(configFile string) error {
 		if err != nil {
 			logx.Errorf("failed to generate block witness, %v", err)
 		}
-		w.RescheduleBlockWitness()
 	})
 	if err != nil {
 		panic(err)
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/zeromicro/go-zero/core/logx"
@@ -26,8 +25,6 @@ import (
 )
 
 const (
-	UnprovedBlockWitnessTimeout = 10 * time.Minute
-
 	BlockProcessDelta = 10
 )
 
@@ -38,8 +35,9 @@ type Witness struct {
 
 	// Trees
 	treeCtx       *tree.Context
+	pruner        *tree.Pruner
 	accountTree   smt.SparseMerkleTree
-	assetTrees    []smt.SparseMerkleTree
+	assetTrees    *tree.AssetTreeCache
 	liquidityTree smt.SparseMerkleTree
 	nftTree       smt.SparseMerkleTree
 
@@ -87,11 +85,13 @@ func (w *Witness) initState() error {
 	}
 	// dbinitializer tree database
 	treeCtx := &tree.Context{
-		Name:          "witness",
-		Driver:        w.config.TreeDB.Driver,
-		LevelDBOption: &w.config.TreeDB.LevelDBOption,
-		RedisDBOption: &w.config.TreeDB.RedisDBOption,
+		Name:           "witness",
+		Driver:         w.config.TreeDB.Driver,
+		LevelDBOption:  &w.config.TreeDB.LevelDBOption,
+		PebbleDBOption: &w.config.TreeDB.PebbleDBOption,
+		RedisDBOption:  &w.config.TreeDB.RedisDBOption,
 	}
+	treeCtx.SetAssetTreeCacheSize(w.config.TreeDB.AssetTreeCacheSize)
 	err = tree.SetupTreeDB(treeCtx)
 	if err != nil {
 		return fmt.Errorf("init tree database failed %v", err)
@@ -121,10 +121,29 @@ func (w *Witness) initState() error {
 	if err != nil {
 		return fmt.Errorf("initNftTree error: %v", err)
 	}
-	w.helper = utils.NewWitnessHelper(w.treeCtx, w.accountTree, w.liquidityTree, w.nftTree, &w.assetTrees, w.accountModel)
+	w.helper = utils.NewWitnessHelper(w.treeCtx, w.accountTree, w.liquidityTree, w.nftTree, w.assetTrees, w.accountModel)
+	// the trees are rolled back to the last confirmed proof on restart, the versions from it are kept.
+	w.pruner = tree.NewPruner(treeCtx, w.config.TreeDB.Prune, w.blockModel, w.latestConfirmedProofHeight)
+	w.pruner.Start()
 	return nil
 }
 
+func (w *Witness) latestConfirmedProofHeight() (int64, error) {
+	p, err := w.proofModel.GetLatestConfirmedProof()
+	if err == types.DbErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return p.BlockNumber, nil
+}
+
+// Shutdown stops the background pruning of the trees.
+func (w *Witness) Shutdown() {
+	w.pruner.Stop()
+}
+
 func (w *Witness) GenerateBlockWitness() (err error) {
 	var latestWitnessHeight int64
 	latestWitnessHeight, err = w.blockWitnessModel.GetLatestBlockWitnessHeight()
@@ -153,7 +172,7 @@ func (w *Witness) GenerateBlockWitness() (err error) {
 			return fmt.Errorf("failed to construct block witness, err: %v", err)
 		}
 		// Step2: commit trees for witness
-		err = tree.CommitTrees(uint64(latestVerifiedBlockNr), w.accountTree, &w.assetTrees, w.liquidityTree, w.nftTree)
+		err = w.pruner.CommitTrees(w.accountTree, w.assetTrees, w.liquidityTree, w.nftTree)
 		if err != nil {
 			return fmt.Errorf("unable to commit trees after txs is executed, error: %v", err)
 		}
@@ -161,7 +180,7 @@ func (w *Witness) GenerateBlockWitness() (err error) {
 		err = w.blockWitnessModel.CreateBlockWitness(blockWitness)
 		if err != nil {
 			// rollback trees
-			rollBackErr := tree.RollBackTrees(uint64(block.BlockHeight)-1, w.accountTree, &w.assetTrees, w.liquidityTree, w.nftTree)
+			rollBackErr := w.pruner.RollBackTrees(uint64(block.BlockHeight)-1, w.accountTree, w.assetTrees, w.liquidityTree, w.nftTree)
 			if rollBackErr != nil {
 				logx.Errorf("unable to rollback trees %v", rollBackErr)
 			}
@@ -171,46 +190,6 @@ func (w *Witness) GenerateBlockWitness() (err error) {
 	return nil
 }
 
-func (w *Witness) RescheduleBlockWitness() {
-	latestConfirmedProof, err := w.proofModel.GetLatestConfirmedProof()
-	if err != nil && err != types.DbErrNotFound {
-		logx.Errorf("failed to get latest confirmed proof, err: %v", err)
-		return
-	}
-
-	var nextBlockNumber int64 = 1
-	if err != types.DbErrNotFound {
-		nextBlockNumber = latestConfirmedProof.BlockNumber + 1
-	}
-
-	nextBlockWitness, err := w.blockWitnessModel.GetBlockWitnessByNumber(nextBlockNumber)
-	if err != nil {
-		logx.Errorf("failed to get latest block witness, err: %v", err)
-		return
-	}
-
-	// skip if next block is not processed
-	if nextBlockWitness.Status == blockwitness.StatusPublished {
-		return
-	}
-
-	// skip if the next block proof exists
-	// if the proof is not submitted and verified in L1, there should be another alerts
-	_, err = w.proofModel.GetProofByBlockNumber(nextBlockNumber)
-	if err == nil {
-		return
-	}
-
-	// update block status to Published if it's timeout
-	if time.Now().After(nextBlockWitness.UpdatedAt.Add(UnprovedBlockWitnessTimeout)) {
-		err := w.blockWitnessModel.UpdateBlockWitnessStatus(nextBlockWitness, blockwitness.StatusPublished)
-		if err != nil {
-			logx.Errorf("update unproved block status error, err: %v", err)
-			return
-		}
-	}
-}
-
 func (w *Witness) constructBlockWitness(block *block.Block, latestVerifiedBlockNr int64) (*blockwitness.BlockWitness, error) {
 	var oldStateRoot, newStateRoot []byte
 	txsWitness := make([]*utils.TxWitness, 0, block.BlockSize)
@@ -255,6 +234,7 @@ func (w *Witness) constructBlockWitness(block *block.Block, latestVerifiedBlockN
 		Height:      block.BlockHeight,
 		WitnessData: string(bz),
 		Status:      blockwitness.StatusPublished,
+		BlockSize:   int(block.BlockSize),
 	}
 	return &blockWitness, nil
 }
@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/dbcache"
+	"github.com/bnb-chain/zkbas/tools/cache/internal/config"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// the expiration of the entries, the same as the committer's.
+const expiration = 15 * time.Minute
+
+// Rebuild rewrites the states cached in redis with the accounts, liquidity and nfts in the database, which are the
+// states of the last committed block, and deletes the cached states not in the database, e.g. the ones created by
+// the blocks rolled back. The committer should be stopped during the rebuilding, it caches the states of the
+// proposing block again when it restores the executed txs.
+func Rebuild(configFile string, batchSize int) error {
+	chainDb, redisCache, err := setup(configFile)
+	if err != nil {
+		return err
+	}
+
+	height, _, err := sdb.CommittedHeight(chainDb)
+	if err != nil {
+		return fmt.Errorf("get committed height failed: %v", err)
+	}
+	b, err := chainDb.BlockModel.GetBlockByHeight(height)
+	if err != nil {
+		return fmt.Errorf("get block %d failed: %v", height, err)
+	}
+	txIndex := int64(len(b.Txs)) - 1
+
+	logx.Infof("rebuild redis cache at block %d", height)
+	ctx := context.Background()
+	accounts := make(map[int64]bool)
+	for offset := int64(0); ; offset += int64(batchSize) {
+		accountList, err := chainDb.AccountModel.GetAccountsList(batchSize, offset)
+		if err == types.DbErrNotFound {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for _, accountInfo := range accountList {
+			err = redisCache.Set(ctx, dbcache.AccountKeyByIndex(accountInfo.AccountIndex),
+				dbcache.NewEntry(height, txIndex, accountInfo))
+			if err != nil {
+				return fmt.Errorf("cache account %d failed: %v", accountInfo.AccountIndex, err)
+			}
+			accounts[accountInfo.AccountIndex] = true
+		}
+	}
+
+	liquidityList, err := chainDb.LiquidityModel.GetAllLiquidityAssets()
+	if err != nil && err != types.DbErrNotFound {
+		return err
+	}
+	liquidities := make(map[int64]bool)
+	for _, liquidityInfo := range liquidityList {
+		err = redisCache.Set(ctx, dbcache.LiquidityKeyByIndex(liquidityInfo.PairIndex),
+			dbcache.NewEntry(height, txIndex, liquidityInfo))
+		if err != nil {
+			return fmt.Errorf("cache liquidity %d failed: %v", liquidityInfo.PairIndex, err)
+		}
+		liquidities[liquidityInfo.PairIndex] = true
+	}
+
+	nfts := make(map[int64]bool)
+	for offset := int64(0); ; offset += int64(batchSize) {
+		nftList, err := chainDb.L2NftModel.GetNftsList(batchSize, offset)
+		if err == types.DbErrNotFound {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for _, nftInfo := range nftList {
+			err = redisCache.Set(ctx, dbcache.NftKeyByIndex(nftInfo.NftIndex),
+				dbcache.NewEntry(height, txIndex, nftInfo))
+			if err != nil {
+				return fmt.Errorf("cache nft %d failed: %v", nftInfo.NftIndex, err)
+			}
+			nfts[nftInfo.NftIndex] = true
+		}
+	}
+
+	deleted := 0
+	for prefix, indexes := range map[string]map[int64]bool{
+		dbcache.AccountKeyPrefix:   accounts,
+		dbcache.LiquidityKeyPrefix: liquidities,
+		dbcache.NftKeyPrefix:       nfts,
+	} {
+		prefixDeleted, err := deleteStale(ctx, redisCache, prefix, indexes)
+		if err != nil {
+			return err
+		}
+		deleted += prefixDeleted
+	}
+
+	logx.Infof("redis cache rebuilt at block %d, %d accounts, %d liquidity, %d nfts, %d stale entries deleted",
+		height, len(accounts), len(liquidities), len(nfts), deleted)
+	return nil
+}
+
+// deleteStale deletes the cached states of the prefix whose indexes are not in the database.
+func deleteStale(ctx context.Context, redisCache dbcache.Cache, prefix string, indexes map[int64]bool) (int, error) {
+	deleted := 0
+	err := redisCache.Scan(ctx, prefix+"*", func(key string) error {
+		index, err := strconv.ParseInt(strings.TrimPrefix(key, prefix), 10, 64)
+		if err == nil && indexes[index] {
+			return nil
+		}
+		err = redisCache.Delete(ctx, key)
+		if err != nil {
+			return fmt.Errorf("delete %s failed: %v", key, err)
+		}
+		deleted++
+		return nil
+	})
+	return deleted, err
+}
+
+// Check samples the states cached in redis and compares them with the database, see statedb.CacheChecker. The
+// mismatches are written to the output file, or to stdout if it is empty, as json lines.
+func Check(configFile string, samples int, output string) error {
+	chainDb, redisCache, err := setup(configFile)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		// the logs would be mixed with the mismatches.
+		logx.Disable()
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("create %s failed: %v", output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	encoder := json.NewEncoder(w)
+	mismatches := 0
+	checked, err := sdb.NewCacheChecker(chainDb, redisCache).Sample(samples, func(mismatch *sdb.CacheMismatch) error {
+		mismatches++
+		return encoder.Encode(mismatch)
+	})
+	if err != nil {
+		return fmt.Errorf("check redis cache failed: %v", err)
+	}
+	if mismatches > 0 {
+		return fmt.Errorf("%d of %d cache entries checked are inconsistent", mismatches, checked)
+	}
+	logx.Infof("%d cache entries checked", checked)
+	return nil
+}
+
+func setup(configFile string) (*sdb.ChainDB, dbcache.Cache, error) {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		return nil, nil, fmt.Errorf("gorm connect db failed: %v", err)
+	}
+	redisCache := dbcache.NewRedisCache(c.CacheRedis[0].Host, c.CacheRedis[0].Pass, expiration)
+	return sdb.NewChainDB(db), redisCache, nil
+}
@@ -0,0 +1,14 @@
+package config
+
+import (
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+)
+
+type Config struct {
+	Postgres struct {
+		DataSource string
+	}
+	CacheRedis cache.CacheConf
+	LogConf    logx.LogConf
+}
@@ -0,0 +1,251 @@
+package exit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	common2 "github.com/bnb-chain/zkbas/common"
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/tools/exit/internal/config"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// ExitProof is the proof of an asset or a nft of an account at the last verified block. The siblings of the
+// proofs are ordered from the leaf to the root, the state root is recomputed from the account root together with
+// the liquidity and nft roots.
+type ExitProof struct {
+	BlockHeight   int64        `json:"block_height"`
+	StateRoot     string       `json:"state_root"`
+	LiquidityRoot string       `json:"liquidity_root"`
+	NftRoot       string       `json:"nft_root"`
+	AccountName   string       `json:"account_name"`
+	Account       *ExitAccount `json:"account"`
+	AccountProof  []string     `json:"account_proof"`
+	Asset         *ExitAsset   `json:"asset,omitempty"`
+	AssetProof    []string     `json:"asset_proof,omitempty"`
+	Nft           *ExitNft     `json:"nft,omitempty"`
+	NftProof      []string     `json:"nft_proof,omitempty"`
+}
+
+type ExitAccount struct {
+	Index           int64  `json:"index"`
+	NameHash        string `json:"name_hash"`
+	PubKeyX         string `json:"pub_key_x"`
+	PubKeyY         string `json:"pub_key_y"`
+	Nonce           int64  `json:"nonce"`
+	CollectionNonce int64  `json:"collection_nonce"`
+	AssetRoot       string `json:"asset_root"`
+}
+
+type ExitAsset struct {
+	Id                       int64  `json:"id"`
+	Balance                  string `json:"balance"`
+	LpAmount                 string `json:"lp_amount"`
+	OfferCanceledOrFinalized string `json:"offer_canceled_or_finalized"`
+}
+
+type ExitNft struct {
+	Index               int64  `json:"index"`
+	CreatorAccountIndex int64  `json:"creator_account_index"`
+	OwnerAccountIndex   int64  `json:"owner_account_index"`
+	ContentHash         string `json:"content_hash"`
+	L1Address           string `json:"l1_address"`
+	L1TokenId           string `json:"l1_token_id"`
+	CreatorTreasuryRate int64  `json:"creator_treasury_rate"`
+	CollectionId        int64  `json:"collection_id"`
+}
+
+// ProveAsset outputs the proof of the asset of the account to the output file, or to stdout if it is empty.
+func ProveAsset(configFile, accountName string, assetId int64, batchSize int, output string) error {
+	if assetId < 0 || assetId >= 1<<tree.AssetTreeHeight {
+		return fmt.Errorf("invalid asset id %d", assetId)
+	}
+	return prove(configFile, accountName, batchSize, output,
+		func(statedb *sdb.StateDB, b *block.Block, accountIndex int64) (*ExitProof, error) {
+			return assetExit(statedb, b, accountIndex, assetId)
+		})
+}
+
+// ProveNft outputs the proof of the nft owned by the account to the output file, or to stdout if it is empty.
+func ProveNft(configFile, accountName string, nftIndex int64, batchSize int, output string) error {
+	if nftIndex < 0 || nftIndex >= 1<<tree.NftTreeHeight {
+		return fmt.Errorf("invalid nft index %d", nftIndex)
+	}
+	return prove(configFile, accountName, batchSize, output,
+		func(statedb *sdb.StateDB, b *block.Block, accountIndex int64) (*ExitProof, error) {
+			return nftExit(statedb, b, accountIndex, nftIndex)
+		})
+}
+
+type proveFunc func(statedb *sdb.StateDB, b *block.Block, accountIndex int64) (*ExitProof, error)
+
+// prove rebuilds the state at the last verified block from the history tables, which is the state the contract
+// accepts the exits against once the desert mode is activated.
+func prove(configFile, accountName string, batchSize int, output string, fn proveFunc) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		return fmt.Errorf("gorm connect db failed: %v", err)
+	}
+	chainDb := sdb.NewChainDB(db)
+
+	account, err := chainDb.AccountModel.GetAccountByName(accountName)
+	if err != nil {
+		return fmt.Errorf("get account %s failed: %v", accountName, err)
+	}
+	height, err := chainDb.BlockModel.GetLatestVerifiedHeight()
+	if err != nil {
+		return fmt.Errorf("get latest verified height failed: %v", err)
+	}
+	b, err := chainDb.BlockModel.GetBlockByHeightWithoutTx(height)
+	if err != nil {
+		return fmt.Errorf("get block %d failed: %v", height, err)
+	}
+	logx.Infof("build state at block %d", height)
+	statedb, err := sdb.NewHistoryStateDB(chainDb, "exit", height, batchSize)
+	if err != nil {
+		return err
+	}
+
+	proof, err := fn(statedb, b, account.AccountIndex)
+	if err != nil {
+		return err
+	}
+	proof.AccountName = accountName
+	proofBytes, err := json.MarshalIndent(proof, "", "  ")
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		fmt.Println(string(proofBytes))
+		return nil
+	}
+	err = os.WriteFile(output, proofBytes, 0600)
+	if err != nil {
+		return fmt.Errorf("write %s failed: %v", output, err)
+	}
+	logx.Infof("exit proof of account %s at block %d is written to %s", accountName, height, output)
+	return nil
+}
+
+func assetExit(statedb *sdb.StateDB, b *block.Block, accountIndex, assetId int64) (*ExitProof, error) {
+	if _, ok := statedb.AccountMap[accountIndex]; !ok {
+		return nil, fmt.Errorf("account %d is not registered at block %d", accountIndex, b.BlockHeight)
+	}
+	accountProof, assetProof, err := statedb.GetAccountAssetProof(accountIndex, assetId)
+	if err != nil {
+		return nil, err
+	}
+	account, err := exitAccount(statedb, accountIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, lpAmount, offerCanceledOrFinalized := types.ZeroBigInt, types.ZeroBigInt, types.ZeroBigInt
+	if asset, ok := statedb.AccountMap[accountIndex].AssetInfo[assetId]; ok {
+		balance, lpAmount, offerCanceledOrFinalized = asset.Balance, asset.LpAmount, asset.OfferCanceledOrFinalized
+	}
+	return &ExitProof{
+		BlockHeight:   b.BlockHeight,
+		StateRoot:     b.StateRoot,
+		LiquidityRoot: hexutil.Encode(statedb.LiquidityTree.Root()),
+		NftRoot:       hexutil.Encode(statedb.NftTree.Root()),
+		Account:       account,
+		AccountProof:  encodeSiblings(accountProof.Siblings),
+		Asset: &ExitAsset{
+			Id:                       assetId,
+			Balance:                  balance.String(),
+			LpAmount:                 lpAmount.String(),
+			OfferCanceledOrFinalized: offerCanceledOrFinalized.String(),
+		},
+		AssetProof: encodeSiblings(assetProof.Siblings),
+	}, nil
+}
+
+func nftExit(statedb *sdb.StateDB, b *block.Block, accountIndex, nftIndex int64) (*ExitProof, error) {
+	if _, ok := statedb.AccountMap[accountIndex]; !ok {
+		return nil, fmt.Errorf("account %d is not registered at block %d", accountIndex, b.BlockHeight)
+	}
+	nft, ok := statedb.NftMap[nftIndex]
+	if !ok {
+		return nil, fmt.Errorf("nft %d is not found at block %d", nftIndex, b.BlockHeight)
+	}
+	if nft.OwnerAccountIndex != accountIndex {
+		return nil, fmt.Errorf("nft %d is owned by account %d", nftIndex, nft.OwnerAccountIndex)
+	}
+	nftProof, err := statedb.GetNftProof(nftIndex)
+	if err != nil {
+		return nil, err
+	}
+	// The asset of the account is unused, the proof of the account is the one checked.
+	accountProof, _, err := statedb.GetAccountAssetProof(accountIndex, 0)
+	if err != nil {
+		return nil, err
+	}
+	account, err := exitAccount(statedb, accountIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExitProof{
+		BlockHeight:   b.BlockHeight,
+		StateRoot:     b.StateRoot,
+		LiquidityRoot: hexutil.Encode(statedb.LiquidityTree.Root()),
+		NftRoot:       hexutil.Encode(statedb.NftTree.Root()),
+		Account:       account,
+		AccountProof:  encodeSiblings(accountProof.Siblings),
+		Nft: &ExitNft{
+			Index:               nftIndex,
+			CreatorAccountIndex: nft.CreatorAccountIndex,
+			OwnerAccountIndex:   nft.OwnerAccountIndex,
+			ContentHash:         nft.NftContentHash,
+			L1Address:           nft.NftL1Address,
+			L1TokenId:           nft.NftL1TokenId,
+			CreatorTreasuryRate: nft.CreatorTreasuryRate,
+			CollectionId:        nft.CollectionId,
+		},
+		NftProof: encodeSiblings(nftProof.Siblings),
+	}, nil
+}
+
+func exitAccount(statedb *sdb.StateDB, accountIndex int64) (*ExitAccount, error) {
+	account := statedb.AccountMap[accountIndex]
+	pk, err := common2.ParsePubKey(account.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of account %d: %v", accountIndex, err)
+	}
+	assetTree, err := statedb.AccountAssetTrees.Get(accountIndex)
+	if err != nil {
+		return nil, fmt.Errorf("get asset tree of account %d failed: %v", accountIndex, err)
+	}
+	return &ExitAccount{
+		Index:           accountIndex,
+		NameHash:        account.AccountNameHash,
+		PubKeyX:         pk.A.X.String(),
+		PubKeyY:         pk.A.Y.String(),
+		Nonce:           account.Nonce,
+		CollectionNonce: account.CollectionNonce,
+		AssetRoot:       hexutil.Encode(assetTree.Root()),
+	}, nil
+}
+
+func encodeSiblings(siblings [][]byte) []string {
+	res := make([]string, 0, len(siblings))
+	for _, sibling := range siblings {
+		res = append(res, hexutil.Encode(sibling))
+	}
+	return res
+}
@@ -0,0 +1,154 @@
+package exit
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbas-crypto/hash/bn254/zmimc"
+	bsmt "github.com/bnb-chain/zkbas-smt"
+	"github.com/bnb-chain/zkbas-smt/database/memory"
+	common2 "github.com/bnb-chain/zkbas/common"
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+const testPk = "58130e24cd20d9de8a110a20751f0a9b36089400ac0f20ca1993c28ee663318a"
+
+func newMemTree(t *testing.T, depth uint8, nilHash []byte) bsmt.SparseMerkleTree {
+	smt, err := bsmt.NewBASSparseMerkleTree(bsmt.NewHasher(zmimc.Hmimc), memory.NewMemoryDB(), depth, nilHash)
+	assert.NoError(t, err)
+	return smt
+}
+
+// newTestState returns the state with the account 1 holding 100 of the asset 2 and the nft 3, and the block
+// committing to the state.
+func newTestState(t *testing.T) (*sdb.StateDB, *block.Block) {
+	statedb := sdb.NewStateDBForDryRun(nil, nil)
+	statedb.AccountTree = newMemTree(t, tree.AccountTreeHeight, tree.NilAccountNodeHash)
+	statedb.LiquidityTree = newMemTree(t, tree.LiquidityTreeHeight, tree.NilLiquidityNodeHash)
+	statedb.NftTree = newMemTree(t, tree.NftTreeHeight, tree.NilNftNodeHash)
+	assetTrees, err := tree.NewAssetTreeCache(&tree.Context{Driver: tree.MemoryDB}, 0, 0)
+	assert.NoError(t, err)
+	for i := int64(0); i < 2; i++ {
+		_, err = assetTrees.AddAccount(i)
+		assert.NoError(t, err)
+	}
+	statedb.AccountAssetTrees = assetTrees
+	assetTree, err := assetTrees.GetForUpdate(1)
+	assert.NoError(t, err)
+
+	statedb.AccountMap[1] = &types.AccountInfo{
+		AccountIndex:    1,
+		AccountNameHash: "0x04b2ea4f6f9a6b6a8b6c7e2e2e24bbf0c7f2e1a1d2c3b4a5968778695a4b3c2d",
+		PublicKey:       testPk,
+		Nonce:           3,
+		AssetInfo: map[int64]*types.AccountAsset{
+			2: {AssetId: 2, Balance: big.NewInt(100), LpAmount: big.NewInt(0), OfferCanceledOrFinalized: big.NewInt(0)},
+		},
+	}
+	assetLeaf, err := tree.ComputeAccountAssetLeafHash("100", "0", "0")
+	assert.NoError(t, err)
+	assert.NoError(t, assetTree.Set(2, assetLeaf))
+	accountLeaf, err := tree.ComputeAccountLeafHash(statedb.AccountMap[1].AccountNameHash, testPk, 3, 0,
+		assetTree.Root())
+	assert.NoError(t, err)
+	assert.NoError(t, statedb.AccountTree.Set(1, accountLeaf))
+
+	statedb.NftMap[3] = &nft.L2Nft{
+		NftIndex: 3, CreatorAccountIndex: 1, OwnerAccountIndex: 1, NftContentHash: "0x01",
+		NftL1Address: "0x0000000000000000000000000000000000000000", NftL1TokenId: "0", CreatorTreasuryRate: 10,
+	}
+	nftLeaf, err := tree.ComputeNftAssetLeafHash(1, 1, "0x01", "0x0000000000000000000000000000000000000000", "0", 10, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, statedb.NftTree.Set(3, nftLeaf))
+
+	return statedb, &block.Block{
+		Model:       gorm.Model{CreatedAt: time.Now()},
+		BlockSize:   1,
+		BlockHeight: 10,
+		StateRoot:   statedb.GetStateRoot(),
+	}
+}
+
+func decodeSiblings(t *testing.T, siblings []string) [][]byte {
+	res := make([][]byte, 0, len(siblings))
+	for _, sibling := range siblings {
+		b, err := hexutil.Decode(sibling)
+		assert.NoError(t, err)
+		res = append(res, b)
+	}
+	return res
+}
+
+func decode(t *testing.T, s string) []byte {
+	b, err := hexutil.Decode(s)
+	assert.NoError(t, err)
+	return b
+}
+
+// accountRoot verifies the account leaf and returns the root of the account tree computed from its proof.
+func accountRoot(t *testing.T, proof *ExitProof) []byte {
+	pk, err := common2.ParsePubKey(testPk)
+	assert.NoError(t, err)
+	assert.Equal(t, pk.A.X.String(), proof.Account.PubKeyX)
+	assert.Equal(t, pk.A.Y.String(), proof.Account.PubKeyY)
+	assert.Len(t, proof.AccountProof, tree.AccountTreeHeight)
+	accountLeaf, err := tree.ComputeAccountLeafHash(proof.Account.NameHash, testPk, proof.Account.Nonce,
+		proof.Account.CollectionNonce, decode(t, proof.Account.AssetRoot))
+	assert.NoError(t, err)
+	return tree.ComputeMerkleRoot(uint64(proof.Account.Index), accountLeaf, decodeSiblings(t, proof.AccountProof))
+}
+
+func TestAssetExit(t *testing.T) {
+	statedb, b := newTestState(t)
+
+	proof, err := assetExit(statedb, b, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), proof.BlockHeight)
+	assert.Equal(t, int64(1), proof.Account.Index)
+	assert.Equal(t, "100", proof.Asset.Balance)
+	assert.Len(t, proof.AssetProof, tree.AssetTreeHeight)
+
+	assetLeaf, err := tree.ComputeAccountAssetLeafHash(proof.Asset.Balance, proof.Asset.LpAmount,
+		proof.Asset.OfferCanceledOrFinalized)
+	assert.NoError(t, err)
+	assert.Equal(t, decode(t, proof.Account.AssetRoot),
+		tree.ComputeMerkleRoot(uint64(proof.Asset.Id), assetLeaf, decodeSiblings(t, proof.AssetProof)))
+	stateRoot := tree.ComputeStateRootHash(accountRoot(t, proof), decode(t, proof.LiquidityRoot),
+		decode(t, proof.NftRoot))
+	assert.Equal(t, common.FromHex(proof.StateRoot), stateRoot)
+
+	_, err = assetExit(statedb, b, 2, 2)
+	assert.Error(t, err)
+}
+
+func TestNftExit(t *testing.T) {
+	statedb, b := newTestState(t)
+
+	proof, err := nftExit(statedb, b, 1, 3)
+	assert.NoError(t, err)
+	assert.Nil(t, proof.Asset)
+	assert.Len(t, proof.NftProof, tree.NftTreeHeight)
+
+	nftLeaf, err := tree.ComputeNftAssetLeafHash(proof.Nft.CreatorAccountIndex, proof.Nft.OwnerAccountIndex,
+		proof.Nft.ContentHash, proof.Nft.L1Address, proof.Nft.L1TokenId, proof.Nft.CreatorTreasuryRate,
+		proof.Nft.CollectionId)
+	assert.NoError(t, err)
+	nftRoot := tree.ComputeMerkleRoot(uint64(proof.Nft.Index), nftLeaf, decodeSiblings(t, proof.NftProof))
+	assert.Equal(t, decode(t, proof.NftRoot), nftRoot)
+	stateRoot := tree.ComputeStateRootHash(accountRoot(t, proof), decode(t, proof.LiquidityRoot), nftRoot)
+	assert.Equal(t, common.FromHex(proof.StateRoot), stateRoot)
+
+	statedb.NftMap[3].OwnerAccountIndex = 2
+	_, err = nftExit(statedb, b, 1, 3)
+	assert.EqualError(t, err, "nft 3 is owned by account 2")
+}
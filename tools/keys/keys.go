@@ -0,0 +1,148 @@
+package keys
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas/common/prove"
+)
+
+// KeyFiles returns the proving and verifying key files of the block size in the dir, they are named as the ones
+// exported by zkbas-crypto.
+func KeyFiles(dir string, blockSize int) (string, string) {
+	return filepath.Join(dir, fmt.Sprintf("zkbas%d.pk", blockSize)),
+		filepath.Join(dir, fmt.Sprintf("zkbas%d.vk", blockSize))
+}
+
+// Setup generates the keys of the block sizes into the dir. The setup is not a ceremony, the keys are only for the
+// development and the tests.
+func Setup(blockSizes []int, dir string, cacheDir string) error {
+	logx.DisableStat()
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	for _, blockSize := range blockSizes {
+		ccs, err := prove.CompileBlockCircuit(blockSize, cacheDir)
+		if err != nil {
+			return fmt.Errorf("compile block size %d circuit failed: %v", blockSize, err)
+		}
+		provingKeyFile, verifyingKeyFile := KeyFiles(dir, blockSize)
+		logx.Infof("setup the keys of block size %d, it is unsafe for production", blockSize)
+		err = prove.SetupKeys(ccs, provingKeyFile, verifyingKeyFile)
+		if err != nil {
+			return fmt.Errorf("setup the keys of block size %d failed: %v", blockSize, err)
+		}
+		logx.Infof("the keys of block size %d are written to %s and %s", blockSize, provingKeyFile,
+			verifyingKeyFile)
+	}
+	return nil
+}
+
+// Check checks the keys of the block sizes in the dir against the block circuits, see prove.CheckKeys.
+func Check(blockSizes []int, dir string, cacheDir string) error {
+	logx.DisableStat()
+	failed := 0
+	for _, blockSize := range blockSizes {
+		ccs, err := prove.CompileBlockCircuit(blockSize, cacheDir)
+		if err != nil {
+			return fmt.Errorf("compile block size %d circuit failed: %v", blockSize, err)
+		}
+		provingKeyFile, verifyingKeyFile := KeyFiles(dir, blockSize)
+		err = prove.CheckKeys(ccs, provingKeyFile, verifyingKeyFile)
+		if err != nil {
+			logx.Errorf("the keys of block size %d don't match the circuit: %v", blockSize, err)
+			failed++
+			continue
+		}
+		logx.Infof("the keys of block size %d match the circuit", blockSize)
+	}
+	if failed > 0 {
+		return fmt.Errorf("the keys of %d of %d block sizes don't match the circuits", failed, len(blockSizes))
+	}
+	return nil
+}
+
+// ExportVerifier writes the verifyingKey and ic functions of the verifier contract with the verifying keys of the
+// block sizes in the dir, to the output file, or to stdout if it is empty.
+func ExportVerifier(blockSizes []int, dir string, output string) error {
+	if len(blockSizes) == 0 {
+		return fmt.Errorf("no block size to export")
+	}
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("create %s failed: %v", output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	vks := make([][]string, len(blockSizes))
+	ics := make([][]string, len(blockSizes))
+	for i, blockSize := range blockSizes {
+		_, verifyingKeyFile := KeyFiles(dir, blockSize)
+		vkConstants, icConstants, err := prove.VerifierConstants(verifyingKeyFile)
+		if err != nil {
+			return fmt.Errorf("read verifying key %s failed: %v", verifyingKeyFile, err)
+		}
+		for _, c := range vkConstants {
+			vks[i] = append(vks[i], c.String())
+		}
+		for _, c := range icConstants {
+			ics[i] = append(ics[i], c.String())
+		}
+	}
+	return writeVerifier(w, blockSizes, vks, ics)
+}
+
+func writeVerifier(w io.Writer, blockSizes []int, vks, ics [][]string) error {
+	p := &printer{w: w}
+	p.printf("    function verifyingKey(uint16 block_size) internal pure returns (uint256[%d] memory vk) {\n",
+		len(vks[0]))
+	for i, blockSize := range blockSizes {
+		p.printBranch(i, blockSize)
+		for j, c := range vks[i] {
+			p.printf("            vk[%d] = %s;\n", j, c)
+		}
+		p.printf("            return vk;\n")
+	}
+	p.printf("        } else {\n            revert(\"u\");\n        }\n    }\n\n")
+
+	p.printf("    function ic(uint16 block_size) internal pure returns (uint256[] memory gammaABC) {\n")
+	for i, blockSize := range blockSizes {
+		p.printBranch(i, blockSize)
+		p.printf("            gammaABC = new uint256[](%d);\n", len(ics[i]))
+		for j, c := range ics[i] {
+			p.printf("            gammaABC[%d] = %s;\n", j, c)
+		}
+		p.printf("            return gammaABC;\n")
+	}
+	p.printf("        } else {\n            revert(\"u\");\n        }\n    }\n")
+	return p.err
+}
+
+// printer keeps the first error of the writes.
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) printf(format string, a ...interface{}) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, a...)
+	}
+}
+
+func (p *printer) printBranch(i int, blockSize int) {
+	if i == 0 {
+		p.printf("        if (block_size == %d) {\n", blockSize)
+		return
+	}
+	p.printf("        } else if (block_size == %d) {\n", blockSize)
+}
@@ -0,0 +1,56 @@
+package keys
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/common/prove"
+)
+
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+func TestExportVerifier(t *testing.T) {
+	ccs, err := frontend.Compile(ecc.BN254, r1cs.NewBuilder, &squareCircuit{})
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	for _, blockSize := range []int{1, 10} {
+		provingKeyFile, verifyingKeyFile := KeyFiles(dir, blockSize)
+		assert.NoError(t, prove.SetupKeys(ccs, provingKeyFile, verifyingKeyFile))
+	}
+
+	output := filepath.Join(dir, "verifier.sol")
+	assert.NoError(t, ExportVerifier([]int{1, 10}, dir, output))
+	buf, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	verifier := string(buf)
+
+	for _, blockSize := range []int{1, 10} {
+		_, verifyingKeyFile := KeyFiles(dir, blockSize)
+		vkConstants, icConstants, err := prove.VerifierConstants(verifyingKeyFile)
+		assert.NoError(t, err)
+		assert.Contains(t, verifier, "vk[13] = "+vkConstants[13].String()+";")
+		assert.Contains(t, verifier, "gammaABC[3] = "+icConstants[3].String()+";")
+	}
+	assert.True(t, strings.HasPrefix(verifier,
+		"    function verifyingKey(uint16 block_size) internal pure returns (uint256[14] memory vk) {\n"+
+			"        if (block_size == 1) {\n"))
+	assert.Contains(t, verifier, "        } else if (block_size == 10) {\n            gammaABC = new uint256[](4);\n")
+	assert.Equal(t, 2, strings.Count(verifier, "revert(\"u\");"))
+
+	assert.Error(t, ExportVerifier([]int{100}, dir, output))
+}
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas/tree"
+)
+
+type Config struct {
+	TreeDB struct {
+		// the leveldb tree db to be copied
+		LevelDBOption tree.LevelDBOption
+		// the pebble tree db to be created
+		PebbleDBOption tree.PebbleDBOption
+	}
+	LogConf logx.LogConf
+}
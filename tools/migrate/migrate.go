@@ -0,0 +1,136 @@
+package migrate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas/tools/migrate/internal/config"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/tree/pebbledb"
+)
+
+const migrateBatchSizeLimit = 4 * 1024 * 1024
+
+// MigrateTreeDB copies the leveldb tree db in the config to a new pebble tree db, the trees of all the services in
+// the leveldb are copied. The keys are wrapped with the namespaces the same way by both drivers, so they are copied
+// as they are, and every key is read back from the pebble afterwards. The services must be stopped.
+func MigrateTreeDB(configFile string) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+
+	start := time.Now()
+	keys, err := migrate(&c.TreeDB.LevelDBOption, &c.TreeDB.PebbleDBOption)
+	if err != nil {
+		return fmt.Errorf("migrate %s to %s failed: %v", c.TreeDB.LevelDBOption.File, c.TreeDB.PebbleDBOption.File,
+			err)
+	}
+	logx.Infof("%d keys are migrated from %s to %s in %v, set the driver of the services to %s", keys,
+		c.TreeDB.LevelDBOption.File, c.TreeDB.PebbleDBOption.File, time.Since(start), tree.PebbleDB)
+	return nil
+}
+
+func migrate(from *tree.LevelDBOption, to *tree.PebbleDBOption) (uint64, error) {
+	src, err := leveldb.OpenFile(from.File, &opt.Options{
+		ReadOnly:       true,
+		ErrorIfMissing: true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	dst, err := pebbledb.New(to.File, to.Cache, to.Handles, false)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close()
+	empty, err := dst.IsEmpty()
+	if err != nil {
+		return 0, err
+	}
+	if !empty {
+		return 0, errors.New("pebble tree db is not empty")
+	}
+
+	keys, err := copyKeys(src, dst)
+	if err != nil {
+		return 0, err
+	}
+	logx.Infof("%d keys are copied, verify them", keys)
+	err = verifyKeys(src, dst, keys)
+	if err != nil {
+		return 0, err
+	}
+	return keys, nil
+}
+
+// copyKeys copies all the keys of the leveldb to the pebble without namespace.
+func copyKeys(src *leveldb.DB, dst *pebbledb.Database) (uint64, error) {
+	iter := src.NewIterator(nil, nil)
+	defer iter.Release()
+
+	var keys uint64
+	batch := dst.NewBatch()
+	for iter.Next() {
+		// the iterator reuses the buffers of the key and the value, they are copied by the batch.
+		err := batch.Set(iter.Key(), iter.Value())
+		if err != nil {
+			return 0, err
+		}
+		keys++
+		if batch.ValueSize() > migrateBatchSizeLimit {
+			err = batch.Write()
+			if err != nil {
+				return 0, err
+			}
+			batch.Reset()
+			logx.Infof("%d keys are copied", keys)
+		}
+	}
+	err := iter.Error()
+	if err != nil {
+		return 0, err
+	}
+	err = batch.Write()
+	if err != nil {
+		return 0, err
+	}
+	return keys, dst.Flush()
+}
+
+// verifyKeys reads back every key of the leveldb from the pebble, and checks the pebble has no other keys.
+func verifyKeys(src *leveldb.DB, dst *pebbledb.Database, keys uint64) error {
+	iter := src.NewIterator(nil, nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		value, err := dst.Get(iter.Key())
+		if err != nil {
+			return fmt.Errorf("get key %x failed: %v", iter.Key(), err)
+		}
+		if !bytes.Equal(value, iter.Value()) {
+			return fmt.Errorf("value of key %x mismatches", iter.Key())
+		}
+	}
+	err := iter.Error()
+	if err != nil {
+		return err
+	}
+	count, err := dst.Count()
+	if err != nil {
+		return err
+	}
+	if count != keys {
+		return fmt.Errorf("%d keys are copied, but %d keys in pebble", keys, count)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package migrate
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/tree"
+)
+
+func TestMigrate(t *testing.T) {
+	levelDBOption := &tree.LevelDBOption{File: t.TempDir()}
+	ctx := &tree.Context{
+		Name:          "committer",
+		Driver:        tree.LevelDB,
+		LevelDBOption: levelDBOption,
+	}
+	assert.NoError(t, tree.SetupTreeDB(ctx))
+	accountTree, err := tree.NewEmptyAccountTree(ctx, 0)
+	assert.NoError(t, err)
+	assetTree, err := tree.NewEmptyAccountAssetTree(ctx, 0, 0)
+	assert.NoError(t, err)
+	for i := int64(1); i <= 3; i++ {
+		assert.NoError(t, accountTree.Set(uint64(i), common.LeftPadBytes(big.NewInt(i).Bytes(), 32)))
+		_, err = accountTree.Commit(nil)
+		assert.NoError(t, err)
+		assert.NoError(t, assetTree.Set(uint64(i), common.LeftPadBytes(big.NewInt(i).Bytes(), 32)))
+		_, err = assetTree.Commit(nil)
+		assert.NoError(t, err)
+	}
+	accountRoot, assetRoot := accountTree.Root(), assetTree.Root()
+	assert.NoError(t, ctx.TreeDB.Close())
+
+	pebbleDBOption := &tree.PebbleDBOption{File: t.TempDir()}
+	keys, err := migrate(levelDBOption, pebbleDBOption)
+	assert.NoError(t, err)
+	assert.True(t, keys > 0)
+	// the pebble tree db must be empty.
+	_, err = migrate(levelDBOption, pebbleDBOption)
+	assert.EqualError(t, err, "pebble tree db is not empty")
+
+	ctx = &tree.Context{
+		Name:           "committer",
+		Driver:         tree.PebbleDB,
+		PebbleDBOption: pebbleDBOption,
+	}
+	assert.NoError(t, tree.SetupTreeDB(ctx))
+	defer ctx.TreeDB.Close()
+	accountTree, err = tree.NewEmptyAccountTree(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, accountRoot, accountTree.Root())
+	assetTree, err = tree.NewEmptyAccountAssetTree(ctx, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, assetRoot, assetTree.Root())
+	assert.Equal(t, uint64(3), uint64(assetTree.LatestVersion()))
+
+	// the trees keep their versions.
+	assert.NoError(t, accountTree.Rollback(1))
+	accountTree, err = tree.NewEmptyAccountTree(ctx, 0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, accountRoot, accountTree.Root())
+}
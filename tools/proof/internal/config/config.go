@@ -0,0 +1,18 @@
+package config
+
+import (
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type Config struct {
+	Postgres struct {
+		DataSource string
+	}
+	LogConf logx.LogConf
+	KeyPath struct {
+		VerifyingKeyPath []string
+	}
+	BlockConfig struct {
+		OptionalBlockSizes []int
+	}
+}
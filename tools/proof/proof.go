@@ -0,0 +1,76 @@
+package proof
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	cryptoBlock "github.com/bnb-chain/zkbas-crypto/legend/circuit/bn254/block"
+	"github.com/bnb-chain/zkbas/common/prove"
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/blockwitness"
+	"github.com/bnb-chain/zkbas/dao/proof"
+	"github.com/bnb-chain/zkbas/tools/proof/internal/config"
+)
+
+// Verify verifies the proof of the block stored in the proof table. The public inputs of the proof are the state roots
+// and the commitment in the witness, they are checked against the blocks as well.
+func Verify(configFile string, height int64) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		return fmt.Errorf("gorm connect db failed: %v", err)
+	}
+	blockModel := block.NewBlockModel(db)
+
+	p, err := proof.NewProofModel(db).GetProofByBlockNumber(height)
+	if err != nil {
+		return fmt.Errorf("get proof of height %d failed: %v", height, err)
+	}
+	w, err := blockwitness.NewBlockWitnessModel(db).GetBlockWitnessByNumber(height)
+	if err != nil {
+		return fmt.Errorf("get block witness of height %d failed: %v", height, err)
+	}
+	var cBlock *cryptoBlock.Block
+	err = json.Unmarshal([]byte(w.WitnessData), &cBlock)
+	if err != nil {
+		return fmt.Errorf("unmarshal block witness of height %d failed: %v", height, err)
+	}
+
+	err = prove.VerifyWitnessBlock(blockModel, height, cBlock)
+	if err != nil {
+		return err
+	}
+
+	if len(c.KeyPath.VerifyingKeyPath) != len(c.BlockConfig.OptionalBlockSizes) {
+		return fmt.Errorf("the verifying keys don't match the block sizes")
+	}
+	keyIndex := -1
+	for i, blockSize := range c.BlockConfig.OptionalBlockSizes {
+		if blockSize == len(cBlock.Txs) {
+			keyIndex = i
+		}
+	}
+	if keyIndex < 0 {
+		return fmt.Errorf("can't find correct vk for block size %d", len(cBlock.Txs))
+	}
+	verifyingKey, err := prove.LoadVerifyingKey(c.KeyPath.VerifyingKeyPath[keyIndex])
+	if err != nil {
+		return fmt.Errorf("load verifying key %s failed: %v", c.KeyPath.VerifyingKeyPath[keyIndex], err)
+	}
+
+	err = prove.VerifyProofInfo(p.ProofInfo, verifyingKey, cBlock)
+	if err != nil {
+		return fmt.Errorf("proof of height %d generated by prover %s is invalid: %v", height, p.Prover, err)
+	}
+	logx.Infof("proof of height %d generated by prover %s is valid", height, p.Prover)
+	return nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/bnb-chain/zkbas/tree"
+)
+
+type Config struct {
+	Postgres struct {
+		DataSource string
+	}
+	TreeDB struct {
+		Driver tree.Driver
+		//nolint:staticcheck
+		LevelDBOption tree.LevelDBOption `json:",optional"`
+		//nolint:staticcheck
+		PebbleDBOption tree.PebbleDBOption `json:",optional"`
+		//nolint:staticcheck
+		RedisDBOption tree.RedisDBOption `json:",optional"`
+		//nolint:staticcheck
+		Prune tree.PruneConfig `json:",optional"`
+	}
+	LogConf logx.LogConf
+}
@@ -0,0 +1,66 @@
+package prune
+
+import (
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/proof"
+	"github.com/bnb-chain/zkbas/tools/prune/internal/config"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// PruneTreeDB sweeps the trees of the service in the tree db by the pruning policy in the config. The service must
+// be stopped, as the sweeping must not interleave with its commits.
+func PruneTreeDB(configFile string, serviceName string) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		return fmt.Errorf("gorm connect db failed: %v", err)
+	}
+
+	treeCtx := &tree.Context{
+		Name:           serviceName,
+		Driver:         c.TreeDB.Driver,
+		LevelDBOption:  &c.TreeDB.LevelDBOption,
+		PebbleDBOption: &c.TreeDB.PebbleDBOption,
+		RedisDBOption:  &c.TreeDB.RedisDBOption,
+	}
+	err = tree.SetupTreeDB(treeCtx)
+	if err != nil {
+		return fmt.Errorf("init tree database failed: %v", err)
+	}
+
+	pruner := tree.NewPruner(treeCtx, c.TreeDB.Prune, block.NewBlockModel(db), serviceFloor(db, serviceName))
+	return pruner.Prune()
+}
+
+// serviceFloor returns the lowest block height the service may roll back its trees to.
+func serviceFloor(db *gorm.DB, serviceName string) tree.PruneFloor {
+	switch serviceName {
+	case "witness":
+		// the witness rolls back its trees to the last confirmed proof on restart.
+		proofModel := proof.NewProofModel(db)
+		return func() (int64, error) {
+			p, err := proofModel.GetLatestConfirmedProof()
+			if err == types.DbErrNotFound {
+				return 0, nil
+			}
+			if err != nil {
+				return 0, err
+			}
+			return p.BlockNumber, nil
+		}
+	default:
+		return nil
+	}
+}
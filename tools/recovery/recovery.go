@@ -16,6 +16,7 @@ func RecoveryTreeDB(
 	blockHeight int64,
 	serviceName string,
 	batchSize int,
+	workers int,
 ) {
 	var c config.Config
 	conf.MustLoad(configFile, &c)
@@ -28,14 +29,16 @@ func RecoveryTreeDB(
 
 	// dbinitializer tree database
 	treeCtx := &tree.Context{
-		Name:          serviceName,
-		Driver:        c.TreeDB.Driver,
-		LevelDBOption: &c.TreeDB.LevelDBOption,
-		RedisDBOption: &c.TreeDB.RedisDBOption,
-		Reload:        true,
+		Name:           serviceName,
+		Driver:         c.TreeDB.Driver,
+		LevelDBOption:  &c.TreeDB.LevelDBOption,
+		PebbleDBOption: &c.TreeDB.PebbleDBOption,
+		RedisDBOption:  &c.TreeDB.RedisDBOption,
+		Reload:         true,
 	}
 	treeCtx.SetOptions(bsmt.InitializeVersion(bsmt.Version(blockHeight) - 1))
 	treeCtx.SetBatchReloadSize(batchSize)
+	treeCtx.SetReloadWorkers(workers)
 	err := tree.SetupTreeDB(treeCtx)
 	if err != nil {
 		logx.Errorf("Init tree database failed: %s", err)
@@ -71,4 +74,9 @@ func RecoveryTreeDB(
 		logx.Errorf("InitNftTree error: %s", err.Error())
 		return
 	}
+	err = treeCtx.FinishReload()
+	if err != nil {
+		logx.Errorf("Finish reload error: %s", err.Error())
+		return
+	}
 }
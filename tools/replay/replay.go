@@ -0,0 +1,274 @@
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbas-crypto/wasm/legend/legendTxTypes"
+	"github.com/bnb-chain/zkbas/core/executor"
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/tx"
+	"github.com/bnb-chain/zkbas/tools/replay/internal/config"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// Replay re-executes the blocks in [fromHeight, toHeight] on the state at fromHeight-1, which is loaded from
+// the history tables into memory trees, and checks the state root after every tx. It stops at the first
+// diverging tx and reports the tx details, i.e. the leaves touched by the tx, which differ from the recorded ones.
+func Replay(configFile string, fromHeight, toHeight int64, batchSize int) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+
+	if fromHeight < 1 || toHeight < fromHeight {
+		return fmt.Errorf("invalid block range [%d, %d]", fromHeight, toHeight)
+	}
+
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		return fmt.Errorf("gorm connect db failed: %v", err)
+	}
+	r, err := newReplayer(sdb.NewChainDB(db), fromHeight-1, batchSize)
+	if err != nil {
+		return err
+	}
+	for height := fromHeight; height <= toHeight; height++ {
+		err = r.replayBlock(height)
+		if err != nil {
+			return err
+		}
+	}
+
+	logx.Infof("replayed blocks [%d, %d], all state roots match", fromHeight, toHeight)
+	return nil
+}
+
+// replayer is the blockchain seen by the executors during the replay.
+type replayer struct {
+	chainDb      *sdb.ChainDB
+	statedb      *sdb.StateDB
+	currentBlock *block.Block
+}
+
+func newReplayer(chainDb *sdb.ChainDB, height int64, batchSize int) (*replayer, error) {
+	parentBlock, err := chainDb.BlockModel.GetBlockByHeightWithoutTx(height)
+	if err != nil {
+		return nil, fmt.Errorf("get block %d failed: %v", height, err)
+	}
+
+	statedb, err := sdb.NewHistoryStateDB(chainDb, "replay", height, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// The executors query the replayed state instead of the latest one.
+	chainDb.AccountModel = &accountModel{AccountModel: chainDb.AccountModel, statedb: statedb}
+	chainDb.LiquidityModel = &liquidityModel{LiquidityModel: chainDb.LiquidityModel, statedb: statedb}
+	chainDb.L2NftModel = &nftModel{L2NftModel: chainDb.L2NftModel, statedb: statedb}
+
+	return &replayer{
+		chainDb:      chainDb,
+		statedb:      statedb,
+		currentBlock: parentBlock,
+	}, nil
+}
+
+func (r *replayer) VerifyExpiredAt(expiredAt int64) error {
+	if expiredAt < r.currentBlock.CreatedAt.UnixMilli() {
+		return errors.New("invalid ExpiredAt")
+	}
+	return nil
+}
+
+func (r *replayer) VerifyNonce(accountIndex int64, nonce int64) error {
+	expectNonce, err := r.statedb.GetCommittedNonce(accountIndex)
+	if err != nil {
+		return err
+	}
+	if nonce != expectNonce {
+		return errors.New("invalid Nonce")
+	}
+	return nil
+}
+
+func (r *replayer) VerifySignature(txInfo legendTxTypes.TxInfo) error {
+	return txInfo.VerifySignature(r.statedb.AccountMap[txInfo.GetFromAccountIndex()].PublicKey)
+}
+
+func (r *replayer) StateDB() *sdb.StateDB {
+	return r.statedb
+}
+
+func (r *replayer) DB() *sdb.ChainDB {
+	return r.chainDb
+}
+
+func (r *replayer) CurrentBlock() *block.Block {
+	return r.currentBlock
+}
+
+func (r *replayer) replayBlock(height int64) error {
+	b, err := r.chainDb.BlockModel.GetBlockByHeight(height)
+	if err != nil {
+		return fmt.Errorf("get block %d failed: %v", height, err)
+	}
+	r.currentBlock = b
+
+	for _, expectedTx := range b.Txs {
+		replayedTx, err := r.replayTx(expectedTx)
+		if err != nil {
+			return fmt.Errorf("replay tx %s at block %d index %d failed: %v", expectedTx.TxHash, height,
+				expectedTx.TxIndex, err)
+		}
+		if replayedTx.StateRoot != expectedTx.StateRoot {
+			r.reportDivergence(expectedTx, replayedTx)
+			return fmt.Errorf("state root diverges at tx %s at block %d index %d, expected %s, got %s",
+				expectedTx.TxHash, height, expectedTx.TxIndex, expectedTx.StateRoot, replayedTx.StateRoot)
+		}
+	}
+
+	err = tree.CommitTrees(uint64(height), r.statedb.AccountTree, r.statedb.AccountAssetTrees,
+		r.statedb.LiquidityTree, r.statedb.NftTree)
+	if err != nil {
+		return err
+	}
+	if stateRoot := r.statedb.GetStateRoot(); stateRoot != b.StateRoot {
+		return fmt.Errorf("state root diverges at block %d, expected %s, got %s", height, b.StateRoot, stateRoot)
+	}
+	r.statedb.PurgeCache(b.StateRoot)
+
+	logx.Infof("block %d replayed, txs: %d", height, len(b.Txs))
+	return nil
+}
+
+func (r *replayer) replayTx(expectedTx *tx.Tx) (*tx.Tx, error) {
+	replayedTx := &tx.Tx{
+		TxHash:        expectedTx.TxHash,
+		TxType:        expectedTx.TxType,
+		GasFee:        expectedTx.GasFee,
+		GasFeeAssetId: expectedTx.GasFeeAssetId,
+		TxStatus:      tx.StatusPending,
+		NftIndex:      expectedTx.NftIndex,
+		PairIndex:     expectedTx.PairIndex,
+		AssetId:       expectedTx.AssetId,
+		TxAmount:      expectedTx.TxAmount,
+		NativeAddress: expectedTx.NativeAddress,
+		TxInfo:        expectedTx.TxInfo,
+		ExtraInfo:     expectedTx.ExtraInfo,
+		Memo:          expectedTx.Memo,
+		AccountIndex:  expectedTx.AccountIndex,
+		Nonce:         expectedTx.Nonce,
+		ExpiredAt:     expectedTx.ExpiredAt,
+	}
+	err := r.restoreTxInfo(replayedTx)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := executor.NewTxExecutor(r, replayedTx)
+	if err != nil {
+		return nil, err
+	}
+	err = e.Prepare()
+	if err != nil {
+		return nil, err
+	}
+	err = e.VerifyInputs()
+	if err != nil {
+		return nil, err
+	}
+	replayedTx.TxDetails, err = e.GenerateTxDetails()
+	if err != nil {
+		return nil, err
+	}
+	err = e.ApplyTransaction()
+	if err != nil {
+		return nil, err
+	}
+	err = e.GeneratePubData()
+	if err != nil {
+		return nil, err
+	}
+	err = e.UpdateTrees()
+	if err != nil {
+		return nil, err
+	}
+	replayedTx, err = e.GetExecutedTx()
+	if err != nil {
+		return nil, err
+	}
+
+	r.statedb.Txs = append(r.statedb.Txs, replayedTx)
+	r.statedb.StateRoot = replayedTx.StateRoot
+	return replayedTx, nil
+}
+
+// restoreTxInfo restores the tx info filled by the executor to the one it received. The index allocated to a
+// new nft by DepositNft makes it look like a deposit of an existing nft, which doesn't exist in the state yet.
+func (r *replayer) restoreTxInfo(t *tx.Tx) error {
+	if t.TxType != types.TxTypeDepositNft {
+		return nil
+	}
+	txInfo, err := types.ParseDepositNftTxInfo(t.TxInfo)
+	if err != nil {
+		return err
+	}
+	if r.statedb.NftMap[txInfo.NftIndex] != nil {
+		return nil
+	}
+	txInfo.NftIndex = 0
+	txInfoBytes, err := json.Marshal(txInfo)
+	if err != nil {
+		return err
+	}
+	t.TxInfo = string(txInfoBytes)
+	return nil
+}
+
+func (r *replayer) reportDivergence(expectedTx, replayedTx *tx.Tx) {
+	expectedTxDetails, err := r.chainDb.TxDetailModel.GetTxDetailsByTxId(int64(expectedTx.ID))
+	if err != nil && err != types.DbErrNotFound {
+		logx.Errorf("get tx details of tx %s failed: %v", expectedTx.TxHash, err)
+		return
+	}
+	for _, diff := range diffTxDetails(expectedTxDetails, replayedTx.TxDetails) {
+		logx.Errorf("tx %s: %s", expectedTx.TxHash, diff)
+	}
+}
+
+// diffTxDetails compares the tx details in order and describes the ones differing.
+func diffTxDetails(expected, replayed []*tx.TxDetail) []string {
+	describe := func(txDetail *tx.TxDetail) string {
+		if txDetail == nil {
+			return "none"
+		}
+		return fmt.Sprintf("asset type %d, account %d, asset %d, balance %s, delta %s, nonce %d, collection nonce %d",
+			txDetail.AssetType, txDetail.AccountIndex, txDetail.AssetId, txDetail.Balance, txDetail.BalanceDelta,
+			txDetail.Nonce, txDetail.CollectionNonce)
+	}
+
+	diffs := make([]string, 0)
+	for i := 0; i < len(expected) || i < len(replayed); i++ {
+		var expectedTxDetail, replayedTxDetail *tx.TxDetail
+		if i < len(expected) {
+			expectedTxDetail = expected[i]
+		}
+		if i < len(replayed) {
+			replayedTxDetail = replayed[i]
+		}
+		expectedDesc, replayedDesc := describe(expectedTxDetail), describe(replayedTxDetail)
+		if expectedDesc != replayedDesc {
+			diffs = append(diffs, fmt.Sprintf("leaf %d differs, expected [%s], replayed [%s]", i, expectedDesc, replayedDesc))
+		}
+	}
+	return diffs
+}
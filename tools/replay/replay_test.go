@@ -0,0 +1,39 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas/dao/tx"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+func TestDiffTxDetails(t *testing.T) {
+	newTxDetail := func(accountIndex int64, balance, delta string) *tx.TxDetail {
+		return &tx.TxDetail{
+			AssetType:    types.FungibleAssetType,
+			AccountIndex: accountIndex,
+			Balance:      balance,
+			BalanceDelta: delta,
+		}
+	}
+	expected := []*tx.TxDetail{
+		newTxDetail(1, "100", "-10"),
+		newTxDetail(2, "0", "10"),
+	}
+
+	assert.Empty(t, diffTxDetails(expected, []*tx.TxDetail{
+		newTxDetail(1, "100", "-10"),
+		newTxDetail(2, "0", "10"),
+	}))
+
+	diffs := diffTxDetails(expected, []*tx.TxDetail{
+		newTxDetail(1, "100", "-10"),
+		newTxDetail(2, "0", "9"),
+		newTxDetail(3, "0", "1"),
+	})
+	assert.Len(t, diffs, 2)
+	assert.Contains(t, diffs[0], "leaf 1 differs")
+	assert.Contains(t, diffs[1], "expected [none]")
+}
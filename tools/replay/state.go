@@ -0,0 +1,83 @@
+package replay
+
+import (
+	"github.com/bnb-chain/zkbas/common/chain"
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/account"
+	"github.com/bnb-chain/zkbas/dao/liquidity"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// accountModel answers the account queries of the executors from the replayed state instead of the latest
+// state in the database, so that the accounts registered after the replayed block are invisible.
+type accountModel struct {
+	account.AccountModel
+	statedb *sdb.StateDB
+}
+
+func (m *accountModel) GetAccountByIndex(accountIndex int64) (*account.Account, error) {
+	accountInfo, ok := m.statedb.AccountMap[accountIndex]
+	if !ok {
+		return nil, types.DbErrNotFound
+	}
+	return chain.FromFormatAccountInfo(accountInfo)
+}
+
+func (m *accountModel) GetAccountByName(name string) (*account.Account, error) {
+	for _, accountInfo := range m.statedb.AccountMap {
+		if accountInfo.AccountName == name {
+			return chain.FromFormatAccountInfo(accountInfo)
+		}
+	}
+	return nil, types.DbErrNotFound
+}
+
+func (m *accountModel) GetAccountByNameHash(nameHash string) (*account.Account, error) {
+	for _, accountInfo := range m.statedb.AccountMap {
+		if accountInfo.AccountNameHash == nameHash {
+			return chain.FromFormatAccountInfo(accountInfo)
+		}
+	}
+	return nil, types.DbErrNotFound
+}
+
+// liquidityModel answers the liquidity queries of the executors from the replayed state.
+type liquidityModel struct {
+	liquidity.LiquidityModel
+	statedb *sdb.StateDB
+}
+
+func (m *liquidityModel) GetLiquidityByPairIndex(pairIndex int64) (*liquidity.Liquidity, error) {
+	liquidityInfo, ok := m.statedb.LiquidityMap[pairIndex]
+	if !ok {
+		return nil, types.DbErrNotFound
+	}
+	liquidityCopy := *liquidityInfo
+	return &liquidityCopy, nil
+}
+
+// nftModel answers the nft queries of the executors from the replayed state.
+type nftModel struct {
+	nft.L2NftModel
+	statedb *sdb.StateDB
+}
+
+func (m *nftModel) GetNftAsset(nftIndex int64) (*nft.L2Nft, error) {
+	nftInfo, ok := m.statedb.NftMap[nftIndex]
+	if !ok {
+		return nil, types.DbErrNotFound
+	}
+	nftCopy := *nftInfo
+	return &nftCopy, nil
+}
+
+func (m *nftModel) GetLatestNftIndex() (int64, error) {
+	latestNftIndex := int64(-1)
+	for nftIndex := range m.statedb.NftMap {
+		if nftIndex > latestNftIndex {
+			latestNftIndex = nftIndex
+		}
+	}
+	return latestNftIndex, nil
+}
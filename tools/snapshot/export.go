@@ -0,0 +1,207 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/dao/liquidity"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/tools/snapshot/internal/config"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// Export writes the snapshot of the state at the block height to the output file, or to stdout if it is empty.
+// The states are streamed from the history tables, which are only appended by the committer, so the snapshot can be
+// exported from a replica of the database while the chain is running. The height 0 means the latest verified block.
+func Export(configFile string, height int64, batchSize int, output string) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+	if output == "" {
+		// the logs would be mixed with the snapshot.
+		logx.Disable()
+	}
+
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		return fmt.Errorf("gorm connect db failed: %v", err)
+	}
+	chainDb := sdb.NewChainDB(db)
+
+	if height == 0 {
+		height, err = chainDb.BlockModel.GetLatestVerifiedHeight()
+		if err != nil {
+			return fmt.Errorf("get latest verified height failed: %v", err)
+		}
+	}
+	b, err := chainDb.BlockModel.GetBlockByHeightWithoutTx(height)
+	if err != nil {
+		return fmt.Errorf("get block %d failed: %v", height, err)
+	}
+	// the pending blocks could still be rolled back.
+	if b.BlockStatus < block.StatusCommitted {
+		return fmt.Errorf("block %d is not committed", height)
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("create %s failed: %v", output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	sw, err := NewWriter(w, &Header{
+		BlockHeight: height,
+		StateRoot:   b.StateRoot,
+		CreatedAt:   time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+
+	logx.Infof("export state at block %d", height)
+	err = export(chainDb, height, batchSize, sw)
+	if err != nil {
+		return fmt.Errorf("export state at block %d failed: %v", height, err)
+	}
+	err = sw.Close()
+	if err != nil {
+		return err
+	}
+	logx.Infof("snapshot of block %d is written to %s, %d accounts, %d liquidity and %d nfts", height, output,
+		sw.footer.Accounts, sw.footer.Liquidity, sw.footer.Nfts)
+	return nil
+}
+
+func export(chainDb *sdb.ChainDB, height int64, batchSize int, w *Writer) error {
+	err := sdb.ForEachHistoryAccount(chainDb, height, batchSize, func(accountInfo *types.AccountInfo) error {
+		record, err := newAccountRecord(accountInfo)
+		if err != nil {
+			return fmt.Errorf("account %d: %v", accountInfo.AccountIndex, err)
+		}
+		return w.WriteAccount(record)
+	})
+	if err != nil {
+		return err
+	}
+	err = sdb.ForEachHistoryLiquidity(chainDb, height, batchSize, func(liquidityInfo *liquidity.Liquidity) error {
+		record, err := newLiquidityRecord(liquidityInfo)
+		if err != nil {
+			return fmt.Errorf("pair %d: %v", liquidityInfo.PairIndex, err)
+		}
+		return w.WriteLiquidity(record)
+	})
+	if err != nil {
+		return err
+	}
+	return sdb.ForEachHistoryNft(chainDb, height, batchSize, func(nftInfo *nft.L2Nft) error {
+		record, err := newNftRecord(nftInfo)
+		if err != nil {
+			return fmt.Errorf("nft %d: %v", nftInfo.NftIndex, err)
+		}
+		return w.WriteNft(record)
+	})
+}
+
+// newAccountRecord hashes the leaves of the account, the asset tree of the account is built in memory to get the
+// asset root and dropped afterwards.
+func newAccountRecord(accountInfo *types.AccountInfo) (*AccountRecord, error) {
+	assetTree, err := tree.NewMemAccountAssetTree()
+	if err != nil {
+		return nil, err
+	}
+	record := &AccountRecord{
+		Index:           accountInfo.AccountIndex,
+		Name:            accountInfo.AccountName,
+		NameHash:        accountInfo.AccountNameHash,
+		PublicKey:       accountInfo.PublicKey,
+		L1Address:       accountInfo.L1Address,
+		Nonce:           accountInfo.Nonce,
+		CollectionNonce: accountInfo.CollectionNonce,
+		Assets:          make([]*AssetRecord, 0, len(accountInfo.AssetInfo)),
+	}
+	for assetId, asset := range accountInfo.AssetInfo {
+		assetRecord := &AssetRecord{
+			Id:                       assetId,
+			Balance:                  asset.Balance.String(),
+			LpAmount:                 asset.LpAmount.String(),
+			OfferCanceledOrFinalized: asset.OfferCanceledOrFinalized.String(),
+		}
+		leaf, err := assetLeaf(assetRecord)
+		if err != nil {
+			return nil, err
+		}
+		err = assetTree.Set(uint64(assetId), leaf)
+		if err != nil {
+			return nil, err
+		}
+		assetRecord.Leaf = common.Bytes2Hex(leaf)
+		record.Assets = append(record.Assets, assetRecord)
+	}
+	sort.Slice(record.Assets, func(i, j int) bool {
+		return record.Assets[i].Id < record.Assets[j].Id
+	})
+
+	leaf, err := accountLeaf(record, assetTree.Root())
+	if err != nil {
+		return nil, err
+	}
+	record.AssetRoot = common.Bytes2Hex(assetTree.Root())
+	record.Leaf = common.Bytes2Hex(leaf)
+	return record, nil
+}
+
+func newLiquidityRecord(liquidityInfo *liquidity.Liquidity) (*LiquidityRecord, error) {
+	record := &LiquidityRecord{
+		PairIndex:            liquidityInfo.PairIndex,
+		AssetAId:             liquidityInfo.AssetAId,
+		AssetA:               liquidityInfo.AssetA,
+		AssetBId:             liquidityInfo.AssetBId,
+		AssetB:               liquidityInfo.AssetB,
+		LpAmount:             liquidityInfo.LpAmount,
+		KLast:                liquidityInfo.KLast,
+		FeeRate:              liquidityInfo.FeeRate,
+		TreasuryAccountIndex: liquidityInfo.TreasuryAccountIndex,
+		TreasuryRate:         liquidityInfo.TreasuryRate,
+	}
+	leaf, err := liquidityLeaf(record)
+	if err != nil {
+		return nil, err
+	}
+	record.Leaf = common.Bytes2Hex(leaf)
+	return record, nil
+}
+
+func newNftRecord(nftInfo *nft.L2Nft) (*NftRecord, error) {
+	record := &NftRecord{
+		NftIndex:            nftInfo.NftIndex,
+		CreatorAccountIndex: nftInfo.CreatorAccountIndex,
+		OwnerAccountIndex:   nftInfo.OwnerAccountIndex,
+		NftContentHash:      nftInfo.NftContentHash,
+		NftL1Address:        nftInfo.NftL1Address,
+		NftL1TokenId:        nftInfo.NftL1TokenId,
+		CreatorTreasuryRate: nftInfo.CreatorTreasuryRate,
+		CollectionId:        nftInfo.CollectionId,
+	}
+	leaf, err := nftLeaf(record)
+	if err != nil {
+		return nil, err
+	}
+	record.Leaf = common.Bytes2Hex(leaf)
+	return record, nil
+}
@@ -0,0 +1,196 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	bsmt "github.com/bnb-chain/zkbas-smt"
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/tools/snapshot/internal/config"
+	"github.com/bnb-chain/zkbas/tree"
+)
+
+// Import restores the trees of the service from the snapshot in the input file, or from stdin if it is empty.
+// The trees are rebuilt from the leaves in the snapshot at the version of the block height, and the state root is
+// checked against the block in the database. The tree database must be empty.
+func Import(configFile, serviceName, input string) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+	if c.TreeDB.Driver == "" {
+		return errors.New("tree db is not configured")
+	}
+
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		return fmt.Errorf("gorm connect db failed: %v", err)
+	}
+	chainDb := sdb.NewChainDB(db)
+
+	var r io.Reader = os.Stdin
+	if input != "" {
+		f, err := os.Open(input)
+		if err != nil {
+			return fmt.Errorf("open %s failed: %v", input, err)
+		}
+		defer f.Close()
+		r = f
+	}
+	sr, err := NewReader(r)
+	if err != nil {
+		return err
+	}
+	height := sr.Header().BlockHeight
+	b, err := chainDb.BlockModel.GetBlockByHeightWithoutTx(height)
+	if err != nil {
+		return fmt.Errorf("get block %d failed: %v", height, err)
+	}
+	if b.StateRoot != sr.Header().StateRoot {
+		return fmt.Errorf("state root of block %d is %s, but %s in the snapshot", height, b.StateRoot,
+			sr.Header().StateRoot)
+	}
+
+	treeCtx := &tree.Context{
+		Name:           serviceName,
+		Driver:         c.TreeDB.Driver,
+		LevelDBOption:  &c.TreeDB.LevelDBOption,
+		PebbleDBOption: &c.TreeDB.PebbleDBOption,
+		RedisDBOption:  &c.TreeDB.RedisDBOption,
+	}
+	treeCtx.SetOptions(bsmt.InitializeVersion(bsmt.Version(height) - 1))
+	err = tree.SetupTreeDB(treeCtx)
+	if err != nil {
+		return fmt.Errorf("init tree database failed: %v", err)
+	}
+
+	logx.Infof("import state at block %d", height)
+	stateRoot, err := restore(treeCtx, sr)
+	if err != nil {
+		return fmt.Errorf("import state at block %d failed, the tree db of %s must be cleared before retrying: %v",
+			height, serviceName, err)
+	}
+	if stateRoot != b.StateRoot {
+		return fmt.Errorf("state root of block %d is %s, but %s is imported, the tree db of %s must be cleared",
+			height, b.StateRoot, stateRoot, serviceName)
+	}
+	logx.Infof("state at block %d is imported into the tree db of %s", height, serviceName)
+	return nil
+}
+
+// restore rebuilds the trees from the snapshot and returns the state root. Each leaf is hashed again from the states
+// in the record and must be the leaf recorded, every tree is committed once so that it ends at the version of the
+// block height. The asset trees are committed as soon as their account is read, so the snapshot is restored without
+// holding all the states in memory.
+func restore(treeCtx *tree.Context, r *Reader) (string, error) {
+	if r.Header().BlockHeight <= 0 {
+		return "", fmt.Errorf("invalid block height %d", r.Header().BlockHeight)
+	}
+	height := uint64(r.Header().BlockHeight)
+	accountTree, err := tree.NewEmptyAccountTree(treeCtx, height-1)
+	if err != nil {
+		return "", err
+	}
+	liquidityTree, err := tree.NewEmptyLiquidityTree(treeCtx, height-1)
+	if err != nil {
+		return "", err
+	}
+	nftTree, err := tree.NewEmptyNftTree(treeCtx, height-1)
+	if err != nil {
+		return "", err
+	}
+	if !accountTree.IsEmpty() || !liquidityTree.IsEmpty() || !nftTree.IsEmpty() {
+		return "", errors.New("tree db is not empty")
+	}
+
+	var nextAccountIndex int64
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case record.Account != nil:
+			// the account indexes are dense, as the asset trees are indexed by them.
+			if record.Account.Index != nextAccountIndex {
+				return "", fmt.Errorf("account %d is expected, but got %d", nextAccountIndex, record.Account.Index)
+			}
+			nextAccountIndex++
+			err = restoreAccount(treeCtx, height, accountTree, record.Account)
+		case record.Liquidity != nil:
+			err = restoreLeaf(liquidityTree, record.Liquidity.PairIndex, record.Liquidity.Leaf,
+				func() ([]byte, error) { return liquidityLeaf(record.Liquidity) })
+			if err != nil {
+				err = fmt.Errorf("pair %d: %v", record.Liquidity.PairIndex, err)
+			}
+		case record.Nft != nil:
+			err = restoreLeaf(nftTree, record.Nft.NftIndex, record.Nft.Leaf,
+				func() ([]byte, error) { return nftLeaf(record.Nft) })
+			if err != nil {
+				err = fmt.Errorf("nft %d: %v", record.Nft.NftIndex, err)
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	for _, smt := range []bsmt.SparseMerkleTree{accountTree, liquidityTree, nftTree} {
+		_, err = smt.Commit(nil)
+		if err != nil {
+			return "", err
+		}
+	}
+	return common.Bytes2Hex(tree.ComputeStateRootHash(accountTree.Root(), liquidityTree.Root(), nftTree.Root())), nil
+}
+
+func restoreAccount(treeCtx *tree.Context, height uint64, accountTree bsmt.SparseMerkleTree, account *AccountRecord) error {
+	assetTree, err := tree.NewEmptyAccountAssetTree(treeCtx, account.Index, height-1)
+	if err != nil {
+		return err
+	}
+	for _, asset := range account.Assets {
+		err = restoreLeaf(assetTree, asset.Id, asset.Leaf, func() ([]byte, error) { return assetLeaf(asset) })
+		if err != nil {
+			return fmt.Errorf("asset %d of account %d: %v", asset.Id, account.Index, err)
+		}
+	}
+	_, err = assetTree.Commit(nil)
+	if err != nil {
+		return err
+	}
+	if assetRoot := common.Bytes2Hex(assetTree.Root()); assetRoot != account.AssetRoot {
+		return fmt.Errorf("asset root of account %d is %s, but %s is restored", account.Index, account.AssetRoot,
+			assetRoot)
+	}
+
+	err = restoreLeaf(accountTree, account.Index, account.Leaf, func() ([]byte, error) {
+		return accountLeaf(account, assetTree.Root())
+	})
+	if err != nil {
+		return fmt.Errorf("account %d: %v", account.Index, err)
+	}
+	return nil
+}
+
+func restoreLeaf(smt bsmt.SparseMerkleTree, key int64, recordedLeaf string, computeLeaf func() ([]byte, error)) error {
+	leaf, err := computeLeaf()
+	if err != nil {
+		return err
+	}
+	if common.Bytes2Hex(leaf) != recordedLeaf {
+		return errors.New("leaf mismatches the states")
+	}
+	return smt.Set(uint64(key), leaf)
+}
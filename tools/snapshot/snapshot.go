@@ -0,0 +1,281 @@
+package snapshot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/bnb-chain/zkbas/tree"
+)
+
+// A snapshot is a gzip stream of json lines: the header, the records of the accounts, the liquidity and the nfts,
+// and the footer. The footer counts the records and carries the sha256 checksum of all the uncompressed lines
+// before it, so a snapshot is written and read in a single pass.
+const (
+	Magic   = "zkbas-state-snapshot"
+	Version = 1
+)
+
+const (
+	recordHeader    = "header"
+	recordAccount   = "account"
+	recordLiquidity = "liquidity"
+	recordNft       = "nft"
+	recordFooter    = "footer"
+)
+
+var ErrTruncated = errors.New("snapshot is truncated")
+
+type Header struct {
+	Magic       string `json:"magic"`
+	Version     int    `json:"version"`
+	BlockHeight int64  `json:"block_height"`
+	StateRoot   string `json:"state_root"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+type Footer struct {
+	Accounts  int64  `json:"accounts"`
+	Liquidity int64  `json:"liquidity"`
+	Nfts      int64  `json:"nfts"`
+	Checksum  string `json:"checksum"`
+}
+
+// AccountRecord is an account with its assets. The leaves are the nodes of the account tree and the asset tree of
+// the account, the inner nodes are rebuilt from them on import.
+type AccountRecord struct {
+	Index           int64          `json:"index"`
+	Name            string         `json:"name"`
+	NameHash        string         `json:"name_hash"`
+	PublicKey       string         `json:"pk"`
+	L1Address       string         `json:"l1_address"`
+	Nonce           int64          `json:"nonce"`
+	CollectionNonce int64          `json:"collection_nonce"`
+	Assets          []*AssetRecord `json:"assets"`
+	AssetRoot       string         `json:"asset_root"`
+	Leaf            string         `json:"leaf"`
+}
+
+type AssetRecord struct {
+	Id                       int64  `json:"id"`
+	Balance                  string `json:"balance"`
+	LpAmount                 string `json:"lp_amount"`
+	OfferCanceledOrFinalized string `json:"offer_canceled_or_finalized"`
+	Leaf                     string `json:"leaf"`
+}
+
+type LiquidityRecord struct {
+	PairIndex            int64  `json:"pair_index"`
+	AssetAId             int64  `json:"asset_a_id"`
+	AssetA               string `json:"asset_a"`
+	AssetBId             int64  `json:"asset_b_id"`
+	AssetB               string `json:"asset_b"`
+	LpAmount             string `json:"lp_amount"`
+	KLast                string `json:"k_last"`
+	FeeRate              int64  `json:"fee_rate"`
+	TreasuryAccountIndex int64  `json:"treasury_account_index"`
+	TreasuryRate         int64  `json:"treasury_rate"`
+	Leaf                 string `json:"leaf"`
+}
+
+type NftRecord struct {
+	NftIndex            int64  `json:"nft_index"`
+	CreatorAccountIndex int64  `json:"creator_account_index"`
+	OwnerAccountIndex   int64  `json:"owner_account_index"`
+	NftContentHash      string `json:"nft_content_hash"`
+	NftL1Address        string `json:"nft_l1_address"`
+	NftL1TokenId        string `json:"nft_l1_token_id"`
+	CreatorTreasuryRate int64  `json:"creator_treasury_rate"`
+	CollectionId        int64  `json:"collection_id"`
+	Leaf                string `json:"leaf"`
+}
+
+// Record is a line of the snapshot, only the field of its type is set.
+type Record struct {
+	Type      string           `json:"type"`
+	Header    *Header          `json:"header,omitempty"`
+	Account   *AccountRecord   `json:"account,omitempty"`
+	Liquidity *LiquidityRecord `json:"liquidity,omitempty"`
+	Nft       *NftRecord       `json:"nft,omitempty"`
+	Footer    *Footer          `json:"footer,omitempty"`
+}
+
+type Writer struct {
+	zw     *gzip.Writer
+	hasher hash.Hash
+	footer Footer
+}
+
+// NewWriter writes the header of the snapshot to w, the records are written after it and Close writes the footer.
+func NewWriter(w io.Writer, header *Header) (*Writer, error) {
+	header.Magic, header.Version = Magic, Version
+	sw := &Writer{
+		zw:     gzip.NewWriter(w),
+		hasher: sha256.New(),
+	}
+	err := sw.write(&Record{Type: recordHeader, Header: header})
+	if err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+func (w *Writer) WriteAccount(account *AccountRecord) error {
+	w.footer.Accounts++
+	return w.write(&Record{Type: recordAccount, Account: account})
+}
+
+func (w *Writer) WriteLiquidity(liquidity *LiquidityRecord) error {
+	w.footer.Liquidity++
+	return w.write(&Record{Type: recordLiquidity, Liquidity: liquidity})
+}
+
+func (w *Writer) WriteNft(nft *NftRecord) error {
+	w.footer.Nfts++
+	return w.write(&Record{Type: recordNft, Nft: nft})
+}
+
+// Close writes the footer and flushes the snapshot, it does not close the underlying writer.
+func (w *Writer) Close() error {
+	w.footer.Checksum = hex.EncodeToString(w.hasher.Sum(nil))
+	line, err := json.Marshal(&Record{Type: recordFooter, Footer: &w.footer})
+	if err != nil {
+		return err
+	}
+	_, err = w.zw.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+func (w *Writer) write(record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	w.hasher.Write(line)
+	_, err = w.zw.Write(line)
+	return err
+}
+
+type Reader struct {
+	header *Header
+	br     *bufio.Reader
+	hasher hash.Hash
+	counts Footer
+}
+
+// NewReader reads the header of the snapshot from r and checks its version.
+func NewReader(r io.Reader) (*Reader, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %v", err)
+	}
+	sr := &Reader{
+		br:     bufio.NewReader(zr),
+		hasher: sha256.New(),
+	}
+	record, err := sr.read()
+	if err == io.EOF {
+		return nil, ErrTruncated
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.Type != recordHeader || record.Header == nil || record.Header.Magic != Magic {
+		return nil, errors.New("invalid snapshot: header is missing")
+	}
+	if record.Header.Version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version %d", record.Header.Version)
+	}
+	sr.header = record.Header
+	return sr, nil
+}
+
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Next returns the next record of the accounts, the liquidity or the nfts. It returns io.EOF once the footer is
+// read and matches the records read, or an error if the snapshot is truncated or corrupted.
+func (r *Reader) Next() (*Record, error) {
+	record, err := r.read()
+	if err == io.EOF {
+		return nil, ErrTruncated
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case record.Type == recordAccount && record.Account != nil:
+		r.counts.Accounts++
+	case record.Type == recordLiquidity && record.Liquidity != nil:
+		r.counts.Liquidity++
+	case record.Type == recordNft && record.Nft != nil:
+		r.counts.Nfts++
+	case record.Type == recordFooter && record.Footer != nil:
+		return nil, r.verify(record.Footer)
+	default:
+		return nil, fmt.Errorf("invalid snapshot: unexpected record %s", record.Type)
+	}
+	return record, nil
+}
+
+func (r *Reader) verify(footer *Footer) error {
+	checksum := hex.EncodeToString(r.hasher.Sum(nil))
+	if footer.Checksum != checksum {
+		return fmt.Errorf("snapshot checksum mismatch, expected %s, got %s", footer.Checksum, checksum)
+	}
+	if footer.Accounts != r.counts.Accounts || footer.Liquidity != r.counts.Liquidity || footer.Nfts != r.counts.Nfts {
+		return fmt.Errorf("snapshot records mismatch, expected %d accounts, %d liquidity and %d nfts, got %d, %d and %d",
+			footer.Accounts, footer.Liquidity, footer.Nfts, r.counts.Accounts, r.counts.Liquidity, r.counts.Nfts)
+	}
+	return io.EOF
+}
+
+func (r *Reader) read() (*Record, error) {
+	line, err := r.br.ReadBytes('\n')
+	if err == io.EOF && len(line) > 0 {
+		return nil, ErrTruncated
+	}
+	if err != nil {
+		return nil, err
+	}
+	record := &Record{}
+	err = json.Unmarshal(line, record)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %v", err)
+	}
+	if record.Type != recordFooter {
+		r.hasher.Write(line)
+	}
+	return record, nil
+}
+
+func assetLeaf(asset *AssetRecord) ([]byte, error) {
+	return tree.ComputeAccountAssetLeafHash(asset.Balance, asset.LpAmount, asset.OfferCanceledOrFinalized)
+}
+
+func accountLeaf(account *AccountRecord, assetRoot []byte) ([]byte, error) {
+	return tree.ComputeAccountLeafHash(account.NameHash, account.PublicKey, account.Nonce, account.CollectionNonce,
+		assetRoot)
+}
+
+func liquidityLeaf(liquidity *LiquidityRecord) ([]byte, error) {
+	return tree.ComputeLiquidityAssetLeafHash(liquidity.AssetAId, liquidity.AssetA, liquidity.AssetBId,
+		liquidity.AssetB, liquidity.LpAmount, liquidity.KLast, liquidity.FeeRate, liquidity.TreasuryAccountIndex,
+		liquidity.TreasuryRate)
+}
+
+func nftLeaf(nft *NftRecord) ([]byte, error) {
+	return tree.ComputeNftAssetLeafHash(nft.CreatorAccountIndex, nft.OwnerAccountIndex, nft.NftContentHash,
+		nft.NftL1Address, nft.NftL1TokenId, nft.CreatorTreasuryRate, nft.CollectionId)
+}
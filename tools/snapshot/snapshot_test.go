@@ -0,0 +1,145 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas-crypto/hash/bn254/zmimc"
+	bsmt "github.com/bnb-chain/zkbas-smt"
+	"github.com/bnb-chain/zkbas-smt/database/memory"
+	"github.com/bnb-chain/zkbas/dao/liquidity"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+const testPk = "58130e24cd20d9de8a110a20751f0a9b36089400ac0f20ca1993c28ee663318a"
+
+func newMemTree(t *testing.T, depth uint8, nilHash []byte) bsmt.SparseMerkleTree {
+	smt, err := bsmt.NewBASSparseMerkleTree(bsmt.NewHasher(zmimc.Hmimc), memory.NewMemoryDB(), depth, nilHash)
+	assert.NoError(t, err)
+	return smt
+}
+
+func newTestAccount(index int64, balance int64) *types.AccountInfo {
+	return &types.AccountInfo{
+		AccountIndex:    index,
+		AccountName:     "test.legend",
+		AccountNameHash: "0x04b2ea4f6f9a6b6a8b6c7e2e2e24bbf0c7f2e1a1d2c3b4a5968778695a4b3c2d",
+		PublicKey:       testPk,
+		Nonce:           index + 1,
+		AssetInfo: map[int64]*types.AccountAsset{
+			2: {AssetId: 2, Balance: big.NewInt(balance), LpAmount: big.NewInt(0), OfferCanceledOrFinalized: big.NewInt(0)},
+			0: {AssetId: 0, Balance: big.NewInt(1), LpAmount: big.NewInt(0), OfferCanceledOrFinalized: big.NewInt(0)},
+		},
+	}
+}
+
+// writeTestSnapshot writes the snapshot of two accounts, a pair and a nft, and returns the state root computed
+// from the trees built directly.
+func writeTestSnapshot(t *testing.T, buf *bytes.Buffer, tamper func(w *Writer)) string {
+	accountTree := newMemTree(t, tree.AccountTreeHeight, tree.NilAccountNodeHash)
+	liquidityTree := newMemTree(t, tree.LiquidityTreeHeight, tree.NilLiquidityNodeHash)
+	nftTree := newMemTree(t, tree.NftTreeHeight, tree.NilNftNodeHash)
+
+	w, err := NewWriter(buf, &Header{BlockHeight: 10})
+	assert.NoError(t, err)
+	for i := int64(0); i < 2; i++ {
+		record, err := newAccountRecord(newTestAccount(i, 100*i))
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{0, 2}, []int64{record.Assets[0].Id, record.Assets[1].Id})
+		assert.NoError(t, accountTree.Set(uint64(i), common.FromHex(record.Leaf)))
+		assert.NoError(t, w.WriteAccount(record))
+	}
+	liquidityRecord, err := newLiquidityRecord(&liquidity.Liquidity{
+		PairIndex: 0, AssetAId: 0, AssetA: "1000", AssetBId: 2, AssetB: "2000", LpAmount: "1414", KLast: "2000000",
+		FeeRate: 30, TreasuryAccountIndex: 0, TreasuryRate: 5,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, liquidityTree.Set(0, common.FromHex(liquidityRecord.Leaf)))
+	assert.NoError(t, w.WriteLiquidity(liquidityRecord))
+	nftRecord, err := newNftRecord(&nft.L2Nft{
+		NftIndex: 3, CreatorAccountIndex: 1, OwnerAccountIndex: 1, NftContentHash: "0x01",
+		NftL1Address: "0x0000000000000000000000000000000000000000", NftL1TokenId: "0", CreatorTreasuryRate: 10,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, nftTree.Set(3, common.FromHex(nftRecord.Leaf)))
+	assert.NoError(t, w.WriteNft(nftRecord))
+	if tamper != nil {
+		tamper(w)
+	}
+	assert.NoError(t, w.Close())
+
+	return common.Bytes2Hex(tree.ComputeStateRootHash(accountTree.Root(), liquidityTree.Root(), nftTree.Root()))
+}
+
+func restoreTestSnapshot(t *testing.T, buf *bytes.Buffer) (string, error) {
+	r, err := NewReader(buf)
+	if err != nil {
+		return "", err
+	}
+	assert.Equal(t, int64(10), r.Header().BlockHeight)
+	treeCtx := &tree.Context{
+		Name:   "test",
+		Driver: tree.MemoryDB,
+	}
+	assert.NoError(t, tree.SetupTreeDB(treeCtx))
+	return restore(treeCtx, r)
+}
+
+func TestRestore(t *testing.T) {
+	buf := &bytes.Buffer{}
+	stateRoot := writeTestSnapshot(t, buf, nil)
+	restoredRoot, err := restoreTestSnapshot(t, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, stateRoot, restoredRoot)
+}
+
+func TestRestoreCorrupted(t *testing.T) {
+	// the leaf must match the states recorded.
+	buf := &bytes.Buffer{}
+	writeTestSnapshot(t, buf, func(w *Writer) {
+		record, err := newAccountRecord(newTestAccount(2, 0))
+		assert.NoError(t, err)
+		record.Nonce++
+		assert.NoError(t, w.WriteAccount(record))
+	})
+	_, err := restoreTestSnapshot(t, buf)
+	assert.EqualError(t, err, "account 2: leaf mismatches the states")
+
+	// the account indexes must be dense.
+	buf.Reset()
+	writeTestSnapshot(t, buf, func(w *Writer) {
+		record, err := newAccountRecord(newTestAccount(3, 0))
+		assert.NoError(t, err)
+		assert.NoError(t, w.WriteAccount(record))
+	})
+	_, err = restoreTestSnapshot(t, buf)
+	assert.EqualError(t, err, "account 2 is expected, but got 3")
+
+	// the lines written around the writer are caught by the checksum.
+	buf.Reset()
+	writeTestSnapshot(t, buf, func(w *Writer) {
+		record, err := newNftRecord(&nft.L2Nft{NftIndex: 4, NftL1Address: "0x0000000000000000000000000000000000000000",
+			NftL1TokenId: "0"})
+		assert.NoError(t, err)
+		line, err := json.Marshal(&Record{Type: recordNft, Nft: record})
+		assert.NoError(t, err)
+		_, err = w.zw.Write(append(line, '\n'))
+		assert.NoError(t, err)
+	})
+	_, err = restoreTestSnapshot(t, buf)
+	assert.Contains(t, err.Error(), "snapshot checksum mismatch")
+
+	// the snapshot must end with the footer.
+	buf.Reset()
+	writeTestSnapshot(t, buf, nil)
+	buf.Truncate(buf.Len() / 2)
+	_, err = restoreTestSnapshot(t, buf)
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package treeverify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/bnb-chain/zkbas/common/chain"
+	sdb "github.com/bnb-chain/zkbas/core/statedb"
+	"github.com/bnb-chain/zkbas/dao/block"
+	"github.com/bnb-chain/zkbas/tools/treeverify/internal/config"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// VerifyTreeDB compares the trees of the service in the tree db with the accounts, liquidity and nfts in the
+// database, which are the states of the current block. The mismatches are written to the output file, or to stdout
+// if it is empty, as json lines. The trees must be at the current block, i.e. the trees of the committer, and the
+// committer should be stopped so that the states do not move during the verification.
+func VerifyTreeDB(configFile string, serviceName string, batchSize int, output string) error {
+	var c config.Config
+	conf.MustLoad(configFile, &c)
+	logx.MustSetup(c.LogConf)
+	logx.DisableStat()
+	if output == "" {
+		// the logs would be mixed with the mismatches.
+		logx.Disable()
+	}
+	if c.TreeDB.Driver == "" || c.TreeDB.Driver == tree.MemoryDB {
+		return errors.New("tree db is not configured")
+	}
+
+	db, err := gorm.Open(postgres.Open(c.Postgres.DataSource))
+	if err != nil {
+		return fmt.Errorf("gorm connect db failed: %v", err)
+	}
+	chainDb := sdb.NewChainDB(db)
+
+	height, err := chainDb.BlockModel.GetCurrentHeight()
+	if err != nil {
+		return fmt.Errorf("get current height failed: %v", err)
+	}
+	b, err := chainDb.BlockModel.GetBlockByHeightWithoutTx(height)
+	if err != nil {
+		return fmt.Errorf("get block %d failed: %v", height, err)
+	}
+	// the states of the proposing block are not committed yet, as in the committer.
+	if b.BlockStatus == block.StatusProposing {
+		height--
+		b, err = chainDb.BlockModel.GetBlockByHeightWithoutTx(height)
+		if err != nil {
+			return fmt.Errorf("get block %d failed: %v", height, err)
+		}
+	}
+
+	treeCtx := &tree.Context{
+		Name:           serviceName,
+		Driver:         c.TreeDB.Driver,
+		LevelDBOption:  &c.TreeDB.LevelDBOption,
+		PebbleDBOption: &c.TreeDB.PebbleDBOption,
+		RedisDBOption:  &c.TreeDB.RedisDBOption,
+	}
+	err = tree.SetupTreeDB(treeCtx)
+	if err != nil {
+		return fmt.Errorf("init tree database failed: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("create %s failed: %v", output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	encoder := json.NewEncoder(w)
+	v, err := NewVerifier(treeCtx, height, func(mismatch *Mismatch) error {
+		return encoder.Encode(mismatch)
+	})
+	if err != nil {
+		return fmt.Errorf("verify trees of %s at block %d failed: %v", serviceName, height, err)
+	}
+
+	logx.Infof("verify trees of %s at block %d", serviceName, height)
+	err = verify(chainDb, batchSize, v)
+	if err != nil {
+		return fmt.Errorf("verify trees of %s at block %d failed: %v", serviceName, height, err)
+	}
+	err = v.VerifyRoots(b.StateRoot)
+	if err != nil {
+		return err
+	}
+	if v.Mismatches() > 0 {
+		return fmt.Errorf("%d mismatches found between the trees of %s and the states at block %d",
+			v.Mismatches(), serviceName, height)
+	}
+	logx.Infof("trees of %s match the states at block %d", serviceName, height)
+	return nil
+}
+
+func verify(chainDb *sdb.ChainDB, batchSize int, v *Verifier) error {
+	for offset := int64(0); ; offset += int64(batchSize) {
+		accounts, err := chainDb.AccountModel.GetAccountsList(batchSize, offset)
+		if err == types.DbErrNotFound {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for _, accountInfo := range accounts {
+			formatAccountInfo, err := chain.ToFormatAccountInfo(accountInfo)
+			if err != nil {
+				return fmt.Errorf("account %d: %v", accountInfo.AccountIndex, err)
+			}
+			err = v.VerifyAccount(formatAccountInfo)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	liquidityList, err := chainDb.LiquidityModel.GetAllLiquidityAssets()
+	if err != nil && err != types.DbErrNotFound {
+		return err
+	}
+	for _, liquidityInfo := range liquidityList {
+		err = v.VerifyLiquidity(liquidityInfo)
+		if err != nil {
+			return err
+		}
+	}
+
+	for offset := int64(0); ; offset += int64(batchSize) {
+		nfts, err := chainDb.L2NftModel.GetNftsList(batchSize, offset)
+		if err == types.DbErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, nftInfo := range nfts {
+			err = v.VerifyNft(nftInfo)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package treeverify
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/bnb-chain/zkbas-crypto/hash/bn254/zmimc"
+	bsmt "github.com/bnb-chain/zkbas-smt"
+	"github.com/bnb-chain/zkbas-smt/database/memory"
+	"github.com/bnb-chain/zkbas/dao/liquidity"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+// The types of the mismatches, a leaf mismatches if the leaf in the tree is not the one hashed from the state, a root
+// mismatches if the tree has leaves which are not in the state.
+const (
+	MismatchAsset         = "asset"
+	MismatchAssetTree     = "asset_tree"
+	MismatchAccount       = "account"
+	MismatchAccountTree   = "account_tree"
+	MismatchLiquidity     = "liquidity"
+	MismatchLiquidityTree = "liquidity_tree"
+	MismatchNft           = "nft"
+	MismatchNftTree       = "nft_tree"
+	MismatchStateRoot     = "state_root"
+)
+
+// Mismatch is a difference between the trees and the state. The index is the account index for the accounts, the
+// assets and the asset trees, the pair index for the liquidity, the nft index for the nfts and the block height for
+// the state root. The tree is empty if the leaf is missing in the tree.
+type Mismatch struct {
+	Type    string `json:"type"`
+	Index   int64  `json:"index"`
+	AssetId *int64 `json:"asset_id,omitempty"`
+	Tree    string `json:"tree"`
+	State   string `json:"state"`
+}
+
+// Verifier compares the trees of a service with the states at the same block height. The leaves are hashed from the
+// states and compared with the leaves in the trees, and the trees are rebuilt in memory from the leaves hashed to
+// find the leaves in the trees which are not in the states.
+type Verifier struct {
+	height int64
+	report func(*Mismatch) error
+
+	treeCtx       *tree.Context
+	accountTree   bsmt.SparseMerkleTree
+	liquidityTree bsmt.SparseMerkleTree
+	nftTree       bsmt.SparseMerkleTree
+
+	stateAccountTree   bsmt.SparseMerkleTree
+	stateLiquidityTree bsmt.SparseMerkleTree
+	stateNftTree       bsmt.SparseMerkleTree
+
+	mismatches int
+}
+
+// NewVerifier returns the verifier of the trees in the tree context at the block height, every mismatch found is
+// passed to the report. The trees must be at the version of the block height.
+func NewVerifier(treeCtx *tree.Context, height int64, report func(*Mismatch) error) (*Verifier, error) {
+	v := &Verifier{
+		height:  height,
+		report:  report,
+		treeCtx: treeCtx,
+	}
+	var err error
+	v.accountTree, err = tree.NewEmptyAccountTree(treeCtx, uint64(height))
+	if err != nil {
+		return nil, err
+	}
+	v.liquidityTree, err = tree.NewEmptyLiquidityTree(treeCtx, uint64(height))
+	if err != nil {
+		return nil, err
+	}
+	v.nftTree, err = tree.NewEmptyNftTree(treeCtx, uint64(height))
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range []struct {
+		name string
+		smt  bsmt.SparseMerkleTree
+	}{
+		{"account", v.accountTree},
+		{"liquidity", v.liquidityTree},
+		{"nft", v.nftTree},
+	} {
+		if !t.smt.IsEmpty() && t.smt.LatestVersion() != bsmt.Version(height) {
+			return nil, fmt.Errorf("%s tree is at version %d, but the block height is %d", t.name,
+				t.smt.LatestVersion(), height)
+		}
+	}
+
+	v.stateAccountTree, err = newMemTree(tree.AccountTreeHeight, tree.NilAccountNodeHash)
+	if err != nil {
+		return nil, err
+	}
+	v.stateLiquidityTree, err = newMemTree(tree.LiquidityTreeHeight, tree.NilLiquidityNodeHash)
+	if err != nil {
+		return nil, err
+	}
+	v.stateNftTree, err = newMemTree(tree.NftTreeHeight, tree.NilNftNodeHash)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Mismatches returns the number of the mismatches found.
+func (v *Verifier) Mismatches() int {
+	return v.mismatches
+}
+
+// VerifyAccount compares the assets and the account with the leaves in the asset tree of the account and in the
+// account tree.
+func (v *Verifier) VerifyAccount(accountInfo *types.AccountInfo) error {
+	assetTree, err := tree.NewEmptyAccountAssetTree(v.treeCtx, accountInfo.AccountIndex, uint64(v.height))
+	if err != nil {
+		return err
+	}
+	stateAssetTree, err := tree.NewMemAccountAssetTree()
+	if err != nil {
+		return err
+	}
+	// the assets are verified in order, so the mismatches are reported in the same order every time.
+	assetIds := make([]int64, 0, len(accountInfo.AssetInfo))
+	for assetId := range accountInfo.AssetInfo {
+		assetIds = append(assetIds, assetId)
+	}
+	sort.Slice(assetIds, func(i, j int) bool { return assetIds[i] < assetIds[j] })
+	for _, assetId := range assetIds {
+		asset := accountInfo.AssetInfo[assetId]
+		leaf, err := tree.ComputeAccountAssetLeafHash(asset.Balance.String(), asset.LpAmount.String(),
+			asset.OfferCanceledOrFinalized.String())
+		if err != nil {
+			return fmt.Errorf("asset %d of account %d: %v", assetId, accountInfo.AccountIndex, err)
+		}
+		err = stateAssetTree.Set(uint64(assetId), leaf)
+		if err != nil {
+			return err
+		}
+		err = v.verifyLeaf(assetTree, assetId, &Mismatch{Type: MismatchAsset, Index: accountInfo.AccountIndex,
+			AssetId: &assetId}, leaf)
+		if err != nil {
+			return err
+		}
+	}
+	err = v.verifyRoot(assetTree, stateAssetTree, &Mismatch{Type: MismatchAssetTree, Index: accountInfo.AccountIndex})
+	if err != nil {
+		return err
+	}
+
+	leaf, err := tree.ComputeAccountLeafHash(accountInfo.AccountNameHash, accountInfo.PublicKey, accountInfo.Nonce,
+		accountInfo.CollectionNonce, stateAssetTree.Root())
+	if err != nil {
+		return fmt.Errorf("account %d: %v", accountInfo.AccountIndex, err)
+	}
+	err = v.stateAccountTree.Set(uint64(accountInfo.AccountIndex), leaf)
+	if err != nil {
+		return err
+	}
+	return v.verifyLeaf(v.accountTree, accountInfo.AccountIndex,
+		&Mismatch{Type: MismatchAccount, Index: accountInfo.AccountIndex}, leaf)
+}
+
+// VerifyLiquidity compares the liquidity with the leaf in the liquidity tree.
+func (v *Verifier) VerifyLiquidity(liquidityInfo *liquidity.Liquidity) error {
+	leaf, err := tree.ComputeLiquidityAssetLeafHash(liquidityInfo.AssetAId, liquidityInfo.AssetA,
+		liquidityInfo.AssetBId, liquidityInfo.AssetB, liquidityInfo.LpAmount, liquidityInfo.KLast,
+		liquidityInfo.FeeRate, liquidityInfo.TreasuryAccountIndex, liquidityInfo.TreasuryRate)
+	if err != nil {
+		return fmt.Errorf("pair %d: %v", liquidityInfo.PairIndex, err)
+	}
+	err = v.stateLiquidityTree.Set(uint64(liquidityInfo.PairIndex), leaf)
+	if err != nil {
+		return err
+	}
+	return v.verifyLeaf(v.liquidityTree, liquidityInfo.PairIndex,
+		&Mismatch{Type: MismatchLiquidity, Index: liquidityInfo.PairIndex}, leaf)
+}
+
+// VerifyNft compares the nft with the leaf in the nft tree.
+func (v *Verifier) VerifyNft(nftInfo *nft.L2Nft) error {
+	leaf, err := tree.ComputeNftAssetLeafHash(nftInfo.CreatorAccountIndex, nftInfo.OwnerAccountIndex,
+		nftInfo.NftContentHash, nftInfo.NftL1Address, nftInfo.NftL1TokenId, nftInfo.CreatorTreasuryRate,
+		nftInfo.CollectionId)
+	if err != nil {
+		return fmt.Errorf("nft %d: %v", nftInfo.NftIndex, err)
+	}
+	err = v.stateNftTree.Set(uint64(nftInfo.NftIndex), leaf)
+	if err != nil {
+		return err
+	}
+	return v.verifyLeaf(v.nftTree, nftInfo.NftIndex, &Mismatch{Type: MismatchNft, Index: nftInfo.NftIndex},
+		leaf)
+}
+
+// VerifyRoots compares the roots of the trees with the ones rebuilt from all the states verified, and the state root
+// of the trees with the one of the block. It is called after all the states are verified.
+func (v *Verifier) VerifyRoots(stateRoot string) error {
+	err := v.verifyRoot(v.accountTree, v.stateAccountTree, &Mismatch{Type: MismatchAccountTree})
+	if err != nil {
+		return err
+	}
+	err = v.verifyRoot(v.liquidityTree, v.stateLiquidityTree, &Mismatch{Type: MismatchLiquidityTree})
+	if err != nil {
+		return err
+	}
+	err = v.verifyRoot(v.nftTree, v.stateNftTree, &Mismatch{Type: MismatchNftTree})
+	if err != nil {
+		return err
+	}
+	treeStateRoot := common.Bytes2Hex(tree.ComputeStateRootHash(v.accountTree.Root(), v.liquidityTree.Root(),
+		v.nftTree.Root()))
+	if treeStateRoot != stateRoot {
+		return v.mismatch(&Mismatch{Type: MismatchStateRoot, Index: v.height, Tree: treeStateRoot, State: stateRoot})
+	}
+	return nil
+}
+
+func (v *Verifier) verifyLeaf(smt bsmt.SparseMerkleTree, key int64, mismatch *Mismatch, leaf []byte) error {
+	treeLeaf, err := smt.Get(uint64(key), nil)
+	if errors.Is(err, bsmt.ErrEmptyRoot) || errors.Is(err, bsmt.ErrNodeNotFound) {
+		treeLeaf, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+	mismatch.Tree, mismatch.State = common.Bytes2Hex(treeLeaf), common.Bytes2Hex(leaf)
+	if mismatch.Tree != mismatch.State {
+		return v.mismatch(mismatch)
+	}
+	return nil
+}
+
+func (v *Verifier) verifyRoot(smt, stateTree bsmt.SparseMerkleTree, mismatch *Mismatch) error {
+	mismatch.Tree, mismatch.State = common.Bytes2Hex(smt.Root()), common.Bytes2Hex(stateTree.Root())
+	if mismatch.Tree != mismatch.State {
+		return v.mismatch(mismatch)
+	}
+	return nil
+}
+
+func (v *Verifier) mismatch(mismatch *Mismatch) error {
+	v.mismatches++
+	return v.report(mismatch)
+}
+
+func newMemTree(depth uint8, nilHash []byte) (bsmt.SparseMerkleTree, error) {
+	return bsmt.NewBASSparseMerkleTree(bsmt.NewHasher(zmimc.Hmimc), memory.NewMemoryDB(), depth, nilHash)
+}
@@ -0,0 +1,179 @@
+package treeverify
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	bsmt "github.com/bnb-chain/zkbas-smt"
+	"github.com/bnb-chain/zkbas/dao/liquidity"
+	"github.com/bnb-chain/zkbas/dao/nft"
+	"github.com/bnb-chain/zkbas/tree"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+const testPk = "58130e24cd20d9de8a110a20751f0a9b36089400ac0f20ca1993c28ee663318a"
+
+type testState struct {
+	accounts  []*types.AccountInfo
+	liquidity []*liquidity.Liquidity
+	nfts      []*nft.L2Nft
+}
+
+func newTestState() *testState {
+	s := &testState{}
+	for i := int64(0); i < 2; i++ {
+		s.accounts = append(s.accounts, &types.AccountInfo{
+			AccountIndex:    i,
+			AccountNameHash: "0x04b2ea4f6f9a6b6a8b6c7e2e2e24bbf0c7f2e1a1d2c3b4a5968778695a4b3c2d",
+			PublicKey:       testPk,
+			Nonce:           i + 1,
+			AssetInfo: map[int64]*types.AccountAsset{
+				0: {AssetId: 0, Balance: big.NewInt(100 * i), LpAmount: big.NewInt(0),
+					OfferCanceledOrFinalized: big.NewInt(0)},
+				2: {AssetId: 2, Balance: big.NewInt(1), LpAmount: big.NewInt(0), OfferCanceledOrFinalized: big.NewInt(0)},
+			},
+		})
+	}
+	s.liquidity = append(s.liquidity, &liquidity.Liquidity{
+		PairIndex: 0, AssetAId: 0, AssetA: "1000", AssetBId: 2, AssetB: "2000", LpAmount: "1414", KLast: "2000000",
+		FeeRate: 30, TreasuryAccountIndex: 0, TreasuryRate: 5,
+	})
+	s.nfts = append(s.nfts, &nft.L2Nft{
+		NftIndex: 3, CreatorAccountIndex: 1, OwnerAccountIndex: 1, NftContentHash: "0x01",
+		NftL1Address: "0x0000000000000000000000000000000000000000", NftL1TokenId: "0", CreatorTreasuryRate: 10,
+	})
+	return s
+}
+
+// commitTestState writes the leaves of the states into the trees at version 1, and returns the state root.
+func commitTestState(t *testing.T, treeCtx *tree.Context, s *testState) string {
+	accountTree, err := tree.NewEmptyAccountTree(treeCtx, 0)
+	assert.NoError(t, err)
+	liquidityTree, err := tree.NewEmptyLiquidityTree(treeCtx, 0)
+	assert.NoError(t, err)
+	nftTree, err := tree.NewEmptyNftTree(treeCtx, 0)
+	assert.NoError(t, err)
+	for _, accountInfo := range s.accounts {
+		assetTree, err := tree.NewEmptyAccountAssetTree(treeCtx, accountInfo.AccountIndex, 0)
+		assert.NoError(t, err)
+		for assetId, asset := range accountInfo.AssetInfo {
+			leaf, err := tree.AssetToNode(asset.Balance.String(), asset.LpAmount.String(),
+				asset.OfferCanceledOrFinalized.String())
+			assert.NoError(t, err)
+			assert.NoError(t, assetTree.Set(uint64(assetId), leaf))
+		}
+		_, err = assetTree.Commit(nil)
+		assert.NoError(t, err)
+		leaf, err := tree.AccountToNode(accountInfo.AccountNameHash, accountInfo.PublicKey, accountInfo.Nonce,
+			accountInfo.CollectionNonce, assetTree.Root())
+		assert.NoError(t, err)
+		assert.NoError(t, accountTree.Set(uint64(accountInfo.AccountIndex), leaf))
+	}
+	for _, l := range s.liquidity {
+		leaf, err := tree.LiquidityAssetToNode(l.AssetAId, l.AssetA, l.AssetBId, l.AssetB, l.LpAmount, l.KLast,
+			l.FeeRate, l.TreasuryAccountIndex, l.TreasuryRate)
+		assert.NoError(t, err)
+		assert.NoError(t, liquidityTree.Set(uint64(l.PairIndex), leaf))
+	}
+	for _, n := range s.nfts {
+		leaf, err := tree.NftAssetToNode(&tree.AccountL2NftHistory{
+			CreatorAccountIndex: n.CreatorAccountIndex, OwnerAccountIndex: n.OwnerAccountIndex,
+			NftContentHash: n.NftContentHash, NftL1Address: n.NftL1Address, NftL1TokenId: n.NftL1TokenId,
+			CreatorTreasuryRate: n.CreatorTreasuryRate, CollectionId: n.CollectionId,
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, nftTree.Set(uint64(n.NftIndex), leaf))
+	}
+	for _, smt := range []bsmt.SparseMerkleTree{accountTree, liquidityTree, nftTree} {
+		_, err = smt.Commit(nil)
+		assert.NoError(t, err)
+	}
+	return common.Bytes2Hex(tree.ComputeStateRootHash(accountTree.Root(), liquidityTree.Root(), nftTree.Root()))
+}
+
+func verifyTestState(v *Verifier, s *testState) error {
+	for _, accountInfo := range s.accounts {
+		err := v.VerifyAccount(accountInfo)
+		if err != nil {
+			return err
+		}
+	}
+	for _, liquidityInfo := range s.liquidity {
+		err := v.VerifyLiquidity(liquidityInfo)
+		if err != nil {
+			return err
+		}
+	}
+	for _, nftInfo := range s.nfts {
+		err := v.VerifyNft(nftInfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestVerifier(t *testing.T) {
+	treeCtx := &tree.Context{
+		Name:          "committer",
+		Driver:        tree.LevelDB,
+		LevelDBOption: &tree.LevelDBOption{File: t.TempDir()},
+	}
+	assert.NoError(t, tree.SetupTreeDB(treeCtx))
+	s := newTestState()
+	stateRoot := commitTestState(t, treeCtx, s)
+
+	verifyState := func(s *testState, stateRoot string) []*Mismatch {
+		var mismatches []*Mismatch
+		v, err := NewVerifier(treeCtx, 1, func(mismatch *Mismatch) error {
+			mismatches = append(mismatches, mismatch)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, verifyTestState(v, s))
+		assert.NoError(t, v.VerifyRoots(stateRoot))
+		assert.Equal(t, len(mismatches), v.Mismatches())
+		return mismatches
+	}
+	assert.Empty(t, verifyState(s, stateRoot))
+
+	// the state root of the trees must be the one of the block.
+	mismatches := verifyState(s, "00")
+	assert.Equal(t, []*Mismatch{{Type: MismatchStateRoot, Index: 1, Tree: stateRoot, State: "00"}}, mismatches)
+
+	// the trees must be at the block height.
+	_, err := NewVerifier(treeCtx, 2, nil)
+	assert.EqualError(t, err, "account tree is at version 1, but the block height is 2")
+
+	// the balance changed is reported on the asset, the asset tree, the account and the account tree.
+	s.accounts[1].AssetInfo[2].Balance = big.NewInt(2)
+	mismatches = verifyState(s, stateRoot)
+	assetId := int64(2)
+	assert.Equal(t, &Mismatch{Type: MismatchAsset, Index: 1, AssetId: &assetId,
+		Tree: mismatches[0].Tree, State: mismatches[0].State}, mismatches[0])
+	assert.NotEqual(t, mismatches[0].Tree, mismatches[0].State)
+	var mismatchTypes []string
+	for _, mismatch := range mismatches {
+		mismatchTypes = append(mismatchTypes, mismatch.Type)
+	}
+	assert.Equal(t, []string{MismatchAsset, MismatchAssetTree, MismatchAccount, MismatchAccountTree}, mismatchTypes)
+	s.accounts[1].AssetInfo[2].Balance = big.NewInt(1)
+
+	// the nft missing in the state is only found by the root of the nft tree.
+	s.nfts = nil
+	mismatches = verifyState(s, stateRoot)
+	assert.Len(t, mismatches, 1)
+	assert.Equal(t, MismatchNftTree, mismatches[0].Type)
+
+	// the pair missing in the tree is reported with an empty leaf.
+	s = newTestState()
+	s.liquidity = append(s.liquidity, &liquidity.Liquidity{PairIndex: 1, AssetA: "0", AssetB: "0", LpAmount: "0",
+		KLast: "0"})
+	mismatches = verifyState(s, stateRoot)
+	assert.Equal(t, MismatchLiquidity, mismatches[0].Type)
+	assert.Equal(t, int64(1), mismatches[0].Index)
+	assert.Equal(t, "", mismatches[0].Tree)
+}
@@ -18,8 +18,12 @@
 package tree
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
@@ -35,13 +39,15 @@ func accountAssetNamespace(index int64) string {
 	return AccountAssetPrefix + strconv.Itoa(int(index)) + ":"
 }
 
+// InitAccountTree initializes the account tree at the block height, the asset trees of the accounts are opened on
+// demand by the returned cache. The asset trees reloaded into a persistent tree db are not kept in memory.
 func InitAccountTree(
 	accountModel AccountModel,
 	accountHistoryModel AccountHistoryModel,
 	blockHeight int64,
 	ctx *Context,
 ) (
-	accountTree bsmt.SparseMerkleTree, accountAssetTrees []bsmt.SparseMerkleTree, err error,
+	accountTree bsmt.SparseMerkleTree, accountAssetTrees *AssetTreeCache, err error,
 ) {
 	accountNums, err := accountHistoryModel.GetValidAccountCount(blockHeight)
 	if err != nil {
@@ -52,17 +58,6 @@ func InitAccountTree(
 	opts := ctx.Options(blockHeight)
 
 	// init account state trees
-	accountAssetTrees = make([]bsmt.SparseMerkleTree, accountNums)
-	for index := int64(0); index < accountNums; index++ {
-		// create account assets tree
-		accountAssetTrees[index], err = bsmt.NewBASSparseMerkleTree(bsmt.NewHasher(zmimc.Hmimc),
-			SetNamespace(ctx, accountAssetNamespace(index)), AssetTreeHeight, NilAccountAssetNodeHash,
-			opts...)
-		if err != nil {
-			logx.Errorf("unable to create new tree by assets: %s", err.Error())
-			return nil, nil, err
-		}
-	}
 	accountTree, err = bsmt.NewBASSparseMerkleTree(bsmt.NewHasher(zmimc.Hmimc),
 		SetNamespace(ctx, AccountPrefix), AccountTreeHeight, NilAccountNodeHash,
 		opts...)
@@ -72,33 +67,61 @@ func InitAccountTree(
 	}
 
 	if accountNums == 0 {
+		accountAssetTrees, err = NewAssetTreeCache(ctx, 0, accountTree.LatestVersion())
+		if err != nil {
+			return nil, nil, err
+		}
 		return accountTree, accountAssetTrees, nil
 	}
 
 	if ctx.IsLoad() {
-		for i := 0; i < int(accountNums); i += ctx.BatchReloadSize() {
-			err := reloadAccountTreeFromRDB(
-				accountModel, accountHistoryModel, blockHeight,
-				i, i+ctx.BatchReloadSize(),
-				accountTree, accountAssetTrees)
+		// the asset trees in memory are kept by the cache as they can't be reopened.
+		memAssetTrees := make(map[int64]bsmt.SparseMerkleTree)
+		progress, err := ctx.reloadProgress(blockHeight)
+		if err != nil {
+			return nil, nil, err
+		}
+		if progress.reloaded(AccountPrefix) {
+			logx.Infof("account tree has been reloaded at %d", blockHeight)
+		} else {
+			start := time.Now()
+			var resumed int
+			for i := 0; i < int(accountNums); i += ctx.BatchReloadSize() {
+				batchResumed, err := reloadAccountTreeFromRDB(
+					accountModel, accountHistoryModel, blockHeight,
+					i, ctx.BatchReloadSize(), ctx,
+					accountTree, progress, memAssetTrees)
+				if err != nil {
+					return nil, nil, err
+				}
+				resumed += batchResumed
+				reloaded := i + ctx.BatchReloadSize()
+				if reloaded > int(accountNums) {
+					reloaded = int(accountNums)
+				}
+				logx.Infof("reload account tree: %d/%d accounts, %d asset trees resumed, %v elapsed",
+					reloaded, accountNums, resumed, time.Since(start))
+			}
+
+			err = commitReloadedTree(ctx, accountTree, blockHeight)
 			if err != nil {
+				logx.Errorf("unable to commit account tree: %s", err.Error())
 				return nil, nil, err
 			}
-		}
-
-		for i := range accountAssetTrees {
-			_, err := accountAssetTrees[i].Commit(nil)
+			progress.Trees = append(progress.Trees, AccountPrefix)
+			err = ctx.setReloadProgress(progress)
 			if err != nil {
-				logx.Errorf("unable to set asset to tree: %s", err.Error())
 				return nil, nil, err
 			}
 		}
 
-		_, err = accountTree.Commit(nil)
+		accountAssetTrees, err = NewAssetTreeCache(ctx, accountNums, accountTree.LatestVersion())
 		if err != nil {
-			logx.Errorf("unable to commit account tree: %s", err.Error())
 			return nil, nil, err
 		}
+		for index, assetTree := range memAssetTrees {
+			accountAssetTrees.pinned[index] = assetTree
+		}
 		return accountTree, accountAssetTrees, nil
 	}
 
@@ -112,33 +135,33 @@ func InitAccountTree(
 		}
 	}
 
-	for i := range accountAssetTrees {
-		if accountAssetTrees[i].LatestVersion() > bsmt.Version(blockHeight) && !accountAssetTrees[i].IsEmpty() {
-			logx.Infof("asset tree %d version [%d] is higher than block, rollback to %d", i, accountAssetTrees[i].LatestVersion(), blockHeight)
-			err := accountAssetTrees[i].Rollback(bsmt.Version(blockHeight))
-			if err != nil {
-				logx.Errorf("unable to rollback asset [%d] tree: %s, version: %d", i, err.Error(), blockHeight)
-				return nil, nil, err
-			}
-		}
+	// the asset trees higher than the block are rolled back when they are opened.
+	accountAssetTrees, err = NewAssetTreeCache(ctx, accountNums, accountTree.LatestVersion())
+	if err != nil {
+		return nil, nil, err
 	}
-
 	return accountTree, accountAssetTrees, nil
 }
 
+// reloadAccountTreeFromRDB reloads the accounts in the batch, the asset trees are rebuilt and committed by the
+// workers in parallel, then the account leaves are set in order. The asset trees in memory are added to the map. It
+// returns the number of the asset trees which had been reloaded by an interrupted reload, the progress is recorded
+// once the asset trees of the batch are committed.
 func reloadAccountTreeFromRDB(
 	accountModel AccountModel,
 	accountHistoryModel AccountHistoryModel,
 	blockHeight int64,
 	offset, limit int,
+	ctx *Context,
 	accountTree bsmt.SparseMerkleTree,
-	accountAssetTrees []bsmt.SparseMerkleTree,
-) error {
+	progress *reloadProgress,
+	memAssetTrees map[int64]bsmt.SparseMerkleTree,
+) (int, error) {
 	_, accountHistories, err := accountHistoryModel.GetValidAccounts(blockHeight,
 		limit, offset)
 	if err != nil {
 		logx.Errorf("unable to get all accountHistories")
-		return err
+		return 0, err
 	}
 
 	var (
@@ -150,7 +173,7 @@ func reloadAccountTreeFromRDB(
 			accountInfo, err := accountModel.GetAccountByIndex(accountHistory.AccountIndex)
 			if err != nil {
 				logx.Errorf("unable to get account by account index: %s", err.Error())
-				return err
+				return 0, err
 			}
 			accountInfoMap[accountHistory.AccountIndex] = &account.Account{
 				AccountIndex:    accountInfo.AccountIndex,
@@ -174,53 +197,157 @@ func reloadAccountTreeFromRDB(
 	}
 
 	// get related account info
+	accountInfos := make([]*types.AccountInfo, 0, len(accountHistories))
+	assetTrees := make(map[int64]bsmt.SparseMerkleTree, len(accountHistories))
 	for i := int64(0); i < int64(len(accountHistories)); i++ {
 		accountIndex := accountHistories[i].AccountIndex
 		if accountInfoMap[accountIndex] == nil {
 			logx.Errorf("invalid account index")
-			return errors.New("invalid account index")
+			return 0, errors.New("invalid account index")
 		}
-		oAccountInfo := accountInfoMap[accountIndex]
-		accountInfo, err := chain.ToFormatAccountInfo(oAccountInfo)
+		accountInfo, err := chain.ToFormatAccountInfo(accountInfoMap[accountIndex])
 		if err != nil {
 			logx.Errorf("unable to convert to format account info: %s", err.Error())
-			return err
+			return 0, err
 		}
-		// create account assets node
-		for assetId, assetInfo := range accountInfo.AssetInfo {
-			hashVal, err := AssetToNode(
-				assetInfo.Balance.String(),
-				assetInfo.LpAmount.String(),
-				assetInfo.OfferCanceledOrFinalized.String(),
-			)
-			if err != nil {
-				logx.Errorf("unable to convert asset to node: %s", err.Error())
-				return err
-			}
-			err = accountAssetTrees[accountIndex].Set(uint64(assetId), hashVal)
-			if err != nil {
-				logx.Errorf("unable to set asset to tree: %s", err.Error())
-				return err
-			}
+		accountInfos = append(accountInfos, accountInfo)
+		// create account assets tree, the asset trees have their own hashers as they are reloaded in parallel.
+		assetTrees[accountIndex], err = newAssetTree(SetNamespace(ctx, accountAssetNamespace(accountIndex)),
+			ctx.Options(blockHeight)...)
+		if err != nil {
+			logx.Errorf("unable to create new tree by assets: %s", err.Error())
+			return 0, err
+		}
+	}
+
+	resumed, err := reloadAssetTrees(ctx, blockHeight, progress.AccountIndex, accountInfos, assetTrees)
+	if err != nil {
+		return 0, err
+	}
+	for _, accountInfo := range accountInfos {
+		if accountInfo.AccountIndex > progress.AccountIndex {
+			progress.AccountIndex = accountInfo.AccountIndex
 		}
+	}
+	err = ctx.setReloadProgress(progress)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, accountInfo := range accountInfos {
 		accountHashVal, err := AccountToNode(
-			accountInfoMap[accountIndex].AccountNameHash,
-			accountInfoMap[accountIndex].PublicKey,
-			accountInfoMap[accountIndex].Nonce,
-			accountInfoMap[accountIndex].CollectionNonce,
-			accountAssetTrees[accountIndex].Root(),
+			accountInfo.AccountNameHash,
+			accountInfo.PublicKey,
+			accountInfo.Nonce,
+			accountInfo.CollectionNonce,
+			assetTrees[accountInfo.AccountIndex].Root(),
 		)
 		if err != nil {
 			logx.Errorf("unable to convert account to node: %s", err.Error())
-			return err
+			return 0, err
 		}
-		err = accountTree.Set(uint64(accountIndex), accountHashVal)
+		err = accountTree.Set(uint64(accountInfo.AccountIndex), accountHashVal)
 		if err != nil {
 			logx.Errorf("unable to set account to tree: %s", err.Error())
+			return 0, err
+		}
+	}
+
+	if ctx.Driver == MemoryDB {
+		for index, assetTree := range assetTrees {
+			memAssetTrees[index] = assetTree
+		}
+	}
+	return resumed, nil
+}
+
+// reloadAssetTrees rebuilds and commits the asset trees of the accounts by the reload workers, the asset trees are
+// independent of each other. The asset trees of the accounts up to the reloaded index are skipped, it returns the
+// number of the asset trees skipped as they had been reloaded.
+func reloadAssetTrees(
+	ctx *Context,
+	blockHeight int64,
+	reloadedIndex int64,
+	accountInfos []*types.AccountInfo,
+	accountAssetTrees map[int64]bsmt.SparseMerkleTree,
+) (int, error) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		resumed int
+		lastErr error
+	)
+	jobs := make(chan *types.AccountInfo)
+	for i := 0; i < ctx.ReloadWorkers(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for accountInfo := range jobs {
+				err := reloadAssetTree(ctx, blockHeight, accountInfo, accountAssetTrees[accountInfo.AccountIndex])
+				if err != nil {
+					mu.Lock()
+					lastErr = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, accountInfo := range accountInfos {
+		if accountInfo.AccountIndex <= reloadedIndex {
+			resumed++
+			continue
+		}
+		jobs <- accountInfo
+	}
+	close(jobs)
+	wg.Wait()
+	return resumed, lastErr
+}
+
+func reloadAssetTree(
+	ctx *Context,
+	blockHeight int64,
+	accountInfo *types.AccountInfo,
+	assetTree bsmt.SparseMerkleTree,
+) error {
+	// create account assets node
+	for assetId, assetInfo := range accountInfo.AssetInfo {
+		hashVal, err := AssetToNode(
+			assetInfo.Balance.String(),
+			assetInfo.LpAmount.String(),
+			assetInfo.OfferCanceledOrFinalized.String(),
+		)
+		if err != nil {
+			logx.Errorf("unable to convert asset to node: %s", err.Error())
+			return err
+		}
+		err = assetTree.Set(uint64(assetId), hashVal)
+		if err != nil {
+			logx.Errorf("unable to set asset to tree: %s", err.Error())
 			return err
 		}
 	}
+	err := commitReloadedTree(ctx, assetTree, blockHeight)
+	if err != nil {
+		logx.Errorf("unable to commit asset tree %d: %s", accountInfo.AccountIndex, err.Error())
+		return err
+	}
+	return nil
+}
 
+// commitReloadedTree commits the tree reloaded into a persistent tree db at the block height. The tree may have been
+// committed by an interrupted reload before the progress is recorded, it is then checked against the leaves set
+// again instead of being committed twice.
+func commitReloadedTree(ctx *Context, smt bsmt.SparseMerkleTree, blockHeight int64) error {
+	if ctx.Driver == MemoryDB || smt.LatestVersion() != bsmt.Version(blockHeight) {
+		_, err := smt.Commit(nil)
+		return err
+	}
+	root := smt.Root()
+	smt.Reset()
+	if !bytes.Equal(root, smt.Root()) {
+		return fmt.Errorf("the tree committed at %d by the interrupted reload differs from the history", blockHeight)
+	}
 	return nil
 }
 
@@ -267,6 +394,17 @@ func NewEmptyAccountAssetTree(
 		ctx.Options(int64(blockHeight))...)
 }
 
+func NewEmptyAccountTree(
+	ctx *Context,
+	blockHeight uint64,
+) (tree bsmt.SparseMerkleTree, err error) {
+	return bsmt.NewBASSparseMerkleTree(
+		bsmt.NewHasher(zmimc.Hmimc),
+		SetNamespace(ctx, AccountPrefix),
+		AccountTreeHeight, NilAccountNodeHash,
+		ctx.Options(int64(blockHeight))...)
+}
+
 func NewMemAccountAssetTree() (tree bsmt.SparseMerkleTree, err error) {
 	return bsmt.NewBASSparseMerkleTree(bsmt.NewHasher(zmimc.Hmimc),
 		memory.NewMemoryDB(), AssetTreeHeight, NilAccountAssetNodeHash)
@@ -0,0 +1,132 @@
+package tree
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bsmt "github.com/bnb-chain/zkbas-smt"
+	"github.com/bnb-chain/zkbas/dao/account"
+	"github.com/bnb-chain/zkbas/types"
+)
+
+const testAccountNums = 25
+
+type testAccountModel struct {
+	account.AccountModel
+}
+
+func (m *testAccountModel) GetAccountByIndex(accountIndex int64) (*account.Account, error) {
+	return &account.Account{
+		AccountIndex:    accountIndex,
+		AccountNameHash: "0x04b2ea4f6f9a6b6a8b6c7e2e2e24bbf0c7f2e1a1d2c3b4a5968778695a4b3c2d",
+		PublicKey:       "58130e24cd20d9de8a110a20751f0a9b36089400ac0f20ca1993c28ee663318a",
+	}, nil
+}
+
+type testAccountHistoryModel struct {
+	account.AccountHistoryModel
+	// failOffset fails the batch at the offset once, as if the reload is interrupted.
+	failOffset int
+}
+
+func (m *testAccountHistoryModel) GetValidAccountCount(height int64) (int64, error) {
+	return testAccountNums, nil
+}
+
+func (m *testAccountHistoryModel) GetValidAccounts(height int64, limit int, offset int) (int64, []*AccountHistory, error) {
+	if m.failOffset > 0 && offset == m.failOffset {
+		m.failOffset = 0
+		return 0, nil, errors.New("interrupted")
+	}
+	var histories []*AccountHistory
+	for i := offset; i < offset+limit && i < testAccountNums; i++ {
+		assetInfo, _ := json.Marshal(map[int64]*types.AccountAsset{
+			0: {AssetId: 0, Balance: big.NewInt(int64(i)), LpAmount: big.NewInt(0), OfferCanceledOrFinalized: big.NewInt(0)},
+			int64(i): {AssetId: int64(i), Balance: big.NewInt(1), LpAmount: big.NewInt(0),
+				OfferCanceledOrFinalized: big.NewInt(0)},
+		})
+		histories = append(histories, &AccountHistory{
+			AccountIndex:    int64(i),
+			Nonce:           int64(i),
+			CollectionNonce: types.NilNonce,
+			AssetInfo:       string(assetInfo),
+		})
+	}
+	return int64(len(histories)), histories, nil
+}
+
+func TestReloadAccountTree(t *testing.T) {
+	// the trees reloaded in parallel are the same as the ones reloaded by one worker.
+	ctx := &Context{Driver: MemoryDB}
+	ctx.SetBatchReloadSize(10)
+	ctx.SetReloadWorkers(1)
+	expectedTree, _, err := InitAccountTree(&testAccountModel{}, &testAccountHistoryModel{}, 10, ctx)
+	assert.NoError(t, err)
+	ctx = &Context{Driver: MemoryDB}
+	ctx.SetBatchReloadSize(10)
+	ctx.SetReloadWorkers(4)
+	accountTree, _, err := InitAccountTree(&testAccountModel{}, &testAccountHistoryModel{}, 10, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTree.Root(), accountTree.Root())
+
+	// the reload interrupted is resumed, the asset trees reloaded are not committed again.
+	ctx = &Context{
+		Name:          "test",
+		Driver:        LevelDB,
+		LevelDBOption: &LevelDBOption{File: t.TempDir()},
+		Reload:        true,
+	}
+	ctx.SetOptions(bsmt.InitializeVersion(9))
+	ctx.SetBatchReloadSize(10)
+	ctx.SetReloadWorkers(4)
+	assert.NoError(t, SetupTreeDB(ctx))
+	historyModel := &testAccountHistoryModel{failOffset: 20}
+	_, _, err = InitAccountTree(&testAccountModel{}, historyModel, 10, ctx)
+	assert.EqualError(t, err, "interrupted")
+	accountTree, assetTrees, err := InitAccountTree(&testAccountModel{}, historyModel, 10, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedTree.Root(), accountTree.Root())
+	assert.Equal(t, bsmt.Version(10), accountTree.LatestVersion())
+	for i := int64(0); i < assetTrees.GetNextAccountIndex(); i++ {
+		assetTree, err := assetTrees.Get(i)
+		assert.NoError(t, err)
+		assert.Equal(t, bsmt.Version(10), assetTree.LatestVersion())
+	}
+
+	// the account tree reloaded is not reloaded again.
+	accountTree, _, err = InitAccountTree(&testAccountModel{}, historyModel, 10, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, bsmt.Version(10), accountTree.LatestVersion())
+	assert.Equal(t, expectedTree.Root(), accountTree.Root())
+
+	// the reload interrupted at another height is not resumed.
+	_, _, err = InitAccountTree(&testAccountModel{}, historyModel, 11, ctx)
+	assert.EqualError(t, err, "the reload at block 10 is interrupted, reload at the same height or clear the tree db")
+
+	// the trees committed before the progress is recorded are not committed again.
+	progress, err := ctx.reloadProgress(10)
+	assert.NoError(t, err)
+	progress.AccountIndex = 9
+	progress.Trees = nil
+	assert.NoError(t, ctx.setReloadProgress(progress))
+	accountTree, assetTrees, err = InitAccountTree(&testAccountModel{}, historyModel, 10, ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, bsmt.Version(10), accountTree.LatestVersion())
+	assert.Equal(t, expectedTree.Root(), accountTree.Root())
+	for i := int64(0); i < assetTrees.GetNextAccountIndex(); i++ {
+		assetTree, err := assetTrees.Get(i)
+		assert.NoError(t, err)
+		assert.Equal(t, bsmt.Version(10), assetTree.LatestVersion())
+	}
+
+	// the progress is cleared once the reload finishes.
+	assert.NoError(t, ctx.FinishReload())
+	progress, err = ctx.reloadProgress(11)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), progress.AccountIndex)
+	assert.Empty(t, progress.Trees)
+}
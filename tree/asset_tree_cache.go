@@ -0,0 +1,266 @@
+/*
+ * Copyright © 2021 ZkBAS Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr/mimc"
+	lru "github.com/hashicorp/golang-lru"
+
+	bsmt "github.com/bnb-chain/zkbas-smt"
+	"github.com/bnb-chain/zkbas-smt/database"
+)
+
+const defaultAssetTreeCacheSize = 8192
+
+var ErrAssetTreeNotFound = errors.New("asset tree not found")
+
+// AssetTreeCache holds the asset trees of the accounts, the trees are opened on demand from the tree db. The trees
+// updated since the last commit are pinned until they are committed, the others are kept in a lru cache and reopened
+// once evicted. The trees in memory can't be reopened, so they are all pinned and committed in every version.
+//
+// The asset trees follow the versions of the account tree. A tree which has not been updated for some versions is
+// committed no more, its latest version is moved to the one of the account tree when it is opened for update, and
+// a tree newer than the account tree is rolled back when it is opened.
+type AssetTreeCache struct {
+	ctx *Context
+
+	mu               sync.Mutex
+	version          bsmt.Version
+	nextAccountIndex int64
+	pinned           map[int64]bsmt.SparseMerkleTree
+	trees            *lru.Cache
+
+	// the next account index of the versions the trees may be rolled back to.
+	accounts map[bsmt.Version]int64
+}
+
+// NewAssetTreeCache returns the cache of the asset trees of the accounts before the next account index, the trees
+// are at the version of the account tree.
+func NewAssetTreeCache(ctx *Context, nextAccountIndex int64, version bsmt.Version) (*AssetTreeCache, error) {
+	trees, err := lru.New(ctx.AssetTreeCacheSize())
+	if err != nil {
+		return nil, err
+	}
+	return &AssetTreeCache{
+		ctx:              ctx,
+		version:          version,
+		nextAccountIndex: nextAccountIndex,
+		pinned:           make(map[int64]bsmt.SparseMerkleTree),
+		trees:            trees,
+		accounts:         map[bsmt.Version]int64{version: nextAccountIndex},
+	}, nil
+}
+
+// GetNextAccountIndex returns the index of the next account to be added.
+func (c *AssetTreeCache) GetNextAccountIndex() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nextAccountIndex
+}
+
+// Get returns the asset tree of the account for reading, it must not be updated.
+func (c *AssetTreeCache) Get(accountIndex int64) (bsmt.SparseMerkleTree, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.get(accountIndex, false)
+}
+
+// GetForUpdate returns the asset tree of the account to be updated, it is pinned until the next commit.
+func (c *AssetTreeCache) GetForUpdate(accountIndex int64) (bsmt.SparseMerkleTree, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.get(accountIndex, true)
+}
+
+// AddAccount adds the empty asset tree of the new account, the account index must be the next one.
+func (c *AssetTreeCache) AddAccount(accountIndex int64) (bsmt.SparseMerkleTree, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if accountIndex != c.nextAccountIndex {
+		return nil, fmt.Errorf("invalid account index %d, the next one is %d", accountIndex, c.nextAccountIndex)
+	}
+	smt, err := c.open(accountIndex, true)
+	if err != nil {
+		return nil, err
+	}
+	c.pinned[accountIndex] = smt
+	c.nextAccountIndex++
+	return smt, nil
+}
+
+// Commit commits the pinned asset trees, they must be committed at the same version as the account tree. The old
+// versions are pruned as CommitTrees does.
+func (c *AssetTreeCache) Commit(pruneVersion uint64, version bsmt.Version) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for idx, smt := range c.pinned {
+		assetPrunedVersion := bsmt.Version(pruneVersion)
+		if smt.LatestVersion() < assetPrunedVersion {
+			assetPrunedVersion = smt.LatestVersion()
+		}
+		ver, err := smt.Commit(&assetPrunedVersion)
+		if err != nil {
+			return fmt.Errorf("unable to commit asset tree [%d], tree ver: %d, prune ver: %d: %v", idx, ver,
+				assetPrunedVersion, err)
+		}
+		if ver != version {
+			return fmt.Errorf("asset tree [%d] is committed at version %d, but the account tree at %d", idx, ver,
+				version)
+		}
+	}
+	c.version = version
+	c.accounts[version] = c.nextAccountIndex
+	for ver := range c.accounts {
+		if ver < bsmt.Version(pruneVersion) {
+			delete(c.accounts, ver)
+		}
+	}
+	if c.ctx.Driver == MemoryDB {
+		return nil
+	}
+	for idx, smt := range c.pinned {
+		c.trees.Add(idx, smt)
+	}
+	c.pinned = make(map[int64]bsmt.SparseMerkleTree)
+	return nil
+}
+
+// Rollback rolls back the asset trees to the version, the uncommitted updates are discarded. The trees in the tree db
+// are dropped from the cache and rolled back when they are opened again.
+func (c *AssetTreeCache) Rollback(version uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ver := bsmt.Version(version)
+	nextAccountIndex, ok := c.accounts[ver]
+	if !ok {
+		// no account is added before the lowest version recorded, e.g. the trees in memory are created at the
+		// version after the block.
+		lowest := bsmt.Version(0)
+		for v, index := range c.accounts {
+			if v > ver && (!ok || v < lowest) {
+				lowest, nextAccountIndex, ok = v, index, true
+			}
+		}
+		if !ok {
+			return fmt.Errorf("unable to rollback asset trees to version %d, the accounts are unknown", ver)
+		}
+	}
+	for v := range c.accounts {
+		if v > ver {
+			delete(c.accounts, v)
+		}
+	}
+	c.version = ver
+	c.nextAccountIndex = nextAccountIndex
+	if c.ctx.Driver != MemoryDB {
+		c.pinned = make(map[int64]bsmt.SparseMerkleTree)
+		c.trees.Purge()
+		return nil
+	}
+	for idx, smt := range c.pinned {
+		if idx >= nextAccountIndex {
+			delete(c.pinned, idx)
+			continue
+		}
+		if smt.LatestVersion() > ver && !smt.IsEmpty() {
+			err := smt.Rollback(ver)
+			if err != nil {
+				return fmt.Errorf("unable to rollback asset tree [%d], ver: %d: %v", idx, ver, err)
+			}
+			continue
+		}
+		smt.Reset()
+	}
+	return nil
+}
+
+func (c *AssetTreeCache) get(accountIndex int64, update bool) (bsmt.SparseMerkleTree, error) {
+	if accountIndex < 0 || accountIndex >= c.nextAccountIndex {
+		return nil, ErrAssetTreeNotFound
+	}
+	if smt, ok := c.pinned[accountIndex]; ok {
+		return smt, nil
+	}
+	if value, ok := c.trees.Get(accountIndex); ok {
+		smt := value.(bsmt.SparseMerkleTree)
+		if !update {
+			return smt, nil
+		}
+		c.trees.Remove(accountIndex)
+		// the tree opened for reading or before the last commits is reopened to move its latest version.
+		if smt.LatestVersion() == c.version {
+			c.pinned[accountIndex] = smt
+			return smt, nil
+		}
+	}
+	smt, err := c.open(accountIndex, update)
+	if err != nil {
+		return nil, err
+	}
+	if update || c.ctx.Driver == MemoryDB {
+		c.pinned[accountIndex] = smt
+	} else {
+		c.trees.Add(accountIndex, smt)
+	}
+	return smt, nil
+}
+
+// open opens the asset tree at the version of the cache. The tree not updated since an older version has the same
+// nodes in the versions after it, the tree opened for reading is left at its latest version, and the one opened for
+// update is moved forward to the version of the cache so it is committed at the next version.
+func (c *AssetTreeCache) open(accountIndex int64, update bool) (bsmt.SparseMerkleTree, error) {
+	db := SetNamespace(c.ctx, accountAssetNamespace(accountIndex))
+	if update && c.ctx.Driver != MemoryDB {
+		err := forwardVersion(db, c.version)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// the trees never committed are initialized at the version of the cache.
+	opts := append(c.ctx.Options(0), bsmt.InitializeVersion(c.version))
+	smt, err := newAssetTree(db, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if smt.LatestVersion() <= c.version {
+		return smt, nil
+	}
+	if !smt.IsEmpty() {
+		err = smt.Rollback(c.version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to rollback asset tree [%d], ver: %d: %v", accountIndex, c.version, err)
+		}
+		return smt, nil
+	}
+	// the empty tree can't be rolled back, e.g. the one of an account added in a version rolled back.
+	err = setVersion(db, latestVersionKey, c.version)
+	if err != nil {
+		return nil, err
+	}
+	return newAssetTree(db, opts...)
+}
+
+// newAssetTree creates the asset tree with its own hasher, the asset trees may be used concurrently.
+func newAssetTree(db database.TreeDB, opts ...bsmt.Option) (bsmt.SparseMerkleTree, error) {
+	return bsmt.NewBASSparseMerkleTree(bsmt.NewHasher(mimc.NewMiMC()), db, AssetTreeHeight,
+		NilAccountAssetNodeHash, opts...)
+}
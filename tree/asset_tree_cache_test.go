@@ -0,0 +1,154 @@
+package tree
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	bsmt "github.com/bnb-chain/zkbas-smt"
+)
+
+func setAssetLeaf(t *testing.T, cache *AssetTreeCache, accountIndex int64, balance int64) []byte {
+	assetTree, err := cache.GetForUpdate(accountIndex)
+	assert.NoError(t, err)
+	assert.NoError(t, assetTree.Set(1, common.LeftPadBytes(big.NewInt(balance).Bytes(), 32)))
+	return assetTree.Root()
+}
+
+func assetRoot(t *testing.T, cache *AssetTreeCache, accountIndex int64) []byte {
+	assetTree, err := cache.Get(accountIndex)
+	assert.NoError(t, err)
+	return assetTree.Root()
+}
+
+func TestAssetTreeCache(t *testing.T) {
+	ctx := &Context{
+		Name:          "test",
+		Driver:        LevelDB,
+		LevelDBOption: &LevelDBOption{File: t.TempDir()},
+	}
+	ctx.SetAssetTreeCacheSize(1)
+	assert.NoError(t, SetupTreeDB(ctx))
+	cache, err := NewAssetTreeCache(ctx, 0, 0)
+	assert.NoError(t, err)
+
+	// version 1 adds two accounts.
+	for i := int64(0); i < 2; i++ {
+		_, err = cache.AddAccount(i)
+		assert.NoError(t, err)
+		setAssetLeaf(t, cache, i, 1)
+	}
+	_, err = cache.AddAccount(3)
+	assert.EqualError(t, err, "invalid account index 3, the next one is 2")
+	assert.NoError(t, cache.Commit(0, 1))
+	root1 := assetRoot(t, cache, 1)
+
+	// the tree not updated in version 2 is moved to version 3 when it is updated again.
+	setAssetLeaf(t, cache, 0, 2)
+	assert.NoError(t, cache.Commit(0, 2))
+	root3 := setAssetLeaf(t, cache, 1, 3)
+	assert.NoError(t, cache.Commit(0, 3))
+	assert.Equal(t, root3, assetRoot(t, cache, 1))
+
+	// the account added in version 4 is dropped by the rollback, and can be added again.
+	_, err = cache.AddAccount(2)
+	assert.NoError(t, err)
+	setAssetLeaf(t, cache, 2, 4)
+	assert.NoError(t, cache.Commit(0, 4))
+	assert.NoError(t, cache.Rollback(3))
+	assert.Equal(t, int64(2), cache.GetNextAccountIndex())
+	_, err = cache.Get(2)
+	assert.Equal(t, ErrAssetTreeNotFound, err)
+	_, err = cache.AddAccount(2)
+	assert.NoError(t, err)
+	assert.NoError(t, cache.Commit(0, 4))
+
+	// the trees reopened are rolled back to the version of the cache.
+	cache, err = NewAssetTreeCache(ctx, 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, root1, assetRoot(t, cache, 1))
+	assetTree, err := cache.Get(0)
+	assert.NoError(t, err)
+	assert.Equal(t, bsmt.Version(2), assetTree.LatestVersion())
+
+	// the trees must be committed at the version of the account tree.
+	setAssetLeaf(t, cache, 1, 5)
+	assert.EqualError(t, cache.Commit(0, 5), "asset tree [1] is committed at version 3, but the account tree at 5")
+}
+
+func TestMemAssetTreeCache(t *testing.T) {
+	ctx := &Context{Driver: MemoryDB}
+	ctx.SetAssetTreeCacheSize(1)
+	cache, err := NewAssetTreeCache(ctx, 0, 5)
+	assert.NoError(t, err)
+	for i := int64(0); i < 3; i++ {
+		_, err = cache.AddAccount(i)
+		assert.NoError(t, err)
+		setAssetLeaf(t, cache, i, 1)
+	}
+	assert.NoError(t, cache.Commit(0, 6))
+	root := assetRoot(t, cache, 0)
+
+	// the trees in memory are all kept and committed in every version.
+	setAssetLeaf(t, cache, 0, 2)
+	assert.NoError(t, cache.Commit(0, 7))
+	for i := int64(0); i < 3; i++ {
+		assetTree, err := cache.Get(i)
+		assert.NoError(t, err)
+		assert.Equal(t, bsmt.Version(7), assetTree.LatestVersion())
+	}
+	assert.NoError(t, cache.Rollback(6))
+	assert.Equal(t, root, assetRoot(t, cache, 0))
+}
+
+func TestForwardAssetTreeVersion(t *testing.T) {
+	ctx := &Context{
+		Name:          "test",
+		Driver:        LevelDB,
+		LevelDBOption: &LevelDBOption{File: t.TempDir()},
+	}
+	assert.NoError(t, SetupTreeDB(ctx))
+	cache, err := NewAssetTreeCache(ctx, 0, 0)
+	assert.NoError(t, err)
+	for i := int64(0); i < 2; i++ {
+		_, err = cache.AddAccount(i)
+		assert.NoError(t, err)
+		setAssetLeaf(t, cache, i, 1)
+	}
+	assert.NoError(t, cache.Commit(0, 1))
+	root1 := assetRoot(t, cache, 1)
+	for version := bsmt.Version(2); version <= 3; version++ {
+		setAssetLeaf(t, cache, 0, int64(version))
+		assert.NoError(t, cache.Commit(0, version))
+	}
+
+	// the tree read is not moved forward, the one updated is.
+	cache, err = NewAssetTreeCache(ctx, 2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, root1, assetRoot(t, cache, 1))
+	db := SetNamespace(ctx, accountAssetNamespace(1))
+	latestVersion, _, err := getVersion(db, latestVersionKey)
+	assert.NoError(t, err)
+	assert.Equal(t, bsmt.Version(1), latestVersion)
+	setAssetLeaf(t, cache, 1, 4)
+	latestVersion, _, err = getVersion(db, latestVersionKey)
+	assert.NoError(t, err)
+	assert.Equal(t, bsmt.Version(3), latestVersion)
+	assert.NoError(t, cache.Commit(0, 4))
+
+	// the tree moved forward is rolled back to a version it was not updated in.
+	assert.NoError(t, cache.Rollback(3))
+	assert.Equal(t, root1, assetRoot(t, cache, 1))
+	root4 := setAssetLeaf(t, cache, 1, 5)
+	assert.NoError(t, cache.Commit(0, 4))
+	setAssetLeaf(t, cache, 1, 6)
+	assert.NoError(t, cache.Commit(0, 5))
+
+	// the tree moved forward is pruned and can still be rolled back to the prune version.
+	assert.NoError(t, PruneTrees(ctx, 4))
+	assert.Equal(t, 2, leafVersions(t, ctx, accountAssetNamespace(1), AssetTreeHeight, 1))
+	assert.NoError(t, cache.Rollback(4))
+	assert.Equal(t, root4, assetRoot(t, cache, 1))
+}
@@ -42,6 +42,14 @@ func InitLiquidityTree(
 	}
 
 	if ctx.IsLoad() {
+		progress, err := ctx.reloadProgress(blockHeight)
+		if err != nil {
+			return nil, err
+		}
+		if progress.reloaded(LiquidityPrefix) {
+			logx.Infof("liquidity tree has been reloaded at %d", blockHeight)
+			return liquidityTree, nil
+		}
 		nums, err := liquidityHistoryModel.GetLatestLiquidityCountByBlockHeight(blockHeight)
 		if err != nil {
 			logx.Errorf("unable to get latest liquidity assets: %s", err.Error())
@@ -60,7 +68,11 @@ func InitLiquidityTree(
 				return nil, err
 			}
 		}
-
+		progress.Trees = append(progress.Trees, LiquidityPrefix)
+		err = ctx.setReloadProgress(progress)
+		if err != nil {
+			return nil, err
+		}
 		return liquidityTree, nil
 	}
 
@@ -137,3 +149,14 @@ func LiquidityAssetToNode(
 	}
 	return hashVal, nil
 }
+
+func NewEmptyLiquidityTree(
+	ctx *Context,
+	blockHeight uint64,
+) (tree bsmt.SparseMerkleTree, err error) {
+	return bsmt.NewBASSparseMerkleTree(
+		bsmt.NewHasher(zmimc.Hmimc),
+		SetNamespace(ctx, LiquidityPrefix),
+		LiquidityTreeHeight, NilLiquidityNodeHash,
+		ctx.Options(int64(blockHeight))...)
+}
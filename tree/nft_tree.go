@@ -40,6 +40,14 @@ func InitNftTree(
 	}
 
 	if ctx.IsLoad() {
+		progress, err := ctx.reloadProgress(blockHeight)
+		if err != nil {
+			return nil, err
+		}
+		if progress.reloaded(NFTPrefix) {
+			logx.Infof("nft tree has been reloaded at %d", blockHeight)
+			return nftTree, nil
+		}
 		nums, err := nftHistoryModel.GetLatestNftAssetCountByBlockHeight(blockHeight)
 		if err != nil {
 			logx.Errorf("unable to get latest nft assets: %s", err.Error())
@@ -53,11 +61,16 @@ func InitNftTree(
 				return nil, err
 			}
 		}
-		_, err = nftTree.Commit(nil)
+		err = commitReloadedTree(ctx, nftTree, blockHeight)
 		if err != nil {
 			logx.Errorf("unable to commit nft tree: %s", err.Error())
 			return nil, err
 		}
+		progress.Trees = append(progress.Trees, NFTPrefix)
+		err = ctx.setReloadProgress(progress)
+		if err != nil {
+			return nil, err
+		}
 		return nftTree, nil
 	}
 
@@ -118,3 +131,14 @@ func NftAssetToNode(nftAsset *AccountL2NftHistory) (hashVal []byte, err error) {
 	}
 	return hashVal, nil
 }
+
+func NewEmptyNftTree(
+	ctx *Context,
+	blockHeight uint64,
+) (tree bsmt.SparseMerkleTree, err error) {
+	return bsmt.NewBASSparseMerkleTree(
+		bsmt.NewHasher(zmimc.Hmimc),
+		SetNamespace(ctx, NFTPrefix),
+		NftTreeHeight, NilNftNodeHash,
+		ctx.Options(int64(blockHeight))...)
+}
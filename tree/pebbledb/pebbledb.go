@@ -0,0 +1,201 @@
+// Package pebbledb implements the tree database of github.com/bnb-chain/zkbas-smt on Pebble. The keys are wrapped
+// with the namespace the same way as the LevelDB database of zkbas-smt, so the data of a LevelDB tree database can
+// be copied as it is.
+package pebbledb
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/cockroachdb/pebble"
+
+	"github.com/bnb-chain/zkbas-smt/database"
+)
+
+var (
+	_ database.TreeDB  = (*Database)(nil)
+	_ database.Batcher = (*batch)(nil)
+)
+
+const (
+	// minCache is the minimum amount of memory in megabytes to allocate to pebble read caching.
+	minCache = 16
+
+	// minHandles is the minimum number of files handles to allocate to the open database files.
+	minHandles = 16
+)
+
+type Database struct {
+	namespace []byte
+	db        *pebble.DB
+}
+
+// New returns a wrapped Pebble object, the cache is in megabytes.
+func New(file string, cache int, handles int, readonly bool) (*Database, error) {
+	if cache < minCache {
+		cache = minCache
+	}
+	if handles < minHandles {
+		handles = minHandles
+	}
+	pebbleCache := pebble.NewCache(int64(cache) * 1024 * 1024)
+	defer pebbleCache.Unref()
+	db, err := pebble.Open(file, &pebble.Options{
+		Cache:        pebbleCache,
+		MaxOpenFiles: handles,
+		ReadOnly:     readonly,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Database{
+		db: db,
+	}, nil
+}
+
+// NewFromExistPebbleDB returns a wrapped Pebble object.
+func NewFromExistPebbleDB(db *pebble.DB) *Database {
+	return &Database{
+		db: db,
+	}
+}
+
+// WrapWithNamespace returns a wrapped Pebble object.
+// The namespace is the prefix that the datastore.
+func WrapWithNamespace(db *Database, namespace string) *Database {
+	return &Database{
+		namespace: []byte(namespace),
+		db:        db.db,
+	}
+}
+
+// wrapKey returns a wrapper key with namespace.
+func wrapKey(namespace, key []byte) []byte {
+	if len(namespace) > 0 {
+		return bytes.Join([][]byte{namespace, key}, []byte(":"))
+	}
+	return key
+}
+
+// Close flushes any pending data to disk and closes all io accesses to the underlying key-value store.
+func (db *Database) Close() error {
+	return db.db.Close()
+}
+
+// Has retrieves if a key is present in the key-value store.
+func (db *Database) Has(key []byte) (bool, error) {
+	_, closer, err := db.db.Get(wrapKey(db.namespace, key))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return false, database.ErrDatabaseNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, closer.Close()
+}
+
+// Get retrieves the given key if it's present in the key-value store.
+func (db *Database) Get(key []byte) ([]byte, error) {
+	dat, closer, err := db.db.Get(wrapKey(db.namespace, key))
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, database.ErrDatabaseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the value is only valid until the closer is closed.
+	ret := make([]byte, len(dat))
+	copy(ret, dat)
+	return ret, closer.Close()
+}
+
+// Set inserts the given value into the key-value store.
+func (db *Database) Set(key []byte, value []byte) error {
+	return db.db.Set(wrapKey(db.namespace, key), value, pebble.NoSync)
+}
+
+// Delete removes the key from the key-value store.
+func (db *Database) Delete(key []byte) error {
+	return db.db.Delete(wrapKey(db.namespace, key), pebble.NoSync)
+}
+
+// IsEmpty reports whether there is no key in the namespace.
+func (db *Database) IsEmpty() (bool, error) {
+	iter := db.db.NewIter(db.iterOptions())
+	empty := !iter.First()
+	return empty, iter.Close()
+}
+
+// Count returns the number of the keys in the namespace.
+func (db *Database) Count() (uint64, error) {
+	iter := db.db.NewIter(db.iterOptions())
+	var count uint64
+	for valid := iter.First(); valid; valid = iter.Next() {
+		count++
+	}
+	return count, iter.Close()
+}
+
+// Flush flushes the memtable to disk, the writes without sync are persisted.
+func (db *Database) Flush() error {
+	return db.db.Flush()
+}
+
+// iterOptions returns the bounds of the keys in the namespace.
+func (db *Database) iterOptions() *pebble.IterOptions {
+	if len(db.namespace) == 0 {
+		return nil
+	}
+	return &pebble.IterOptions{
+		LowerBound: wrapKey(db.namespace, nil),
+		// the key right after all the keys wrapped with the namespace, as ';' follows ':'.
+		UpperBound: append(append([]byte{}, db.namespace...), ';'),
+	}
+}
+
+// NewBatch creates a write-only key-value store that buffers changes to its host
+// database until a final write is called.
+func (db *Database) NewBatch() database.Batcher {
+	return &batch{
+		db:        db.db,
+		namespace: db.namespace,
+		b:         db.db.NewBatch(),
+	}
+}
+
+// batch is a write-only pebble batch that commits changes to its host database
+// when Write is called. A batch cannot be used concurrently.
+type batch struct {
+	namespace []byte
+	db        *pebble.DB
+	b         *pebble.Batch
+	size      int
+}
+
+// Set inserts the given value into the batch for later committing.
+func (b *batch) Set(key, value []byte) error {
+	b.size += len(value)
+	return b.b.Set(wrapKey(b.namespace, key), value, nil)
+}
+
+// Delete inserts the a key removal into the batch for later committing.
+func (b *batch) Delete(key []byte) error {
+	b.size += len(key)
+	return b.b.Delete(wrapKey(b.namespace, key), nil)
+}
+
+// Write flushes any accumulated data to disk.
+func (b *batch) Write() error {
+	return b.b.Commit(pebble.NoSync)
+}
+
+// ValueSize retrieves the amount of data queued up for writing.
+func (b *batch) ValueSize() int {
+	return b.size
+}
+
+// Reset resets the batch for reuse.
+func (b *batch) Reset() {
+	b.b.Reset()
+	b.size = 0
+}
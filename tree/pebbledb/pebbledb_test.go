@@ -0,0 +1,57 @@
+package pebbledb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bnb-chain/zkbas-smt/database"
+)
+
+func TestDatabase(t *testing.T) {
+	db, err := New(t.TempDir(), 0, 0, false)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	committer := WrapWithNamespace(db, "committer")
+	witness := WrapWithNamespace(db, "witness")
+	assert.NoError(t, committer.Set([]byte("key"), []byte("value")))
+	value, err := committer.Get([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+	has, err := committer.Has([]byte("key"))
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	// the keys are wrapped the same way as the leveldb of zkbas-smt.
+	value, err = db.Get([]byte("committer:key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+	_, err = witness.Get([]byte("key"))
+	assert.Equal(t, database.ErrDatabaseNotFound, err)
+	empty, err := witness.IsEmpty()
+	assert.NoError(t, err)
+	assert.True(t, empty)
+
+	batch := witness.NewBatch()
+	assert.NoError(t, batch.Set([]byte("key1"), []byte("value1")))
+	assert.NoError(t, batch.Set([]byte("key2"), []byte("value2")))
+	assert.NoError(t, batch.Delete([]byte("key1")))
+	assert.Equal(t, 16, batch.ValueSize())
+	_, err = witness.Get([]byte("key2"))
+	assert.Equal(t, database.ErrDatabaseNotFound, err)
+	assert.NoError(t, batch.Write())
+	value, err = witness.Get([]byte("key2"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("value2"), value)
+	_, err = witness.Has([]byte("key1"))
+	assert.Equal(t, database.ErrDatabaseNotFound, err)
+
+	assert.NoError(t, committer.Delete([]byte("key")))
+	count, err := committer.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), count)
+	count, err = db.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), count)
+}
@@ -0,0 +1,377 @@
+/*
+ * Copyright © 2021 ZkBAS Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	bsmt "github.com/bnb-chain/zkbas-smt"
+	"github.com/bnb-chain/zkbas-smt/database"
+)
+
+// prunedVersionKey records the version the tree is swept to, it is not used by the tree itself.
+var prunedVersionKey = []byte(`prunedVersion`)
+
+const pruneBatchSizeLimit = 1024 * 1024
+
+var ErrPruneUnsupported = errors.New("pruning is unsupported by memorydb")
+
+// PruneConfig is the policy of pruning the versions of the trees. The versions newer than the last verified and
+// executed block minus the retention are kept, together with the versions the service may roll back its trees to.
+type PruneConfig struct {
+	// the number of versions kept below the last verified and executed block
+	//nolint:staticcheck
+	Retention uint64 `json:",optional"`
+	// the interval of sweeping the tree db in background, 0 disables the background pruning
+	//nolint:staticcheck
+	Interval time.Duration `json:",optional"`
+}
+
+// PruneVersion returns the version below which the versions of the trees can be pruned. The floor is the lowest
+// block height the service may roll back its trees to, it is ignored if it is negative.
+func (c PruneConfig) PruneVersion(verifiedHeight, floor int64) bsmt.Version {
+	version := verifiedHeight - int64(c.Retention)
+	if floor >= 0 && floor < version {
+		version = floor
+	}
+	if version < 0 {
+		return 0
+	}
+	return bsmt.Version(version)
+}
+
+// PruneFloor returns the lowest block height the service may roll back its trees to, e.g. the height of the last
+// confirmed proof for the witness.
+type PruneFloor func() (int64, error)
+
+// Pruner prunes the versions of the trees of a service by the policy. The trees only prune the nodes written by
+// a commit, so the nodes which have not been updated since keep their old versions, these are swept in background.
+type Pruner struct {
+	ctx        *Context
+	config     PruneConfig
+	blockModel BlockModel
+	floor      PruneFloor
+
+	// the sweeping rewrites the nodes in the tree db, it must not interleave with the commits and the rollbacks. It
+	// is held for a part of a tree at a time, so the commits wait for at most one part.
+	mu      sync.Mutex
+	version bsmt.Version
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPruner returns the pruner of the trees in the tree context, the floor is optional.
+func NewPruner(ctx *Context, config PruneConfig, blockModel BlockModel, floor PruneFloor) *Pruner {
+	return &Pruner{
+		ctx:        ctx,
+		config:     config,
+		blockModel: blockModel,
+		floor:      floor,
+	}
+}
+
+// PruneVersion returns the version below which the versions of the trees can be pruned now.
+func (p *Pruner) PruneVersion() (bsmt.Version, error) {
+	verifiedHeight, err := p.blockModel.GetLatestVerifiedHeight()
+	if err != nil {
+		return 0, err
+	}
+	floor := int64(-1)
+	if p.floor != nil {
+		floor, err = p.floor()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return p.config.PruneVersion(verifiedHeight, floor), nil
+}
+
+// CommitTrees commits the trees as CommitTrees does, the old versions are pruned by the policy.
+func (p *Pruner) CommitTrees(
+	accountTree bsmt.SparseMerkleTree,
+	assetTrees *AssetTreeCache,
+	liquidityTree bsmt.SparseMerkleTree,
+	nftTree bsmt.SparseMerkleTree,
+) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	version, err := p.PruneVersion()
+	if err != nil {
+		// the commit does not depend on the pruning, the last version is used.
+		logx.Errorf("get prune version failed, use %d: %v", p.version, err)
+	} else if version > p.version {
+		p.version = version
+	}
+	return CommitTrees(uint64(p.version), accountTree, assetTrees, liquidityTree, nftTree)
+}
+
+// RollBackTrees rolls back the trees as RollBackTrees does, it is serialized with the sweeping.
+func (p *Pruner) RollBackTrees(
+	version uint64,
+	accountTree bsmt.SparseMerkleTree,
+	assetTrees *AssetTreeCache,
+	liquidityTree bsmt.SparseMerkleTree,
+	nftTree bsmt.SparseMerkleTree,
+) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return RollBackTrees(version, accountTree, assetTrees, liquidityTree, nftTree)
+}
+
+// Start sweeps the tree db every interval in background, it does nothing if the interval is 0 or the trees are in
+// memory.
+func (p *Pruner) Start() {
+	if p.config.Interval <= 0 || p.ctx.Driver == MemoryDB || p.stop != nil {
+		return
+	}
+	p.stop, p.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(p.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				err := p.Prune()
+				if err != nil {
+					logx.Errorf("prune trees of %s failed: %v", p.ctx.Name, err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the background sweeping and waits for the running one.
+func (p *Pruner) Stop() {
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+	p.stop = nil
+}
+
+// Prune sweeps the trees in the tree db to the prune version of the policy.
+func (p *Pruner) Prune() error {
+	version, err := p.PruneVersion()
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	if version > p.version {
+		p.version = version
+	}
+	version = p.version
+	p.mu.Unlock()
+	return pruneTrees(p.ctx, version, &p.mu)
+}
+
+// PruneTrees removes the versions older than the version from all the nodes of the trees in the tree db, the latest
+// version of a node not newer than the version is kept, so the trees can still be rolled back to the version. The
+// asset trees are swept by the account index until one which has never been committed.
+func PruneTrees(ctx *Context, version bsmt.Version) error {
+	return pruneTrees(ctx, version, new(sync.Mutex))
+}
+
+// pruneTrees sweeps the trees as PruneTrees does, the lock is held while a part of a tree is swept.
+func pruneTrees(ctx *Context, version bsmt.Version, lock sync.Locker) error {
+	if ctx.Driver == MemoryDB {
+		return ErrPruneUnsupported
+	}
+	if version == 0 {
+		return nil
+	}
+	start := time.Now()
+	var nodes uint64
+	for _, t := range []struct {
+		namespace string
+		depth     uint8
+	}{
+		{AccountPrefix, AccountTreeHeight},
+		{LiquidityPrefix, LiquidityTreeHeight},
+		{NFTPrefix, NftTreeHeight},
+	} {
+		_, pruned, err := pruneTree(SetNamespace(ctx, t.namespace), t.depth, version, lock)
+		if err != nil {
+			return err
+		}
+		nodes += pruned
+	}
+	for index := int64(0); ; index++ {
+		exist, pruned, err := pruneTree(SetNamespace(ctx, accountAssetNamespace(index)), AssetTreeHeight, version,
+			lock)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			break
+		}
+		nodes += pruned
+	}
+	logx.Infof("prune trees of %s to version %d, %d nodes pruned in %v", ctx.Name, version, nodes,
+		time.Since(start))
+	return nil
+}
+
+// pruneTree sweeps the nodes of the tree, it reports whether the tree has been committed. The root is swept first,
+// then the subtrees under its children one at a time, the lock is released between them. The nodes written by the
+// commits in between are pruned by the commits themselves.
+func pruneTree(db database.TreeDB, depth uint8, version bsmt.Version, lock sync.Locker) (bool, uint64, error) {
+	var (
+		exist    bool
+		nodes    uint64
+		children []uint64
+	)
+	err := withLock(lock, func() error {
+		latestVersion, ok, err := getVersion(db, latestVersionKey)
+		if err != nil || !ok {
+			return err
+		}
+		exist = true
+		prunedVersion, _, err := getVersion(db, prunedVersionKey)
+		if err != nil {
+			return err
+		}
+		// the nodes written after the last sweeping are pruned by the commits.
+		if version <= prunedVersion || latestVersion <= prunedVersion {
+			return nil
+		}
+
+		batch := db.NewBatch()
+		root, pruned, err := pruneNode(db, batch, 0, 0, version)
+		if err != nil || root == nil {
+			return err
+		}
+		if pruned {
+			nodes++
+		}
+		for nibble, child := range root.Children {
+			if child != nil && len(child.Versions) > 0 {
+				children = append(children, uint64(nibble))
+			}
+		}
+		return batch.Write()
+	})
+	if err != nil || len(children) == 0 {
+		return exist, nodes, err
+	}
+
+	for _, nibble := range children {
+		err = withLock(lock, func() error {
+			batch := db.NewBatch()
+			pruned, err := pruneSubtree(db, batch, depth, 4, nibble, version)
+			if err != nil {
+				return err
+			}
+			nodes += pruned
+			return batch.Write()
+		})
+		if err != nil {
+			return true, nodes, err
+		}
+	}
+	return true, nodes, withLock(lock, func() error {
+		return setVersion(db, prunedVersionKey, version)
+	})
+}
+
+func withLock(lock sync.Locker, fn func() error) error {
+	lock.Lock()
+	defer lock.Unlock()
+	return fn()
+}
+
+// pruneSubtree sweeps the node and the nodes under it, the batch is written when it grows over the limit.
+func pruneSubtree(db database.TreeDB, batch database.Batcher, maxDepth, depth uint8, path uint64,
+	version bsmt.Version) (uint64, error) {
+	node, pruned, err := pruneNode(db, batch, depth, path, version)
+	if err != nil || node == nil {
+		return 0, err
+	}
+	var nodes uint64
+	if pruned {
+		nodes++
+		if batch.ValueSize() > pruneBatchSizeLimit {
+			err = batch.Write()
+			if err != nil {
+				return 0, err
+			}
+			batch.Reset()
+		}
+	}
+
+	if depth >= maxDepth {
+		return nodes, nil
+	}
+	for nibble, child := range node.Children {
+		if child == nil || len(child.Versions) == 0 {
+			continue
+		}
+		childNodes, err := pruneSubtree(db, batch, maxDepth, depth+4, path<<4|uint64(nibble), version)
+		if err != nil {
+			return 0, err
+		}
+		nodes += childNodes
+	}
+	return nodes, nil
+}
+
+// pruneNode sweeps the versions of the node, the node is rewritten to the batch if any version is pruned.
+func pruneNode(db database.TreeDB, batch database.Batcher, depth uint8, path uint64,
+	version bsmt.Version) (*bsmt.StorageTreeNode, bool, error) {
+	node, err := getStorageNode(db, depth, path)
+	if err != nil || node == nil {
+		return nil, false, err
+	}
+	// the versions of the children are the copies of their roots, they are pruned the same way.
+	changed := pruneVersions(&node.Versions, version)
+	for _, child := range node.Children {
+		if child != nil && pruneVersions(&child.Versions, version) {
+			changed = true
+		}
+	}
+	if !changed {
+		return node, false, nil
+	}
+	return node, true, setStorageNode(batch, depth, path, node)
+}
+
+// pruneVersions keeps the versions newer than the version and the latest one not newer than it, as the tree does.
+func pruneVersions(versions *[]*bsmt.VersionInfo, version bsmt.Version) bool {
+	i := 0
+	for ; i < len(*versions)-1; i++ {
+		if (*versions)[i].Ver >= version {
+			break
+		}
+	}
+	if i > 0 && (*versions)[i].Ver > version {
+		i--
+	}
+	if i == 0 {
+		return false
+	}
+	*versions = (*versions)[i:]
+	return true
+}
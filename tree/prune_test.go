@@ -0,0 +1,114 @@
+package tree
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	bsmt "github.com/bnb-chain/zkbas-smt"
+)
+
+func TestPruneVersion(t *testing.T) {
+	config := PruneConfig{Retention: 10}
+	assert.Equal(t, bsmt.Version(90), config.PruneVersion(100, -1))
+	assert.Equal(t, bsmt.Version(80), config.PruneVersion(100, 80))
+	assert.Equal(t, bsmt.Version(90), config.PruneVersion(100, 95))
+	assert.Equal(t, bsmt.Version(0), config.PruneVersion(5, -1))
+}
+
+func leafVersions(t *testing.T, ctx *Context, namespace string, depth uint8, key uint64) int {
+	node, err := getStorageNode(SetNamespace(ctx, namespace), depth, key)
+	assert.NoError(t, err)
+	return len(node.Versions)
+}
+
+// countLocker counts the times the lock is taken.
+type countLocker struct {
+	sync.Mutex
+	locks int
+}
+
+func (l *countLocker) Lock() {
+	l.Mutex.Lock()
+	l.locks++
+}
+
+func TestSmtStorageLayout(t *testing.T) {
+	ctx := &Context{
+		Name:          "test",
+		Driver:        LevelDB,
+		LevelDBOption: &LevelDBOption{File: t.TempDir()},
+	}
+	assert.NoError(t, SetupTreeDB(ctx))
+	smt, err := NewEmptyAccountTree(ctx, 0)
+	assert.NoError(t, err)
+	leaf := common.LeftPadBytes([]byte{1}, 32)
+	assert.NoError(t, smt.Set(1, leaf))
+	_, err = smt.Commit(nil)
+	assert.NoError(t, err)
+
+	// the storage read by the sweeping is the one written by zkbas-smt.
+	db := SetNamespace(ctx, AccountPrefix)
+	version, exist, err := getVersion(db, latestVersionKey)
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, smt.LatestVersion(), version)
+	root, err := getStorageNode(db, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, smt.Root(), root.Versions[len(root.Versions)-1].Hash)
+	node, err := getStorageNode(db, AccountTreeHeight, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, leaf, node.Versions[len(node.Versions)-1].Hash)
+}
+
+func TestPruneTrees(t *testing.T) {
+	ctx := &Context{
+		Name:          "test",
+		Driver:        LevelDB,
+		LevelDBOption: &LevelDBOption{File: t.TempDir()},
+	}
+	assert.NoError(t, SetupTreeDB(ctx))
+
+	// the key 1 of the account tree and the asset tree 0 is updated in every version, the key 2 only in the first.
+	accountTree, err := NewEmptyAccountTree(ctx, 0)
+	assert.NoError(t, err)
+	assetTree, err := NewEmptyAccountAssetTree(ctx, 0, 0)
+	assert.NoError(t, err)
+	roots := make(map[bsmt.Version][]byte)
+	for i := int64(1); i <= 5; i++ {
+		for _, smt := range []bsmt.SparseMerkleTree{accountTree, assetTree} {
+			assert.NoError(t, smt.Set(1, common.LeftPadBytes(big.NewInt(1<<i).Bytes(), 32)))
+			if i == 1 {
+				assert.NoError(t, smt.Set(2, common.LeftPadBytes([]byte{1}, 32)))
+			}
+			_, err = smt.Commit(nil)
+			assert.NoError(t, err)
+		}
+		roots[bsmt.Version(i)] = accountTree.Root()
+	}
+	assert.Equal(t, 5, leafVersions(t, ctx, AccountPrefix, AccountTreeHeight, 1))
+	assert.Equal(t, 5, leafVersions(t, ctx, accountAssetNamespace(0), AssetTreeHeight, 1))
+
+	assert.Equal(t, ErrPruneUnsupported, PruneTrees(&Context{Driver: MemoryDB}, 3))
+	// the lock is released between the root and the subtrees of a tree, it is taken more than once per tree.
+	lock := &countLocker{}
+	assert.NoError(t, pruneTrees(ctx, 3, lock))
+	assert.Less(t, 5, lock.locks)
+	assert.Equal(t, 3, leafVersions(t, ctx, AccountPrefix, AccountTreeHeight, 1))
+	assert.Equal(t, 3, leafVersions(t, ctx, accountAssetNamespace(0), AssetTreeHeight, 1))
+	assert.Equal(t, 1, leafVersions(t, ctx, AccountPrefix, AccountTreeHeight, 2))
+	// the sweeping is skipped until the prune version moves.
+	assert.NoError(t, PruneTrees(ctx, 3))
+
+	// the trees reloaded can still be rolled back to the prune version.
+	accountTree, err = NewEmptyAccountTree(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, roots[5], accountTree.Root())
+	assert.NoError(t, accountTree.Rollback(3))
+	accountTree, err = NewEmptyAccountTree(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, roots[3], accountTree.Root())
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright © 2021 ZkBAS Protocol
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/rlp"
+
+	bsmt "github.com/bnb-chain/zkbas-smt"
+	"github.com/bnb-chain/zkbas-smt/database"
+)
+
+// The storage layout of the trees of github.com/bnb-chain/zkbas-smt v0.0.1, which does not expose it nor an api to
+// prune the nodes not updated by a commit. This file is the only place depending on the layout, it is to be
+// replaced by the pruning api of zkbas-smt once it is released, TestSmtStorageLayout fails if the layout changes.
+var (
+	latestVersionKey          = []byte(`latestVersion`)
+	storageFullTreeNodePrefix = []byte(`t`)
+	storageSep                = []byte(`:`)
+)
+
+func storageFullTreeNodeKey(depth uint8, path uint64) []byte {
+	pathBuf := make([]byte, 8)
+	binary.BigEndian.PutUint64(pathBuf, path)
+	return bytes.Join([][]byte{storageFullTreeNodePrefix, {depth}, pathBuf}, storageSep)
+}
+
+// getStorageNode returns the node at the depth and the path, or nil if the node has never been written.
+func getStorageNode(db database.TreeDB, depth uint8, path uint64) (*bsmt.StorageTreeNode, error) {
+	buf, err := db.Get(storageFullTreeNodeKey(depth, path))
+	if errors.Is(err, database.ErrDatabaseNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	node := &bsmt.StorageTreeNode{}
+	err = rlp.DecodeBytes(buf, node)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func setStorageNode(batch database.Batcher, depth uint8, path uint64, node *bsmt.StorageTreeNode) error {
+	buf, err := rlp.EncodeToBytes(node)
+	if err != nil {
+		return err
+	}
+	return batch.Set(storageFullTreeNodeKey(depth, path), buf)
+}
+
+func getVersion(db database.TreeDB, key []byte) (bsmt.Version, bool, error) {
+	buf, err := db.Get(key)
+	if errors.Is(err, database.ErrDatabaseNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if len(buf) != 8 {
+		return 0, false, errors.New("invalid version in tree db")
+	}
+	return bsmt.Version(binary.BigEndian.Uint64(buf)), true, nil
+}
+
+func setVersion(db database.TreeDB, key []byte, version bsmt.Version) error {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(version))
+	return db.Set(key, buf)
+}
+
+// forwardVersion moves the latest version of the tree forward to the version if it is older, as if the tree is
+// committed without any update in the versions between. The versions of the root node are kept, the root of a version
+// is the one of the latest version not newer than it, so the tree is still rolled back and pruned the same way.
+func forwardVersion(db database.TreeDB, version bsmt.Version) error {
+	latestVersion, exist, err := getVersion(db, latestVersionKey)
+	if err != nil || !exist || latestVersion >= version {
+		return err
+	}
+	return setVersion(db, latestVersionKey, version)
+}
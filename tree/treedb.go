@@ -3,6 +3,8 @@ package tree
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"runtime"
 	"strings"
 	"time"
 
@@ -11,12 +13,15 @@ import (
 	"github.com/bnb-chain/zkbas-smt/database/leveldb"
 	"github.com/bnb-chain/zkbas-smt/database/memory"
 	"github.com/bnb-chain/zkbas-smt/database/redis"
+	"github.com/bnb-chain/zkbas/tree/pebbledb"
 )
 
 const defaultBatchReloadSize = 1000
 
 var (
 	ErrUnsupportedDriver = errors.New("unsupported db driver")
+
+	reloadProgressKey = []byte(`reloadProgress`)
 )
 
 type Driver string
@@ -29,6 +34,14 @@ type LevelDBOption struct {
 	Handles int `json:",optional"`
 }
 
+type PebbleDBOption struct {
+	File string
+	//nolint:staticcheck
+	Cache int `json:",optional"`
+	//nolint:staticcheck
+	Handles int `json:",optional"`
+}
+
 type RedisDBOption struct {
 	//nolint:staticcheck
 	ClusterAddr []string `json:",optional"`
@@ -131,6 +144,7 @@ const (
 	MemoryDB Driver = "memorydb"
 	LevelDB  Driver = "leveldb"
 	RedisDB  Driver = "redis"
+	PebbleDB Driver = "pebble"
 )
 
 func SetupTreeDB(
@@ -147,6 +161,13 @@ func SetupTreeDB(
 		}
 		context.TreeDB = db
 		return nil
+	case PebbleDB:
+		db, err := pebbledb.New(context.PebbleDBOption.File, context.PebbleDBOption.Cache, context.PebbleDBOption.Handles, false)
+		if err != nil {
+			return err
+		}
+		context.TreeDB = db
+		return nil
 	case RedisDB:
 		bytes, err := json.Marshal(context.RedisDBOption)
 		if err != nil {
@@ -176,6 +197,8 @@ func SetNamespace(
 		return memory.NewMemoryDB()
 	case LevelDB:
 		return leveldb.WrapWithNamespace(context.TreeDB.(*leveldb.Database), strings.Join([]string{context.Name, namespace}, ":"))
+	case PebbleDB:
+		return pebbledb.WrapWithNamespace(context.TreeDB.(*pebbledb.Database), strings.Join([]string{context.Name, namespace}, ":"))
 	case RedisDB:
 		return redis.WrapWithNamespace(context.TreeDB.(*redis.Database), strings.Join([]string{context.Name, namespace}, ":"))
 	}
@@ -183,15 +206,18 @@ func SetNamespace(
 }
 
 type Context struct {
-	Name          string
-	Driver        Driver
-	LevelDBOption *LevelDBOption
-	RedisDBOption *RedisDBOption
+	Name           string
+	Driver         Driver
+	LevelDBOption  *LevelDBOption
+	PebbleDBOption *PebbleDBOption
+	RedisDBOption  *RedisDBOption
 
 	TreeDB          database.TreeDB
 	defaultOptions  []bsmt.Option
 	Reload          bool
 	batchReloadSize int
+	reloadWorkers   int
+	assetTreeCache  int
 }
 
 func (ctx *Context) IsLoad() bool {
@@ -201,6 +227,69 @@ func (ctx *Context) IsLoad() bool {
 	return ctx.Driver == MemoryDB
 }
 
+// reloadProgress is the progress of the reload of the trees into a persistent tree db at a block height. It is
+// recorded as the trees are committed, so an interrupted reload is resumed from it, and cleared by FinishReload.
+type reloadProgress struct {
+	Height int64 `json:"height"`
+	// the asset trees of the accounts up to the index have been committed, -1 if none.
+	AccountIndex int64 `json:"account_index"`
+	// the prefixes of the trees which have been committed.
+	Trees []string `json:"trees"`
+}
+
+func (p *reloadProgress) reloaded(prefix string) bool {
+	for _, tree := range p.Trees {
+		if tree == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadProgress returns the progress of the reload at the block height, the trees in memory are always reloaded
+// from scratch. The trees of a reload interrupted at another height are in an unknown state, they are not reused.
+func (ctx *Context) reloadProgress(blockHeight int64) (*reloadProgress, error) {
+	progress := &reloadProgress{Height: blockHeight, AccountIndex: -1}
+	if ctx.Driver == MemoryDB {
+		return progress, nil
+	}
+	buf, err := SetNamespace(ctx, ReloadPrefix).Get(reloadProgressKey)
+	if errors.Is(err, database.ErrDatabaseNotFound) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(buf, progress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reload progress: %v", err)
+	}
+	if progress.Height != blockHeight {
+		return nil, fmt.Errorf("the reload at block %d is interrupted, reload at the same height or clear the tree db",
+			progress.Height)
+	}
+	return progress, nil
+}
+
+func (ctx *Context) setReloadProgress(progress *reloadProgress) error {
+	if ctx.Driver == MemoryDB {
+		return nil
+	}
+	buf, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	return SetNamespace(ctx, ReloadPrefix).Set(reloadProgressKey, buf)
+}
+
+// FinishReload clears the progress of the reload once all the trees are reloaded.
+func (ctx *Context) FinishReload() error {
+	if ctx.Driver == MemoryDB {
+		return nil
+	}
+	return SetNamespace(ctx, ReloadPrefix).Delete(reloadProgressKey)
+}
+
 func (ctx *Context) Options(blockHeight int64) []bsmt.Option {
 	var opts []bsmt.Option
 	for i := range ctx.defaultOptions {
@@ -227,3 +316,27 @@ func (ctx *Context) BatchReloadSize() int {
 func (ctx *Context) SetBatchReloadSize(size int) {
 	ctx.batchReloadSize = size
 }
+
+func (ctx *Context) ReloadWorkers() int {
+	if ctx.reloadWorkers <= 0 {
+		return runtime.NumCPU() // default
+	}
+
+	return ctx.reloadWorkers
+}
+
+func (ctx *Context) SetReloadWorkers(workers int) {
+	ctx.reloadWorkers = workers
+}
+
+func (ctx *Context) AssetTreeCacheSize() int {
+	if ctx.assetTreeCache <= 0 {
+		return defaultAssetTreeCacheSize // default
+	}
+
+	return ctx.assetTreeCache
+}
+
+func (ctx *Context) SetAssetTreeCacheSize(size int) {
+	ctx.assetTreeCache = size
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/bnb-chain/zkbas-crypto/accumulators/merkleTree"
 	"github.com/bnb-chain/zkbas/dao/account"
+	"github.com/bnb-chain/zkbas/dao/block"
 	"github.com/bnb-chain/zkbas/dao/liquidity"
 	"github.com/bnb-chain/zkbas/dao/nft"
 	"github.com/bnb-chain/zkbas/dao/sysconfig"
@@ -29,6 +30,7 @@ import (
 
 type (
 	SysconfigModel        = sysconfig.SysConfigModel
+	BlockModel            = block.BlockModel
 	AccountModel          = account.AccountModel
 	AccountHistoryModel   = account.AccountHistoryModel
 	L2NftHistoryModel     = nft.L2NftHistoryModel
@@ -50,6 +52,7 @@ const (
 	LiquidityPrefix    = "liquidity:"
 	AccountPrefix      = "account:"
 	AccountAssetPrefix = "account_asset:"
+	ReloadPrefix       = "reload:"
 )
 
 var (
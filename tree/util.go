@@ -114,7 +114,7 @@ func EmptyNftNodeHash() []byte {
 
 func CommitTrees(version uint64,
 	accountTree bsmt.SparseMerkleTree,
-	assetTrees *[]bsmt.SparseMerkleTree,
+	assetTrees *AssetTreeCache,
 	liquidityTree bsmt.SparseMerkleTree,
 	nftTree bsmt.SparseMerkleTree) error {
 
@@ -126,15 +126,9 @@ func CommitTrees(version uint64,
 	if err != nil {
 		return errors.Wrapf(err, "unable to commit account tree, tree ver: %d, prune ver: %d", ver, accPrunedVersion)
 	}
-	for idx := range *assetTrees {
-		assetPrunedVersion := bsmt.Version(version)
-		if (*assetTrees)[idx].LatestVersion() < assetPrunedVersion {
-			assetPrunedVersion = (*assetTrees)[idx].LatestVersion()
-		}
-		ver, err := (*assetTrees)[idx].Commit(&assetPrunedVersion)
-		if err != nil {
-			return errors.Wrapf(err, "unable to commit asset tree [%d], tree ver: %d, prune ver: %d", idx, ver, assetPrunedVersion)
-		}
+	err = assetTrees.Commit(version, ver)
+	if err != nil {
+		return err
 	}
 	liquidityPrunedVersion := bsmt.Version(version)
 	if liquidityTree.LatestVersion() < liquidityPrunedVersion {
@@ -157,7 +151,7 @@ func CommitTrees(version uint64,
 
 func RollBackTrees(version uint64,
 	accountTree bsmt.SparseMerkleTree,
-	assetTrees *[]bsmt.SparseMerkleTree,
+	assetTrees *AssetTreeCache,
 	liquidityTree bsmt.SparseMerkleTree,
 	nftTree bsmt.SparseMerkleTree) error {
 
@@ -169,13 +163,9 @@ func RollBackTrees(version uint64,
 		}
 	}
 
-	for idx, assetTree := range *assetTrees {
-		if assetTree.LatestVersion() > ver && !assetTree.IsEmpty() {
-			err := assetTree.Rollback(ver)
-			if err != nil {
-				return errors.Wrapf(err, "unable to rollback asset tree [%d], ver: %d", idx, ver)
-			}
-		}
+	err := assetTrees.Rollback(version)
+	if err != nil {
+		return err
 	}
 
 	if liquidityTree.LatestVersion() > ver && !liquidityTree.IsEmpty() {
@@ -319,3 +309,22 @@ func ComputeStateRootHash(
 	hFunc.Write(nftRoot)
 	return hFunc.Sum(nil)
 }
+
+// ComputeMerkleRoot computes the root of the tree from the leaf at the key and its siblings ordered from the leaf
+// to the root, as returned by GetProof.
+func ComputeMerkleRoot(key uint64, leaf []byte, siblings [][]byte) []byte {
+	hFunc := mimc.NewMiMC()
+	node := leaf
+	for i, sibling := range siblings {
+		hFunc.Reset()
+		if key>>i&1 == 0 {
+			hFunc.Write(node)
+			hFunc.Write(sibling)
+		} else {
+			hFunc.Write(sibling)
+			hFunc.Write(node)
+		}
+		node = hFunc.Sum(nil)
+	}
+	return node
+}
@@ -19,6 +19,8 @@ var (
 	DbErrFailToCreateProof         = errors.New("fail to create proof")
 	DbErrFailToCreateFailTx        = errors.New("fail to create fail tx")
 	DbErrFailToCreateSysconfig     = errors.New("fail to create system config")
+	DbErrMempoolTxNotPending       = errors.New("mempool tx is not pending")
+	DbErrBlockWitnessLeaseLost     = errors.New("block witness lease is lost")
 
 	JsonErrUnmarshal = errors.New("json.Unmarshal err")
 	JsonErrMarshal   = errors.New("json.Marshal err")
@@ -30,11 +32,20 @@ var (
 
 	CmcNotListedErr = errors.New("cmc not listed")
 
-	AppErrInvalidParam    = New(20001, "invalid param: ")
-	AppErrInvalidTx       = New(20002, "invalid tx: cannot parse tx")
-	AppErrInvalidTxType   = New(20003, "invalid tx type")
-	AppErrInvalidTxField  = New(20004, "invalid tx field: ")
-	AppErrInvalidGasAsset = New(25005, "invalid gas asset")
-	AppErrNotFound        = New(29404, "not found")
-	AppErrInternal        = New(29500, "internal server error")
+	AppErrInvalidParam             = New(20001, "invalid param: ")
+	AppErrInvalidTx                = New(20002, "invalid tx: cannot parse tx")
+	AppErrInvalidTxType            = New(20003, "invalid tx type")
+	AppErrInvalidTxField           = New(20004, "invalid tx field: ")
+	AppErrInvalidGasAsset          = New(25005, "invalid gas asset")
+	AppErrInvalidSignature         = New(20005, "invalid signature")
+	AppErrTxNotPending             = New(20006, "tx is not pending")
+	AppErrReplacementTxUnderpriced = New(20007, "replacement tx underpriced: ")
+	AppErrTxPoolFull               = New(20008, "tx pool is full: ")
+	AppErrNonceTooHigh             = New(20009, "nonce too high: ")
+	AppErrTxRejected               = New(20010, "tx rejected: ")
+	AppErrTxBatchRejected          = New(20011, "tx batch rejected: ")
+	AppErrTxHookUnavailable        = New(20012, "tx hook unavailable, please retry later")
+	AppErrStateNotReady            = New(20013, "state is not ready, please retry later")
+	AppErrNotFound                 = New(29404, "not found")
+	AppErrInternal                 = New(29500, "internal server error")
 )
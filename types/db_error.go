@@ -0,0 +1,25 @@
+package types
+
+// dbError is a sentinel error of the daos with the error of the database causing it.
+type dbError struct {
+	err   error
+	cause error
+}
+
+// WrapDbErr returns the sentinel error of the daos with the error of the database causing it. errors.Is matches
+// the sentinel, and the cause is kept so that a transient failure of the database can be told apart.
+func WrapDbErr(err error, cause error) error {
+	return &dbError{err: err, cause: cause}
+}
+
+func (e *dbError) Error() string {
+	return e.err.Error() + ": " + e.cause.Error()
+}
+
+func (e *dbError) Is(target error) bool {
+	return target == e.err
+}
+
+func (e *dbError) Unwrap() error {
+	return e.cause
+}